@@ -37,6 +37,32 @@ const (
 	RepoUpdateNever RepoUpdateMode = "never"
 )
 
+// ConfigFormat is the serialization format of a configuration file.
+type ConfigFormat string
+
+const (
+	// ConfigFormatYAML is the YAML config format. JSON content is parsed as YAML as well.
+	ConfigFormatYAML ConfigFormat = "yaml"
+	// ConfigFormatJSON is the JSON config format.
+	ConfigFormatJSON ConfigFormat = "json"
+	// ConfigFormatTOML is the TOML config format.
+	ConfigFormatTOML ConfigFormat = "toml"
+)
+
+// ConfigFormatFromPath returns the config format for the given file path or URL based on its
+// extension, defaulting to YAML.
+func ConfigFormatFromPath(path string) ConfigFormat {
+	path, _, _ = strings.Cut(path, "?")
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return ConfigFormatTOML
+	case ".json":
+		return ConfigFormatJSON
+	default:
+		return ConfigFormatYAML
+	}
+}
+
 // AppConfig represents the main application configuration.
 type AppConfig struct {
 	// Debug enables or disables debug mode.
@@ -45,19 +71,33 @@ type AppConfig struct {
 	CheckUpdates *bool `json:"check_updates"  yaml:"check_updates"`
 	// Summary enables or disables the installation summary at the end.
 	Summary *bool `json:"summary"        yaml:"summary"`
+	// Strict turns unknown config key warnings into errors.
+	Strict *bool `json:"strict"         yaml:"strict"`
 	// CategoryDisplay controls how category headers are rendered.
 	CategoryDisplay *CategoryDisplayMode `json:"category_display" yaml:"category_display"`
 	// RepoUpdate controls repository index update behavior per installer type.
-	// Supported types: brew, apt, apk. Values: "once" (default), "always", "never".
+	// Supported types: brew, apt, apk, dnf, yum, zypper. Values: "once" (default), "always", "never".
 	RepoUpdate *map[InstallerType]RepoUpdateMode `json:"repo_update"    yaml:"repo_update"`
 	// Install is a list of installers to run.
 	Install []InstallerData `json:"install"        yaml:"install"`
-	// Defaults provides default configurations for installer types.
+	// Defaults provides default configurations for installers, for all of them, per type or per tag.
 	Defaults *AppConfigDefaults `json:"defaults"       yaml:"defaults"`
-	// Env is a map of environment variables to set.
-	Env *map[string]string `json:"env"            yaml:"env"`
+	// Templates is a map of named partial installers that installers can build on using extends.
+	Templates *map[string]InstallerData `json:"templates"      yaml:"templates"`
+	// Env is a map of environment variables to set. Variables are set in order, and values can
+	// reference variables declared before them, e.g. ${VAR} or ${VAR:-default}.
+	Env *EnvVars `json:"env"            yaml:"env"`
+	// EnvFile is the path to a dotenv file to load variables from. Variables in env take
+	// precedence. Relative paths are resolved against the config file's directory.
+	EnvFile *string `json:"env_file"       yaml:"env_file"`
 	// PlatformEnv is a map of platform-specific environment variables to set.
 	PlatformEnv *platform.PlatformMap[map[string]string] `json:"platform_env"   yaml:"platform_env"`
+	// PathPrepend is a list of directories to add to the front of PATH, for sofmani itself and
+	// all the commands it runs.
+	PathPrepend *[]string `json:"path_prepend"   yaml:"path_prepend"`
+	// PathAppend is a list of directories to add to the end of PATH, for sofmani itself and all
+	// the commands it runs.
+	PathAppend *[]string `json:"path_append"    yaml:"path_append"`
 	// MachineAliases is a map of friendly names to machine IDs.
 	MachineAliases *map[string]string `json:"machine_aliases" yaml:"machine_aliases"`
 	// Filter is a list of installer names to filter by.
@@ -66,6 +106,15 @@ type AppConfig struct {
 	IgnoreFrequency bool
 	// StartFrom skips all installers before the one with the given name.
 	StartFrom string
+	// Fragments is the list of conf.d fragment files that were merged into this config.
+	Fragments []string
+	// Overlays is the list of machine-specific overlay files that were merged into this config.
+	Overlays []string
+	// environ caches the resolved environment, see Environ.
+	environ []string
+	// fileStarts holds the indices in Install where the installers of each merged fragment or
+	// overlay start. Categories don't extend past them, see ApplyCategories.
+	fileStarts []int
 }
 
 // GetRepoUpdateMode returns the repo update mode for the given installer type,
@@ -105,20 +154,41 @@ type AppCliConfig struct {
 	StartFrom string
 }
 
-// AppConfigDefaults provides default configurations for installer types.
-type AppConfigDefaults struct {
-	// Type is a map of installer types to their default configurations.
-	Type *map[InstallerType]InstallerData `json:"type" yaml:"type"`
-}
-
 // GetCategoryDisplay returns the effective category display mode, defaulting to "border".
 func (c *AppConfig) GetCategoryDisplay() CategoryDisplayMode {
 	return lo.FromPtrOr(c.CategoryDisplay, CategoryDisplayBorder)
 }
 
-// Environ returns the combined environment variables as a slice of strings.
+// Environ returns the combined environment variables as a slice of strings. Variables that fail to
+// resolve are left out; use ResolveEnviron to handle the error.
 func (c *AppConfig) Environ() []string {
-	return utils.EnvMapAsSlice(utils.CombineEnvMaps(c.Env, c.PlatformEnv.Resolve()))
+	env, _ := c.ResolveEnviron()
+	return env
+}
+
+// ResolveEnviron returns the combined environment variables as a slice of strings: env_file, env,
+// then platform_env, then PATH with path_prepend and path_append applied. Variables are resolved on
+// the first successful call only, so that applying the result to the process environment does not
+// expand self-references such as ${PATH} twice.
+func (c *AppConfig) ResolveEnviron() ([]string, error) {
+	if c.environ != nil {
+		return c.environ, nil
+	}
+	env, err := resolveEnv(c.EnvFile, c.Env, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	env, err = withPlatformEnv(env, c.PlatformEnv)
+	if err != nil {
+		return nil, err
+	}
+	prepend, appendDirs := lo.FromPtr(c.PathPrepend), lo.FromPtr(c.PathAppend)
+	if len(prepend) > 0 || len(appendDirs) > 0 {
+		path, _ := lookupEnviron(env)("PATH")
+		env = withEnvVar(env, "PATH", utils.AdjustPathList(path, prepend, appendDirs))
+	}
+	c.environ = env
+	return env, nil
 }
 
 // ParseConfig parses the configuration file and applies overrides.
@@ -126,11 +196,21 @@ func ParseConfig(overrides *AppCliConfig) (*AppConfig, error) {
 	file := overrides.ConfigFile
 	ext := filepath.Ext(file)
 	switch ext {
-	case ".json", ".yaml", ".yml":
+	case ".json", ".yaml", ".yml", ".toml":
 		appConfig, err := ParseConfigFrom(file)
 		if err != nil {
 			return nil, err
 		}
+		if err := appConfig.applyFragments(file); err != nil {
+			return nil, err
+		}
+		if err := appConfig.applyMachineOverlays(file); err != nil {
+			return nil, err
+		}
+		if err := appConfig.ResolveTemplates(); err != nil {
+			return nil, err
+		}
+		appConfig.ApplyCategories()
 		if overrides.Debug != nil {
 			appConfig.Debug = overrides.Debug
 		}
@@ -151,27 +231,101 @@ func ParseConfig(overrides *AppCliConfig) (*AppConfig, error) {
 // ParseConfigFrom parses the configuration from the given file.
 func ParseConfigFrom(file string) (*AppConfig, error) {
 	appConfig := NewAppConfig()
-	err := config.ParseConfigFile(&appConfig, file)
+	err := parseConfigFile(&appConfig, file)
 	if err != nil {
 		return nil, err
 	}
+	appConfig.resolveEnvFilePaths(filepath.Dir(file))
 	return &appConfig, nil
 }
 
-// ParseConfigFromContent parses the configuration from YAML content.
-func ParseConfigFromContent(content []byte) (*AppConfig, error) {
+// resolveEnvFilePaths makes relative env_file paths in the config and its installers absolute,
+// relative to dir.
+func (c *AppConfig) resolveEnvFilePaths(dir string) {
+	c.EnvFile = envFilePath(dir, c.EnvFile)
+	resolveInstallerEnvFilePaths(dir, c.Install)
+	if c.Templates != nil {
+		for name, template := range *c.Templates {
+			template.EnvFile = envFilePath(dir, template.EnvFile)
+			(*c.Templates)[name] = template
+		}
+	}
+}
+
+// resolveInstallerEnvFilePaths makes relative env_file paths of the installers, including nested
+// group steps, absolute, relative to dir.
+func resolveInstallerEnvFilePaths(dir string, installers []InstallerData) {
+	for idx := range installers {
+		inst := &installers[idx]
+		inst.EnvFile = envFilePath(dir, inst.EnvFile)
+		if inst.Steps != nil {
+			resolveInstallerEnvFilePaths(dir, *inst.Steps)
+		}
+	}
+}
+
+// envFilePath returns path joined to dir, unless it is empty, absolute, or starts with ~ or a
+// variable reference.
+func envFilePath(dir string, path *string) *string {
+	if path == nil || *path == "" || filepath.IsAbs(*path) || strings.HasPrefix(*path, "~") || strings.HasPrefix(*path, "$") {
+		return path
+	}
+	return lo.ToPtr(filepath.Join(dir, *path))
+}
+
+// ParseConfigFromContent parses the configuration from content in the given format.
+func ParseConfigFromContent(content []byte, format ConfigFormat) (*AppConfig, error) {
 	appConfig := NewAppConfig()
-	err := yaml.Unmarshal(content, &appConfig)
+	err := unmarshalConfigContent(content, format, &appConfig)
 	if err != nil {
 		return nil, err
 	}
 	return &appConfig, nil
 }
 
-// FindConfigFile searches for the configuration file in standard locations.
-// It searches in the current working directory, then in ~/.config, and finally in the home directory.
+// parseConfigFile parses the given config file into target. JSON and YAML files are parsed
+// directly, while TOML files are converted and parsed as YAML content.
+func parseConfigFile(target any, file string) error {
+	format := ConfigFormatFromPath(file)
+	if format != ConfigFormatTOML {
+		return config.ParseConfigFile(target, file)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("can't open toml config file. %w", err)
+	}
+	return unmarshalConfigContent(content, format, target)
+}
+
+// unmarshalConfigContent unmarshals config content in the given format into target.
+func unmarshalConfigContent(content []byte, format ConfigFormat, target any) error {
+	if format == ConfigFormatTOML {
+		converted, err := tomlToYAML(content)
+		if err != nil {
+			return err
+		}
+		content = converted
+	}
+	return yaml.Unmarshal(content, target)
+}
+
+// FindConfigFile searches for the configuration file in standard locations, in this order:
+//   - $SOFMANI_CONFIG, either a config file or a directory containing one
+//   - the current working directory
+//   - $XDG_CONFIG_HOME/sofmani, then $XDG_CONFIG_HOME ($XDG_CONFIG_HOME defaults to ~/.config)
+//   - ~/.config/sofmani, then ~/.config, if $XDG_CONFIG_HOME points elsewhere
+//   - the home directory
+//
 // It returns the path to the first file found, or an empty string if no file is found.
 func FindConfigFile() string {
+	if file := os.Getenv("SOFMANI_CONFIG"); file != "" {
+		file = utils.GetRealPath(nil, file)
+		if info, err := os.Stat(file); err == nil && info.IsDir() {
+			return tryConfigDir(file)
+		}
+		// Returned even if it doesn't exist, so that the error names the file that was asked for.
+		return file
+	}
 	wd, err := os.Getwd()
 	if err != nil {
 		return ""
@@ -181,23 +335,42 @@ func FindConfigFile() string {
 		fmt.Fprintf(os.Stderr, "Failed to get user home directory: %v\n", err)
 		return ""
 	}
-	file := ""
-	dirs := []string{wd, filepath.Join(home, ".config"), home}
-	for _, dir := range dirs {
-		file = tryConfigDir(dir)
-		if file != "" {
+	for _, dir := range configSearchDirs(wd, home, os.Getenv("XDG_CONFIG_HOME")) {
+		if file := tryConfigDir(dir); file != "" {
 			return file
 		}
 	}
 	return ""
 }
 
+// configSearchDirs returns the directories FindConfigFile searches, in order.
+func configSearchDirs(wd string, home string, xdgConfigHome string) []string {
+	defaultConfigHome := filepath.Join(home, ".config")
+	if xdgConfigHome == "" {
+		xdgConfigHome = defaultConfigHome
+	}
+	dirs := []string{wd, filepath.Join(xdgConfigHome, "sofmani"), xdgConfigHome}
+	if filepath.Clean(xdgConfigHome) != defaultConfigHome {
+		dirs = append(dirs, filepath.Join(defaultConfigHome, "sofmani"), defaultConfigHome)
+	}
+	return append(dirs, home)
+}
+
+// configExtensions is the list of supported config file extensions, in lookup order.
+var configExtensions = []string{"json", "yaml", "yml", "toml"}
+
 // tryConfigDir attempts to find a configuration file with a valid extension in the given directory.
-// It checks for "sofmani.json", "sofmani.yaml", and "sofmani.yml".
+// It checks for "sofmani.json", "sofmani.yaml", "sofmani.yml", and "sofmani.toml".
 // It returns the path to the first file found, or an empty string if no file is found.
 func tryConfigDir(dir string) string {
-	for _, ext := range []string{"json", "yaml", "yml"} {
-		file := filepath.Join(dir, "sofmani."+ext)
+	return tryConfigBase(filepath.Join(dir, "sofmani"))
+}
+
+// tryConfigBase attempts to find a file named base with any of the supported config extensions.
+// It returns the path to the first file found, or an empty string if no file is found.
+func tryConfigBase(base string) string {
+	for _, ext := range configExtensions {
+		file := base + "." + ext
 		if _, err := os.Stat(file); err == nil {
 			return file
 		}
@@ -211,13 +384,24 @@ func (c *AppConfig) GetConfigDesc() []string {
 	desc = append(desc, fmt.Sprintf("Debug: %t", lo.FromPtrOr(c.Debug, false)))
 	desc = append(desc, fmt.Sprintf("CheckUpdates: %t", lo.FromPtrOr(c.CheckUpdates, false)))
 	desc = append(desc, fmt.Sprintf("Summary: %t", lo.FromPtrOr(c.Summary, true)))
+	desc = append(desc, fmt.Sprintf("Strict: %t", lo.FromPtrOr(c.Strict, false)))
 
 	if c.Env != nil {
 		desc = append(desc, "Environment Variables:")
-		for k, v := range *c.Env {
-			desc = append(desc, fmt.Sprintf("  %s=%s", k, v))
+		for _, v := range *c.Env {
+			desc = append(desc, "  "+v.Describe())
 		}
 	}
+	if c.EnvFile != nil {
+		desc = append(desc, fmt.Sprintf("Env File: %s", *c.EnvFile))
+	}
+
+	if c.PathPrepend != nil {
+		desc = append(desc, fmt.Sprintf("Path Prepend: %s", strings.Join(*c.PathPrepend, ", ")))
+	}
+	if c.PathAppend != nil {
+		desc = append(desc, fmt.Sprintf("Path Append: %s", strings.Join(*c.PathAppend, ", ")))
+	}
 
 	if c.PlatformEnv != nil {
 		desc = append(desc, "Platform Environment Variables:\n")
@@ -227,6 +411,20 @@ func (c *AppConfig) GetConfigDesc() []string {
 		}
 	}
 
+	if len(c.Fragments) > 0 {
+		desc = append(desc, "Config Fragments:")
+		for _, f := range c.Fragments {
+			desc = append(desc, fmt.Sprintf("  %s", f))
+		}
+	}
+
+	if len(c.Overlays) > 0 {
+		desc = append(desc, "Machine Overlays:")
+		for _, o := range c.Overlays {
+			desc = append(desc, fmt.Sprintf("  %s", o))
+		}
+	}
+
 	var filterBuilder strings.Builder
 	filterBuilder.WriteString("Filter: ")
 	if len(c.Filter) > 0 {
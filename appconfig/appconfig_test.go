@@ -9,6 +9,7 @@ import (
 	"github.com/chenasraf/sofmani/platform"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPlatformMapResolve(t *testing.T) {
@@ -36,21 +37,21 @@ func TestPlatformMapResolve(t *testing.T) {
 }
 
 func TestAppConfigEnviron(t *testing.T) {
-	env := map[string]string{"KEY1": "value1", "KEY2": "value2"}
+	env := NewEnvVars(map[string]string{"KEY1": "value1", "KEY2": "value2"})
 	config := AppConfig{Env: &env}
 	expected := []string{"KEY1=value1", "KEY2=value2"}
 	assert.ElementsMatch(t, expected, config.Environ())
 }
 
 func TestInstallerEnviron(t *testing.T) {
-	env := map[string]string{"KEY1": "value1", "KEY2": "value2"}
+	env := NewEnvVars(map[string]string{"KEY1": "value1", "KEY2": "value2"})
 	installer := InstallerData{Env: &env}
 	expected := []string{"KEY1=value1", "KEY2=value2"}
 	assert.ElementsMatch(t, expected, installer.Environ())
 }
 
 func TestInstallerPlatformEnviron(t *testing.T) {
-	env := map[string]string{"KEY1": "value1", "KEY2": "value2"}
+	env := NewEnvVars(map[string]string{"KEY1": "value1", "KEY2": "value2"})
 	platformEnv := map[string]string{"KEY2": "value2-override", "KEY3": "value3"}
 	data := InstallerData{Env: &env, PlatformEnv: &platform.PlatformMap[map[string]string]{
 		MacOS:   &platformEnv,
@@ -183,3 +184,64 @@ func TestFindConfigFile(t *testing.T) {
 	assert.NoError(t, os.Chdir(dir))
 	assert.True(t, strings.HasSuffix(FindConfigFile(), file))
 }
+
+func TestFindConfigFileFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "custom.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("debug: true\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sofmani.toml"), []byte("debug = true\n"), 0644))
+
+	t.Run("file", func(t *testing.T) {
+		t.Setenv("SOFMANI_CONFIG", file)
+		assert.Equal(t, file, FindConfigFile())
+	})
+	t.Run("directory", func(t *testing.T) {
+		t.Setenv("SOFMANI_CONFIG", dir)
+		assert.Equal(t, filepath.Join(dir, "sofmani.toml"), FindConfigFile())
+	})
+	t.Run("missing file", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing.yaml")
+		t.Setenv("SOFMANI_CONFIG", missing)
+		assert.Equal(t, missing, FindConfigFile())
+	})
+}
+
+func TestFindConfigFileInXDGConfigHome(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("SOFMANI_CONFIG", "")
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	file := filepath.Join(xdg, "sofmani", "sofmani.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte("debug: true\n"), 0644))
+	assert.Equal(t, file, FindConfigFile())
+}
+
+func TestConfigSearchDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		xdg      string
+		expected []string
+	}{
+		{
+			"default config home",
+			"",
+			[]string{"/work", "/home/user/.config/sofmani", "/home/user/.config", "/home/user"},
+		},
+		{
+			"same as default config home",
+			"/home/user/.config/",
+			[]string{"/work", "/home/user/.config/sofmani", "/home/user/.config/", "/home/user"},
+		},
+		{
+			"custom config home",
+			"/xdg",
+			[]string{"/work", "/xdg/sofmani", "/xdg", "/home/user/.config/sofmani", "/home/user/.config", "/home/user"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, configSearchDirs("/work", "/home/user", tt.xdg))
+		})
+	}
+}
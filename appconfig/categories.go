@@ -0,0 +1,44 @@
+package appconfig
+
+import "slices"
+
+// ApplyCategories applies the settings of each category entry to the entries listed under it, up
+// to the next category. The fields set on a category (e.g. platforms, machines, tags, enabled and
+// env) and its defaults fill in what its entries don't set themselves, and win over the top-level
+// defaults. Tags are combined and env is merged by key. Each entry also records its category name
+// in Section. Categories only apply within the file that declares them: the installers of a merged
+// fragment or overlay don't belong to the last category of the file before it.
+func (c *AppConfig) ApplyCategories() {
+	var scope *AppConfigDefaults
+	var section *string
+	for idx := range c.Install {
+		data := &c.Install[idx]
+		if slices.Contains(c.fileStarts, idx) {
+			scope, section = nil, nil
+		}
+		if data.IsCategory() {
+			scope = data.categoryDefaults()
+			section = data.Category
+			continue
+		}
+		if scope != nil {
+			*data = scope.Resolve(*data, data.Type)
+		}
+		data.Section = section
+	}
+}
+
+// categoryDefaults returns the defaults a category applies to its entries: its own defaults, with
+// the fields set on the category itself on top of defaults.all.
+func (i *InstallerData) categoryDefaults() *AppConfigDefaults {
+	scope := AppConfigDefaults{}
+	if i.Defaults != nil {
+		scope = *i.Defaults
+	}
+	all := i.inheritable()
+	if scope.All != nil {
+		all = MergeInstallerData(*scope.All, all)
+	}
+	scope.All = &all
+	return &scope
+}
@@ -0,0 +1,158 @@
+package appconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCategories(t *testing.T) {
+	t.Run("applies category settings up to the next category", func(t *testing.T) {
+		c := &AppConfig{
+			Install: []InstallerData{
+				{Name: lo.ToPtr("before"), Type: InstallerTypeBrew},
+				{
+					Category:  lo.ToPtr("Work"),
+					Desc:      lo.ToPtr("Work tools"),
+					Platforms: &platform.Platforms{Only: &[]platform.Platform{platform.PlatformMacos}},
+					Tags:      lo.ToPtr("work"),
+					Enabled:   lo.ToPtr("test -d ~/work"),
+					Env:       &EnvVars{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}},
+				},
+				{Name: lo.ToPtr("app"), Type: InstallerTypeBrew, Tags: lo.ToPtr("gui"), Env: &EnvVars{{Name: "B", Value: "2"}}},
+				{Name: lo.ToPtr("own"), Type: InstallerTypeBrew, Enabled: lo.ToPtr("true")},
+				{Category: lo.ToPtr("Other")},
+				{Name: lo.ToPtr("after"), Type: InstallerTypeBrew},
+			},
+		}
+		c.ApplyCategories()
+
+		before := c.Install[0]
+		assert.Nil(t, before.Section)
+		assert.Nil(t, before.Platforms)
+
+		app := c.Install[2]
+		assert.Equal(t, "Work", *app.Section)
+		assert.Equal(t, []platform.Platform{platform.PlatformMacos}, *app.Platforms.Only)
+		assert.Equal(t, "work gui", *app.Tags)
+		assert.Equal(t, "test -d ~/work", *app.Enabled)
+		assert.Equal(t, map[string]string{"A": "1", "B": "2"}, app.Env.Map())
+		assert.Nil(t, app.Category)
+		assert.Nil(t, app.Desc)
+
+		own := c.Install[3]
+		assert.Equal(t, "true", *own.Enabled)
+
+		after := c.Install[5]
+		assert.Equal(t, "Other", *after.Section)
+		assert.Nil(t, after.Platforms)
+		assert.Nil(t, after.Tags)
+	})
+
+	t.Run("applies category defaults", func(t *testing.T) {
+		c := &AppConfig{
+			Install: []InstallerData{
+				{
+					Category:   lo.ToPtr("Tools"),
+					PostUpdate: lo.ToPtr("category"),
+					Defaults: &AppConfigDefaults{
+						All: &InstallerData{PostInstall: lo.ToPtr("all"), PostUpdate: lo.ToPtr("all")},
+						Type: &map[InstallerType]InstallerData{
+							InstallerTypeNpm: {Opts: &map[string]any{"global": true}},
+						},
+					},
+				},
+				{Name: lo.ToPtr("brew"), Type: InstallerTypeBrew},
+				{Name: lo.ToPtr("npm"), Type: InstallerTypeNpm},
+			},
+		}
+		c.ApplyCategories()
+		assert.Equal(t, "all", *c.Install[1].PostInstall)
+		assert.Equal(t, "category", *c.Install[1].PostUpdate)
+		assert.Nil(t, c.Install[1].Opts)
+		assert.Nil(t, c.Install[1].Defaults)
+		assert.Equal(t, map[string]any{"global": true}, *c.Install[2].Opts)
+	})
+}
+
+func TestParseConfigWithCategories(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	writeOverlayTestFile(t, configFile, `
+defaults:
+  all:
+    pre_install: echo top
+    post_install: echo top
+install:
+  - category: Work
+    post_install: echo work
+    machines:
+      only: [work-laptop]
+  - name: slack
+    type: brew
+`)
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: configFile})
+	require.NoError(t, err)
+	slack := config.Defaults.Resolve(config.Install[1], InstallerTypeBrew)
+	// Category settings win over the top-level defaults, which still fill in the rest.
+	assert.Equal(t, "echo work", *slack.PostInstall)
+	assert.Equal(t, "echo top", *slack.PreInstall)
+	assert.Equal(t, []string{"work-laptop"}, *slack.Machines.Only)
+	assert.Equal(t, "Work", *slack.Section)
+}
+
+func TestParseConfigCategoriesAreScopedToTheirFile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	writeOverlayTestFile(t, configFile, `
+machine_aliases:
+  home: `+machine.GetMachineID()+`
+install:
+  - category: Work
+    tags: work
+    enabled: test -d ~/work
+    platforms:
+      only: [macos]
+  - name: slack
+    type: brew
+`)
+	writeOverlayTestFile(t, filepath.Join(dir, "conf.d", "10-tools.yaml"), `
+install:
+  - name: jq
+    type: brew
+  - category: Tools
+    tags: tools
+  - name: ripgrep
+    type: brew
+`)
+	writeOverlayTestFile(t, filepath.Join(dir, "sofmani.home.yaml"), `
+install:
+  - name: htop
+    type: brew
+`)
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: configFile})
+	require.NoError(t, err)
+	require.Len(t, config.Install, 6)
+	slack := config.Install[1]
+	assert.Equal(t, "Work", *slack.Section)
+	assert.Equal(t, "work", *slack.Tags)
+
+	// The fragment's and the overlay's installers don't inherit the last category of the file
+	// before them.
+	for _, data := range []InstallerData{config.Install[2], config.Install[5]} {
+		assert.Nil(t, data.Section, *data.Name)
+		assert.Nil(t, data.Tags, *data.Name)
+		assert.Nil(t, data.Enabled, *data.Name)
+		assert.Nil(t, data.Platforms, *data.Name)
+	}
+	ripgrep := config.Install[4]
+	assert.Equal(t, "Tools", *ripgrep.Section)
+	assert.Equal(t, "tools", *ripgrep.Tags)
+}
@@ -0,0 +1,208 @@
+package appconfig
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
+)
+
+// AppConfigDefaults provides default configurations for installers. Defaults only fill in what an
+// installer does not set itself, with the precedence all < type < tag < installer.
+type AppConfigDefaults struct {
+	// All is the default configuration for every installer.
+	All *InstallerData `json:"all"  yaml:"all"`
+	// Type is a map of installer types to their default configurations.
+	Type *map[InstallerType]InstallerData `json:"type" yaml:"type"`
+	// Tag is a map of tags to their default configurations, applied to installers with the tag.
+	Tag *map[string]InstallerData `json:"tag"  yaml:"tag"`
+}
+
+// Resolve returns data with the defaults that apply to it filled in. The tag defaults that apply
+// are chosen by the installer's tags, including the ones inherited from the all and type defaults,
+// and are applied in the order of those tags. Identity fields (name, names, type, category, desc,
+// steps and defaults) are never inherited.
+func (d *AppConfigDefaults) Resolve(data InstallerData, installerType InstallerType) InstallerData {
+	if d == nil {
+		return data
+	}
+	merged := InstallerData{}
+	if d.All != nil {
+		merged = MergeInstallerData(merged, d.All.inheritable())
+	}
+	if d.Type != nil {
+		if typeDefaults, ok := (*d.Type)[installerType]; ok {
+			merged = MergeInstallerData(merged, typeDefaults.inheritable())
+		}
+	}
+	if d.Tag != nil {
+		tags := MergeInstallerData(merged, data).Tags
+		for _, tag := range strings.Fields(lo.FromPtr(tags)) {
+			if tagDefaults, ok := (*d.Tag)[tag]; ok {
+				merged = MergeInstallerData(merged, tagDefaults.inheritable())
+			}
+		}
+	}
+	return MergeInstallerData(merged, data)
+}
+
+// inheritable returns a copy of the installer without the fields that identify it, which can't be
+// inherited from defaults.
+func (i InstallerData) inheritable() InstallerData {
+	i.Category = nil
+	i.Desc = nil
+	i.Name = nil
+	i.Names = nil
+	i.Type = ""
+	i.Steps = nil
+	i.Defaults = nil
+	i.Section = nil
+	return i
+}
+
+// MergeInstallerData returns base with the fields set in override on top. Fields set in override
+// win, tags are combined, and opts, env, platform_env and env_shell are merged key by key, nested
+// opts included. The result does not share maps with either input.
+func MergeInstallerData(base InstallerData, override InstallerData) InstallerData {
+	return InstallerData{
+		Category:       cmp.Or(override.Category, base.Category),
+		Desc:           cmp.Or(override.Desc, base.Desc),
+		Enabled:        cmp.Or(override.Enabled, base.Enabled),
+		When:           cmp.Or(override.When, base.When),
+		Name:           cmp.Or(override.Name, base.Name),
+		Names:          cmp.Or(override.Names, base.Names),
+		Type:           cmp.Or(override.Type, base.Type),
+		Extends:        cmp.Or(override.Extends, base.Extends),
+		Tags:           mergeTags(base.Tags, override.Tags),
+		Env:            copyEnvVars(base.Env, override.Env),
+		EnvFile:        cmp.Or(override.EnvFile, base.EnvFile),
+		PlatformEnv:    copyPlatformEnv(base.PlatformEnv, override.PlatformEnv),
+		Platforms:      cmp.Or(override.Platforms, base.Platforms),
+		Archs:          cmp.Or(override.Archs, base.Archs),
+		Traits:         cmp.Or(override.Traits, base.Traits),
+		Machines:       cmp.Or(override.Machines, base.Machines),
+		Steps:          cmp.Or(override.Steps, base.Steps),
+		Opts:           mergeOpts(base.Opts, override.Opts),
+		BinName:        cmp.Or(override.BinName, base.BinName),
+		CheckHasUpdate: cmp.Or(override.CheckHasUpdate, base.CheckHasUpdate),
+		CheckInstalled: cmp.Or(override.CheckInstalled, base.CheckInstalled),
+		PostInstall:    cmp.Or(override.PostInstall, base.PostInstall),
+		PreInstall:     cmp.Or(override.PreInstall, base.PreInstall),
+		PostUpdate:     cmp.Or(override.PostUpdate, base.PostUpdate),
+		PreUpdate:      cmp.Or(override.PreUpdate, base.PreUpdate),
+		EnvShell:       mergePlatformMap(base.EnvShell, override.EnvShell),
+		SkipSummary:    cmp.Or(override.SkipSummary, base.SkipSummary),
+		Verbose:        cmp.Or(override.Verbose, base.Verbose),
+		Frequency:      cmp.Or(override.Frequency, base.Frequency),
+		Defaults:       cmp.Or(override.Defaults, base.Defaults),
+		Section:        cmp.Or(override.Section, base.Section),
+	}
+}
+
+// mergeTags combines two space-separated tag lists, without duplicates.
+func mergeTags(base *string, override *string) *string {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	tags := lo.Uniq(slices.Concat(strings.Fields(*base), strings.Fields(*override)))
+	return lo.ToPtr(strings.Join(tags, " "))
+}
+
+// copyEnvVars merges two env lists into a new one, with override winning.
+func copyEnvVars(base *EnvVars, override *EnvVars) *EnvVars {
+	if base == nil && override == nil {
+		return nil
+	}
+	out := EnvVars{}
+	out.Merge(base)
+	out.Merge(override)
+	return &out
+}
+
+// copyPlatformEnv merges two platform env maps per platform into a new one, with override winning.
+func copyPlatformEnv(
+	base *platform.PlatformMap[map[string]string],
+	override *platform.PlatformMap[map[string]string],
+) *platform.PlatformMap[map[string]string] {
+	if base == nil && override == nil {
+		return nil
+	}
+	copied := mergePlatformEnv(&platform.PlatformMap[map[string]string]{}, base)
+	return mergePlatformEnv(copied, override)
+}
+
+// mergePlatformMap merges two platform maps per key, with override winning.
+func mergePlatformMap[T any](base *platform.PlatformMap[T], override *platform.PlatformMap[T]) *platform.PlatformMap[T] {
+	if base == nil && override == nil {
+		return nil
+	}
+	if base == nil {
+		base = &platform.PlatformMap[T]{}
+	}
+	if override == nil {
+		override = &platform.PlatformMap[T]{}
+	}
+	out := &platform.PlatformMap[T]{
+		MacOS:   cmp.Or(override.MacOS, base.MacOS),
+		Linux:   cmp.Or(override.Linux, base.Linux),
+		Windows: cmp.Or(override.Windows, base.Windows),
+	}
+	if len(base.Distros)+len(override.Distros) > 0 {
+		out.Distros = lo.Assign(base.Distros, override.Distros)
+	}
+	if len(base.OSArch)+len(override.OSArch) > 0 {
+		out.OSArch = lo.Assign(base.OSArch, override.OSArch)
+	}
+	return out
+}
+
+// mergeOpts merges two opts maps into a new one, with override winning. Nested maps are merged
+// recursively.
+func mergeOpts(base *map[string]any, override *map[string]any) *map[string]any {
+	if base == nil && override == nil {
+		return nil
+	}
+	out := deepMergeMaps(lo.FromPtr(base), lo.FromPtr(override))
+	return &out
+}
+
+// deepMergeMaps merges two maps into a new one, with override winning. Values that are maps on both
+// sides are merged recursively.
+func deepMergeMaps(base map[string]any, override map[string]any) map[string]any {
+	out := maps.Clone(base)
+	if out == nil {
+		out = map[string]any{}
+	}
+	for key, value := range override {
+		baseMap, baseOk := asStringMap(out[key])
+		overrideMap, overrideOk := asStringMap(value)
+		if baseOk && overrideOk {
+			out[key] = deepMergeMaps(baseMap, overrideMap)
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// asStringMap returns a nested opts map with string keys. YAML decodes nested maps with any keys.
+func asStringMap(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = item
+		}
+		return out, true
+	}
+	return nil, false
+}
@@ -0,0 +1,163 @@
+package appconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppConfigDefaultsResolve(t *testing.T) {
+	t.Run("nil defaults", func(t *testing.T) {
+		var d *AppConfigDefaults
+		data := InstallerData{Name: lo.ToPtr("app")}
+		assert.Equal(t, data, d.Resolve(data, InstallerTypeBrew))
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		d := &AppConfigDefaults{
+			All: &InstallerData{
+				PreInstall:  lo.ToPtr("all"),
+				PostInstall: lo.ToPtr("all"),
+				PreUpdate:   lo.ToPtr("all"),
+				PostUpdate:  lo.ToPtr("all"),
+			},
+			Type: &map[InstallerType]InstallerData{
+				InstallerTypeBrew: {PostInstall: lo.ToPtr("type"), PreUpdate: lo.ToPtr("type"), PostUpdate: lo.ToPtr("type")},
+			},
+			Tag: &map[string]InstallerData{
+				"work": {PreUpdate: lo.ToPtr("tag"), PostUpdate: lo.ToPtr("tag")},
+			},
+		}
+		data := InstallerData{Tags: lo.ToPtr("work"), PostUpdate: lo.ToPtr("entry")}
+		result := d.Resolve(data, InstallerTypeBrew)
+		assert.Equal(t, "all", *result.PreInstall)
+		assert.Equal(t, "type", *result.PostInstall)
+		assert.Equal(t, "tag", *result.PreUpdate)
+		assert.Equal(t, "entry", *result.PostUpdate)
+	})
+
+	t.Run("tags inherited from type defaults select tag defaults", func(t *testing.T) {
+		d := &AppConfigDefaults{
+			Type: &map[InstallerType]InstallerData{InstallerTypeNpm: {Tags: lo.ToPtr("node")}},
+			Tag:  &map[string]InstallerData{"node": {Verbose: lo.ToPtr(true)}},
+		}
+		result := d.Resolve(InstallerData{Tags: lo.ToPtr("dev")}, InstallerTypeNpm)
+		assert.Equal(t, "node dev", *result.Tags)
+		assert.True(t, *result.Verbose)
+	})
+
+	t.Run("later tags win", func(t *testing.T) {
+		d := &AppConfigDefaults{
+			Tag: &map[string]InstallerData{
+				"a": {PreInstall: lo.ToPtr("a")},
+				"b": {PreInstall: lo.ToPtr("b")},
+			},
+		}
+		assert.Equal(t, "b", *d.Resolve(InstallerData{Tags: lo.ToPtr("a b")}, InstallerTypeBrew).PreInstall)
+		assert.Equal(t, "a", *d.Resolve(InstallerData{Tags: lo.ToPtr("b a")}, InstallerTypeBrew).PreInstall)
+	})
+
+	t.Run("identity fields are not inherited", func(t *testing.T) {
+		d := &AppConfigDefaults{
+			All: &InstallerData{
+				Name:     lo.ToPtr("default"),
+				Desc:     lo.ToPtr("default"),
+				Category: lo.ToPtr("default"),
+				Type:     InstallerTypeShell,
+				Steps:    &[]InstallerData{{Name: lo.ToPtr("step")}},
+				Defaults: &AppConfigDefaults{},
+			},
+		}
+		result := d.Resolve(InstallerData{Type: InstallerTypeBrew}, InstallerTypeBrew)
+		assert.Nil(t, result.Name)
+		assert.Nil(t, result.Desc)
+		assert.Nil(t, result.Category)
+		assert.Nil(t, result.Steps)
+		assert.Nil(t, result.Defaults)
+		assert.Equal(t, InstallerTypeBrew, result.Type)
+	})
+}
+
+func TestMergeInstallerData(t *testing.T) {
+	t.Run("merges every field", func(t *testing.T) {
+		// Every field set in override must end up in the result, so a new InstallerData field
+		// can't be forgotten in MergeInstallerData.
+		override := InstallerData{
+			Category:       lo.ToPtr("c"),
+			Desc:           lo.ToPtr("d"),
+			Enabled:        lo.ToPtr("true"),
+			When:           lo.ToPtr("os == \"linux\""),
+			Name:           lo.ToPtr("n"),
+			Names:          &[]string{"a", "b"},
+			Type:           InstallerTypeBrew,
+			Extends:        &[]string{"base"},
+			Tags:           lo.ToPtr("t"),
+			Env:            &EnvVars{{Name: "A", Value: "1"}},
+			EnvFile:        lo.ToPtr(".env"),
+			PlatformEnv:    &platform.PlatformMap[map[string]string]{Linux: &map[string]string{"A": "1"}},
+			Platforms:      &platform.Platforms{},
+			Archs:          &platform.Archs{},
+			Traits:         &platform.Traits{},
+			Machines:       &machine.Machines{},
+			Steps:          &[]InstallerData{},
+			Opts:           &map[string]any{"a": 1},
+			BinName:        lo.ToPtr("b"),
+			CheckHasUpdate: lo.ToPtr("u"),
+			CheckInstalled: lo.ToPtr("i"),
+			PostInstall:    lo.ToPtr("pi"),
+			PreInstall:     lo.ToPtr("pri"),
+			PostUpdate:     lo.ToPtr("pu"),
+			PreUpdate:      lo.ToPtr("pru"),
+			EnvShell:       &platform.PlatformMap[string]{Linux: lo.ToPtr("bash")},
+			SkipSummary:    &SkipSummary{},
+			Verbose:        lo.ToPtr(true),
+			Frequency:      lo.ToPtr("1d"),
+			Defaults:       &AppConfigDefaults{},
+			Section:        lo.ToPtr("s"),
+		}
+		result := reflect.ValueOf(MergeInstallerData(InstallerData{}, override))
+		for idx := range result.NumField() {
+			assert.False(t, result.Field(idx).IsZero(), "field %s is not merged", result.Type().Field(idx).Name)
+		}
+	})
+
+	t.Run("deep merges opts", func(t *testing.T) {
+		base := InstallerData{Opts: &map[string]any{
+			"tap":    "a/b",
+			"nested": map[any]any{"x": 1, "y": 2},
+		}}
+		override := InstallerData{Opts: &map[string]any{
+			"nested": map[string]any{"y": 3},
+		}}
+		result := MergeInstallerData(base, override)
+		assert.Equal(t, map[string]any{
+			"tap":    "a/b",
+			"nested": map[string]any{"x": 1, "y": 3},
+		}, *result.Opts)
+		assert.Equal(t, map[any]any{"x": 1, "y": 2}, (*base.Opts)["nested"])
+	})
+
+	t.Run("merges env and platform_env by key", func(t *testing.T) {
+		base := InstallerData{
+			Env:         &EnvVars{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}},
+			PlatformEnv: &platform.PlatformMap[map[string]string]{Linux: &map[string]string{"A": "1", "B": "1"}},
+		}
+		override := InstallerData{
+			Env:         &EnvVars{{Name: "B", Value: "2"}},
+			PlatformEnv: &platform.PlatformMap[map[string]string]{Linux: &map[string]string{"B": "2"}},
+		}
+		result := MergeInstallerData(base, override)
+		assert.Equal(t, map[string]string{"A": "1", "B": "2"}, result.Env.Map())
+		assert.Equal(t, map[string]string{"A": "1", "B": "2"}, *result.PlatformEnv.Linux)
+		assert.Equal(t, map[string]string{"A": "1", "B": "1"}, *base.PlatformEnv.Linux)
+	})
+
+	t.Run("combines tags", func(t *testing.T) {
+		result := MergeInstallerData(InstallerData{Tags: lo.ToPtr("a b")}, InstallerData{Tags: lo.ToPtr("b c")})
+		assert.Equal(t, "a b c", *result.Tags)
+	})
+}
@@ -0,0 +1,96 @@
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envFileKeyPattern matches valid variable names in env files.
+var envFileKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReadEnvFile reads the variables of a dotenv file, in the order they are declared.
+func ReadEnvFile(path string) (EnvVars, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	vars, err := ParseEnvFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// ParseEnvFile parses dotenv content. Each line is a KEY=VALUE pair, optionally prefixed with
+// "export". Lines starting with # are comments. Values can be:
+//   - unquoted, with trailing "# comments" removed
+//   - double-quoted, supporting \n, \t, \" and \\ escapes
+//   - single-quoted, which are taken literally and do not expand variable references
+func ParseEnvFile(content string) (EnvVars, error) {
+	out := EnvVars{}
+	for idx, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envFileKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", idx+1)
+		}
+		v, err := parseEnvFileValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", idx+1, err)
+		}
+		v.Name = key
+		out.put(v)
+	}
+	return out, nil
+}
+
+// parseEnvFileValue parses the value part of an env file line.
+func parseEnvFileValue(value string) (EnvVar, error) {
+	if value == "" {
+		return EnvVar{}, nil
+	}
+	switch quote := value[0]; quote {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return EnvVar{}, fmt.Errorf("unterminated single-quoted value")
+		}
+		return EnvVar{Value: value[1 : end+1], literal: true}, nil
+	case '"':
+		var sb strings.Builder
+		for idx := 1; idx < len(value); idx++ {
+			c := value[idx]
+			switch {
+			case c == '"':
+				return EnvVar{Value: sb.String()}, nil
+			case c == '\\' && idx+1 < len(value):
+				idx++
+				switch value[idx] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case '"', '\\':
+					sb.WriteByte(value[idx])
+				default:
+					sb.WriteByte('\\')
+					sb.WriteByte(value[idx])
+				}
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return EnvVar{}, fmt.Errorf("unterminated double-quoted value")
+	}
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		value = value[:idx]
+	}
+	return EnvVar{Value: strings.TrimSpace(value)}, nil
+}
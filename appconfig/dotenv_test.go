@@ -0,0 +1,65 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	vars, err := ParseEnvFile(`# comment
+PLAIN=value
+export EXPORTED=yes
+
+SPACED = padded # trailing comment
+DOUBLE="line\nbreak \"quoted\" # not a comment"
+SINGLE='${NOT_EXPANDED}'
+EMPTY=
+HASH=a#b
+PLAIN=again
+`)
+	require.NoError(t, err)
+	assert.Equal(t, EnvVars{
+		{Name: "PLAIN", Value: "again"},
+		{Name: "EXPORTED", Value: "yes"},
+		{Name: "SPACED", Value: "padded"},
+		{Name: "DOUBLE", Value: "line\nbreak \"quoted\" # not a comment"},
+		{Name: "SINGLE", Value: "${NOT_EXPANDED}", literal: true},
+		{Name: "EMPTY", Value: ""},
+		{Name: "HASH", Value: "a#b"},
+	}, vars)
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing equals", "FOO\n"},
+		{"invalid name", "1FOO=bar\n"},
+		{"unterminated double quote", "FOO=\"bar\n"},
+		{"unterminated single quote", "FOO='bar\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEnvFile(tt.content)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(file, []byte("A=1\nB=${A}2\nC='${A}3'\n"), 0644))
+	vars, err := ReadEnvFile(file)
+	require.NoError(t, err)
+	resolved, err := vars.Resolve(func(string) (string, bool) { return "", false })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A=1", "B=12", "C=${A}3"}, resolved)
+
+	_, err = ReadEnvFile(filepath.Join(t.TempDir(), "missing.env"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,540 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/chenasraf/sofmani/utils"
+	"github.com/samber/lo"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is a single environment variable. Its value is either given directly, or read at run time
+// from a file, the output of a command, or another environment variable.
+type EnvVar struct {
+	// Name is the variable name.
+	Name string `json:"-"       yaml:"-"`
+	// Value is the raw variable value, which may reference other variables.
+	Value string `json:"value"   yaml:"value"`
+	// File reads the value from a file. Trailing newlines are removed.
+	File string `json:"file"    yaml:"file"`
+	// Command reads the value from the output of a shell command. Trailing newlines are removed.
+	Command string `json:"command" yaml:"command"`
+	// Env copies the value of another environment variable.
+	Env string `json:"env"     yaml:"env"`
+	// Secret hides the value in log output and in the config description.
+	Secret bool `json:"secret"  yaml:"secret"`
+
+	// literal disables reference expansion, for single-quoted values in env files.
+	literal bool
+}
+
+// hasSource returns true if the value is read from a file, a command or another variable.
+func (v EnvVar) hasSource() bool {
+	return v.File != "" || v.Command != "" || v.Env != ""
+}
+
+// Describe returns a "KEY=VALUE" description of the variable for display, showing sources instead
+// of their values and hiding secret values.
+func (v EnvVar) Describe() string {
+	var source string
+	switch {
+	case v.File != "":
+		source = "file: " + v.File
+	case v.Command != "":
+		source = "command: " + v.Command
+	case v.Env != "":
+		source = "env: " + v.Env
+	}
+	switch {
+	case v.Secret && source != "":
+		return fmt.Sprintf("%s=<secret, %s>", v.Name, source)
+	case v.Secret:
+		return v.Name + "=<secret>"
+	case source != "":
+		return fmt.Sprintf("%s=<%s>", v.Name, source)
+	}
+	return v.Name + "=" + v.Value
+}
+
+// fields returns the object form of the variable, as written in config files.
+func (v EnvVar) fields() map[string]any {
+	fields := map[string]any{}
+	for key, value := range map[string]string{"value": v.Value, "file": v.File, "command": v.Command, "env": v.Env} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	if v.Secret {
+		fields["secret"] = true
+	}
+	return fields
+}
+
+// EnvVars is an ordered list of environment variables. It is written as a regular map in config
+// files, but keeps the order in which the variables are declared, so that values can reference
+// variables declared before them using $VAR, ${VAR} or ${VAR:-default}.
+type EnvVars []EnvVar
+
+// NewEnvVars creates an EnvVars from a map, sorted by variable name.
+func NewEnvVars(env map[string]string) EnvVars {
+	out := EnvVars{}
+	for k, v := range env {
+		out = append(out, EnvVar{Name: k, Value: v})
+	}
+	sort.Slice(out, func(a, b int) bool {
+		return out[a].Name < out[b].Name
+	})
+	return out
+}
+
+// Get returns the raw value of the variable with the given name.
+func (e *EnvVars) Get(name string) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	for _, v := range *e {
+		if v.Name == name {
+			return v.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the value of the variable with the given name. Existing variables keep their position,
+// new ones are added at the end.
+func (e *EnvVars) Set(name string, value string) {
+	e.put(EnvVar{Name: name, Value: value})
+}
+
+// put replaces the variable with the same name, or adds it at the end.
+func (e *EnvVars) put(v EnvVar) {
+	for idx, existing := range *e {
+		if existing.Name == v.Name {
+			(*e)[idx] = v
+			return
+		}
+	}
+	*e = append(*e, v)
+}
+
+// Merge sets every variable of other on e, in order.
+func (e *EnvVars) Merge(other *EnvVars) {
+	if other == nil {
+		return
+	}
+	for _, v := range *other {
+		e.put(v)
+	}
+}
+
+// Map returns the raw variables as a map.
+func (e *EnvVars) Map() map[string]string {
+	out := map[string]string{}
+	if e == nil {
+		return out
+	}
+	for _, v := range *e {
+		out[v.Name] = v.Value
+	}
+	return out
+}
+
+// Resolve reads the value of each variable, in order, and returns the variables as "KEY=VALUE"
+// strings. References are looked up in the variables resolved so far, and then using lookup.
+// Secret values are registered with the logger, so they are redacted from log output.
+func (e *EnvVars) Resolve(lookup func(name string) (string, bool)) ([]string, error) {
+	out := []string{}
+	if e == nil {
+		return out, nil
+	}
+	resolved := map[string]string{}
+	find := func(name string) (string, bool) {
+		if v, ok := resolved[name]; ok {
+			return v, true
+		}
+		return lookup(name)
+	}
+	for _, v := range *e {
+		value, err := v.resolve(find, out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve env %s: %w", v.Name, err)
+		}
+		if v.Secret {
+			logger.AddSecret(value)
+		}
+		resolved[v.Name] = value
+		out = append(out, v.Name+"="+value)
+	}
+	return out, nil
+}
+
+// resolve returns the value of a single variable. env holds the variables resolved so far, which
+// are passed on to source commands.
+func (v EnvVar) resolve(lookup func(name string) (string, bool), env []string) (string, error) {
+	switch {
+	case v.File != "":
+		path := utils.GetRealPath(env, utils.ExpandEnvRefs(v.File, lookup))
+		return cachedEnvSource("file:"+path, func() (string, error) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimRight(string(content), "\r\n"), nil
+		})
+	case v.Command != "":
+		return cachedEnvSource("command:"+v.Command, func() (string, error) {
+			out, err := utils.RunCmdGetOutput(env, utils.GetOSShell(nil), utils.GetOSShellArgs(v.Command)...)
+			if err != nil {
+				return "", fmt.Errorf("command %q failed: %w", v.Command, err)
+			}
+			return strings.TrimRight(string(out), "\r\n"), nil
+		})
+	case v.Env != "":
+		value, ok := lookup(v.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", v.Env)
+		}
+		return value, nil
+	case v.literal:
+		return v.Value, nil
+	}
+	return utils.ExpandHome(utils.ExpandEnvRefs(v.Value, lookup)), nil
+}
+
+var (
+	envSourceCache   = map[string]string{} // envSourceCache holds the values read from file and command sources.
+	envSourceCacheMu sync.Mutex            // envSourceCacheMu guards envSourceCache.
+)
+
+// cachedEnvSource reads a file or command source at most once per run, so that commands such as
+// password managers are not prompted again for every command an installer runs.
+func cachedEnvSource(key string, read func() (string, error)) (string, error) {
+	envSourceCacheMu.Lock()
+	defer envSourceCacheMu.Unlock()
+	if value, ok := envSourceCache[key]; ok {
+		return value, nil
+	}
+	value, err := read()
+	if err != nil {
+		return "", err
+	}
+	envSourceCache[key] = value
+	return value, nil
+}
+
+// resolveEnv resolves the variables loaded from envFile, followed by env, using lookup. Variables in
+// env replace env file variables of the same name.
+func resolveEnv(envFile *string, env *EnvVars, lookup func(name string) (string, bool)) ([]string, error) {
+	vars := EnvVars{}
+	if envFile != nil && *envFile != "" {
+		fileVars, err := ReadEnvFile(utils.GetRealPath(nil, *envFile))
+		if err != nil {
+			return nil, err
+		}
+		vars = fileVars
+	}
+	vars.Merge(env)
+	return vars.Resolve(lookup)
+}
+
+// lookupEnviron returns a lookup function over "KEY=VALUE" strings, falling back to the process
+// environment.
+func lookupEnviron(env []string) func(name string) (string, bool) {
+	vars := utils.EnvSliceAsMap(env)
+	return func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+}
+
+// withPlatformEnv resolves the variables of the current platform, sorted by name, on top of env.
+// Platform variables replace env variables of the same name.
+func withPlatformEnv(env []string, platformEnv *platform.PlatformMap[map[string]string]) ([]string, error) {
+	resolved := platformEnv.Resolve()
+	if resolved == nil {
+		return env, nil
+	}
+	vars := NewEnvVars(*resolved)
+	lines, err := vars.Resolve(lookupEnviron(env))
+	if err != nil {
+		return nil, err
+	}
+	out := slices.Clone(env)
+	for _, line := range lines {
+		name, value, _ := strings.Cut(line, "=")
+		out = withEnvVar(out, name, value)
+	}
+	return out, nil
+}
+
+// withEnvVar sets a variable in a list of "KEY=VALUE" strings, replacing it if already present.
+func withEnvVar(env []string, name string, value string) []string {
+	line := name + "=" + value
+	idx := slices.IndexFunc(env, func(existing string) bool {
+		return strings.HasPrefix(existing, name+"=")
+	})
+	if idx >= 0 {
+		env[idx] = line
+		return env
+	}
+	return append(env, line)
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling for EnvVars. It supports both the yaml.v2
+// decoder used for config files and the yaml.v3 decoder used for config content.
+func (e *EnvVars) UnmarshalYAML(unmarshal func(any) error) error {
+	var items yamlv2.MapSlice
+	if err := unmarshal(&items); err == nil {
+		// The MapSlice keeps the variable order and tells source objects apart, but decodes scalars
+		// as typed values. Their text is decoded separately, so that e.g. 1.20 stays "1.20".
+		texts := map[string]envValueText{}
+		if err := unmarshal(&texts); err != nil {
+			return err
+		}
+		out := EnvVars{}
+		for _, item := range items {
+			name := fmt.Sprint(item.Key)
+			text := texts[name]
+			switch value := item.Value.(type) {
+			case yamlv2.MapSlice:
+				fields := map[string]any{}
+				for _, field := range value {
+					key := fmt.Sprint(field.Key)
+					fields[key] = field.Value
+					if fieldText, ok := text.Fields[key]; ok && key != "secret" && fieldText.Text != nil {
+						fields[key] = *fieldText.Text
+					}
+				}
+				v, err := envVarFromFields(name, fields)
+				if err != nil {
+					return err
+				}
+				out = append(out, v)
+			case []any:
+				return fmt.Errorf("env value of %s must be a string or a source object", name)
+			default:
+				v := EnvVar{Name: name, Value: envValueString(value)}
+				if text.Text != nil {
+					v.Value = *text.Text
+				}
+				out = append(out, v)
+			}
+		}
+		*e = out
+		return nil
+	}
+
+	var captured nodeCapture
+	if err := unmarshal(&captured); err != nil {
+		return err
+	}
+	node := captured.node
+	if node == nil || node.Kind != yaml.MappingNode {
+		return fmt.Errorf("env must be a map of variable names to values")
+	}
+	out := EnvVars{}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key, value := node.Content[idx], node.Content[idx+1]
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if value.Tag == "!!null" {
+				value.Value = ""
+			}
+			out = append(out, EnvVar{Name: key.Value, Value: value.Value})
+		case yaml.MappingNode:
+			fields := map[string]any{}
+			for fieldIdx := 0; fieldIdx+1 < len(value.Content); fieldIdx += 2 {
+				fieldKey, fieldValue := value.Content[fieldIdx], value.Content[fieldIdx+1]
+				// Scalars keep their text, except secret, which is a boolean.
+				if fieldValue.Kind == yaml.ScalarNode && fieldKey.Value != "secret" {
+					fields[fieldKey.Value] = ""
+					if fieldValue.Tag != "!!null" {
+						fields[fieldKey.Value] = fieldValue.Value
+					}
+					continue
+				}
+				var decoded any
+				if err := fieldValue.Decode(&decoded); err != nil {
+					return err
+				}
+				fields[fieldKey.Value] = decoded
+			}
+			v, err := envVarFromFields(key.Value, fields)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", value.Line, err)
+			}
+			out = append(out, v)
+		default:
+			return fmt.Errorf("line %d: env value of %s must be a string or a source object", value.Line, key.Value)
+		}
+	}
+	*e = out
+	return nil
+}
+
+// envValueText captures the text of an env value in a yaml.v2 config file, as written. Fields holds
+// the text of the fields of a source object.
+type envValueText struct {
+	Text   *string
+	Fields map[string]envValueText
+}
+
+// UnmarshalYAML implements yaml.v2 unmarshaling for envValueText. Values that are neither scalars
+// nor objects are left empty.
+func (t *envValueText) UnmarshalYAML(unmarshal func(any) error) error {
+	var text string
+	if err := unmarshal(&text); err == nil {
+		t.Text = &text
+		return nil
+	}
+	var fields map[string]envValueText
+	if err := unmarshal(&fields); err == nil {
+		t.Fields = fields
+	}
+	return nil
+}
+
+// nodeCapture captures the raw yaml.v3 node it is decoded from.
+type nodeCapture struct {
+	node *yaml.Node
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *nodeCapture) UnmarshalYAML(value *yaml.Node) error {
+	c.node = value
+	return nil
+}
+
+// MarshalYAML implements custom YAML marshaling for EnvVars, keeping the variable order.
+func (e EnvVars) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, v := range e {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: v.Value}
+		if v.hasSource() || v.Secret {
+			value = &yaml.Node{}
+			if err := value.Encode(v.fields()); err != nil {
+				return nil, err
+			}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: v.Name}, value)
+	}
+	return node, nil
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for EnvVars, keeping the variable order.
+func (e *EnvVars) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("env must be an object of variable names to values")
+	}
+	out := EnvVars{}
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		name := fmt.Sprint(keyTok)
+		switch value := value.(type) {
+		case map[string]any:
+			v, err := envVarFromFields(name, value)
+			if err != nil {
+				return err
+			}
+			out = append(out, v)
+		case []any:
+			return fmt.Errorf("env value of %s must be a string or a source object", name)
+		default:
+			out = append(out, EnvVar{Name: name, Value: envValueString(value)})
+		}
+	}
+	*e = out
+	return nil
+}
+
+// MarshalJSON implements custom JSON marshaling for EnvVars, keeping the variable order.
+func (e EnvVars) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, v := range e {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		var value []byte
+		if v.hasSource() || v.Secret {
+			value, err = json.Marshal(v.fields())
+		} else {
+			value, err = json.Marshal(v.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// envValueString converts a decoded scalar env value to its string form.
+func envValueString(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// envVarFromFields reads a variable from its object form, e.g. { command: "pass show github", secret: true }.
+func envVarFromFields(name string, fields map[string]any) (EnvVar, error) {
+	v := EnvVar{Name: name}
+	for key, value := range fields {
+		switch key {
+		case "value":
+			v.Value = envValueString(value)
+		case "file":
+			v.File = envValueString(value)
+		case "command":
+			v.Command = envValueString(value)
+		case "env":
+			v.Env = envValueString(value)
+		case "secret":
+			secret, ok := value.(bool)
+			if !ok {
+				return v, fmt.Errorf("env %s: secret must be a boolean", name)
+			}
+			v.Secret = secret
+		default:
+			return v, fmt.Errorf("env %s: unknown key %q, expected one of value, file, command, env or secret", name, key)
+		}
+	}
+	sources := lo.Count([]bool{v.Value != "", v.File != "", v.Command != "", v.Env != ""}, true)
+	if sources > 1 {
+		return v, fmt.Errorf("env %s: only one of value, file, command or env can be set", name)
+	}
+	return v, nil
+}
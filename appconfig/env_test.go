@@ -0,0 +1,415 @@
+package appconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/chenasraf/sofmani/utils"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvVarsKeepOrder(t *testing.T) {
+	expected := EnvVars{
+		{Name: "ZED", Value: "1"},
+		{Name: "ALPHA", Value: "two=2"},
+		{Name: "MIDDLE", Value: ""},
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml file", "sofmani.yaml", "env:\n  ZED: 1\n  ALPHA: two=2\n  MIDDLE:\n"},
+		{"json file", "sofmani.json", `{"env": {"ZED": 1, "ALPHA": "two=2", "MIDDLE": null}}`},
+		{"toml file", "sofmani.toml", "[env]\nZED = 1\nALPHA = \"two=2\"\nMIDDLE = \"\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0644))
+			config, err := ParseConfigFrom(file)
+			require.NoError(t, err)
+			require.NotNil(t, config.Env)
+			assert.Equal(t, expected, *config.Env)
+		})
+		t.Run(tt.name+" content", func(t *testing.T) {
+			config, err := ParseConfigFromContent([]byte(tt.content), ConfigFormatFromPath(tt.file))
+			require.NoError(t, err)
+			require.NotNil(t, config.Env)
+			assert.Equal(t, expected, *config.Env)
+		})
+	}
+}
+
+func TestEnvVarsKeepScalarText(t *testing.T) {
+	content := `env:
+  GO_VERSION: 1.20
+  MODE: 0755
+  ENABLED: true
+  PINNED: { value: 1.20 }
+install:
+  - name: tool
+    type: shell
+    env:
+      GO_VERSION: 1.20
+      MODE: 0755
+      ENABLED: true
+`
+	expected := EnvVars{
+		{Name: "GO_VERSION", Value: "1.20"},
+		{Name: "MODE", Value: "0755"},
+		{Name: "ENABLED", Value: "true"},
+	}
+	file := filepath.Join(t.TempDir(), "sofmani.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	fromFile, err := ParseConfigFrom(file)
+	require.NoError(t, err)
+	fromContent, err := ParseConfigFromContent([]byte(content), ConfigFormatYAML)
+	require.NoError(t, err)
+	for _, config := range []*AppConfig{fromFile, fromContent} {
+		assert.Equal(t, append(slices.Clone(expected), EnvVar{Name: "PINNED", Value: "1.20"}), *config.Env)
+		assert.Equal(t, expected, *config.Install[0].Env)
+	}
+}
+
+func TestEnvVarsInstallerEnvOrder(t *testing.T) {
+	config, err := ParseConfigFromContent([]byte(`
+install:
+  - name: tool
+    type: shell
+    env:
+      B: b
+      A: ${B}a
+`), ConfigFormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, EnvVars{{Name: "B", Value: "b"}, {Name: "A", Value: "${B}a"}}, *config.Install[0].Env)
+	assert.Equal(t, []string{"B=b", "A=ba"}, config.Install[0].Environ())
+}
+
+func TestEnvVarsRejectsNestedValues(t *testing.T) {
+	_, err := ParseConfigFromContent([]byte("env:\n  FOO:\n    bar: baz\n"), ConfigFormatYAML)
+	assert.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "sofmani.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("env:\n  FOO: [a, b]\n"), 0644))
+	_, err = ParseConfigFrom(file)
+	assert.Error(t, err)
+
+	var env EnvVars
+	assert.Error(t, json.Unmarshal([]byte(`{"FOO": {"bar": "baz"}}`), &env))
+	assert.Error(t, json.Unmarshal([]byte(`["FOO"]`), &env))
+}
+
+func TestEnvVarsMarshal(t *testing.T) {
+	env := EnvVars{{Name: "B", Value: "1"}, {Name: "A", Value: "x=\"y\""}}
+	out, err := json.Marshal(env)
+	require.NoError(t, err)
+	assert.Equal(t, `{"B":"1","A":"x=\"y\""}`, string(out))
+
+	var decoded EnvVars
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, env, decoded)
+}
+
+func TestEnvVarsSetAndMerge(t *testing.T) {
+	env := EnvVars{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}
+	env.Merge(&EnvVars{{Name: "C", Value: "3"}, {Name: "A", Value: "4"}})
+	assert.Equal(t, EnvVars{{Name: "A", Value: "4"}, {Name: "B", Value: "2"}, {Name: "C", Value: "3"}}, env)
+
+	value, ok := env.Get("B")
+	assert.True(t, ok)
+	assert.Equal(t, "2", value)
+	_, ok = env.Get("D")
+	assert.False(t, ok)
+
+	var nilEnv *EnvVars
+	_, ok = nilEnv.Get("A")
+	assert.False(t, ok)
+	resolved, err := nilEnv.Resolve(os.LookupEnv)
+	require.NoError(t, err)
+	assert.Empty(t, resolved)
+}
+
+func TestEnvVarsResolve(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+	env := EnvVars{
+		{Name: "TOOLS", Value: "$HOME/tools"},
+		{Name: "BIN", Value: "${TOOLS}/bin"},
+		{Name: "EDITOR", Value: "${VISUAL:-vim}"},
+		{Name: "LATER", Value: "${AFTER:-unset}"},
+		{Name: "AFTER", Value: "set"},
+		{Name: "FLAGS", Value: "--a=1 --b=2"},
+	}
+	resolved, err := env.Resolve(lookup)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"TOOLS=/home/user/tools",
+		"BIN=/home/user/tools/bin",
+		"EDITOR=vim",
+		"LATER=unset",
+		"AFTER=set",
+		"FLAGS=--a=1 --b=2",
+	}, resolved)
+}
+
+func TestAppConfigEnvironPath(t *testing.T) {
+	sep := string(filepath.ListSeparator)
+	t.Setenv("PATH", strings.Join([]string{"/usr/bin", "/bin"}, sep))
+	t.Setenv("SOFMANI_TEST_ROOT", "/opt/sofmani")
+
+	config := AppConfig{
+		Env: &EnvVars{
+			{Name: "TOOLS", Value: "${SOFMANI_TEST_ROOT}/tools"},
+		},
+		PathPrepend: &[]string{"${SOFMANI_TEST_ROOT}/bin"},
+		PathAppend:  &[]string{"/usr/local/go/bin"},
+	}
+	env := config.Environ()
+	assert.Equal(t, []string{
+		"TOOLS=/opt/sofmani/tools",
+		"PATH=" + strings.Join([]string{"/opt/sofmani/bin", "/usr/bin", "/bin", "/usr/local/go/bin"}, sep),
+	}, env)
+
+	// Once applied to the process, the environment is not resolved again.
+	t.Setenv("PATH", "/changed")
+	assert.Equal(t, env, config.Environ())
+}
+
+func TestAppConfigEnvironPathUsesEnvPath(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	config := AppConfig{
+		Env:        &EnvVars{{Name: "PATH", Value: "/custom/bin"}},
+		PathAppend: &[]string{"/extra/bin"},
+	}
+	assert.Equal(t, []string{"PATH=/custom/bin" + string(filepath.ListSeparator) + "/extra/bin"}, config.Environ())
+}
+
+func TestPlatformEnvReferencesEnv(t *testing.T) {
+	platformEnv := map[string]string{"BIN": "${ROOT}/bin", "ROOT": "/platform"}
+	data := InstallerData{
+		Env: &EnvVars{{Name: "ROOT", Value: "/base"}, {Name: "OTHER", Value: "x"}},
+		PlatformEnv: &platform.PlatformMap[map[string]string]{
+			MacOS:   &platformEnv,
+			Linux:   &platformEnv,
+			Windows: &platformEnv,
+		},
+	}
+	// Platform variables are resolved in name order, on top of env.
+	assert.Equal(t, []string{"ROOT=/platform", "OTHER=x", "BIN=/base/bin"}, data.Environ())
+}
+
+func TestApplyOverlayPathLists(t *testing.T) {
+	c := &AppConfig{
+		PathPrepend: &[]string{"/base/first"},
+		PathAppend:  &[]string{"/base/last"},
+	}
+	c.ApplyOverlay(&AppConfigOverlay{AppConfig: AppConfig{
+		PathPrepend: lo.ToPtr([]string{"/overlay/first"}),
+		PathAppend:  lo.ToPtr([]string{"/overlay/last"}),
+	}})
+	assert.Equal(t, []string{"/overlay/first", "/base/first"}, *c.PathPrepend)
+	assert.Equal(t, []string{"/base/last", "/overlay/last"}, *c.PathAppend)
+}
+
+func TestEnvVarsSources(t *testing.T) {
+	expected := EnvVars{
+		{Name: "TOKEN", Command: "pass show github", Secret: true},
+		{Name: "KEY", File: "~/.secrets/key"},
+		{Name: "COPY", Env: "HOME"},
+		{Name: "PLAIN", Value: "x", Secret: true},
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml file", "sofmani.yaml", `env:
+  TOKEN: { command: pass show github, secret: true }
+  KEY: { file: ~/.secrets/key }
+  COPY: { env: HOME }
+  PLAIN: { value: x, secret: true }
+`},
+		{"json file", "sofmani.json", `{"env": {
+  "TOKEN": {"command": "pass show github", "secret": true},
+  "KEY": {"file": "~/.secrets/key"},
+  "COPY": {"env": "HOME"},
+  "PLAIN": {"value": "x", "secret": true}
+}}`},
+		{"toml file", "sofmani.toml", `[env]
+TOKEN = { command = "pass show github", secret = true }
+KEY = { file = "~/.secrets/key" }
+COPY = { env = "HOME" }
+PLAIN = { value = "x", secret = true }
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0644))
+			config, err := ParseConfigFrom(file)
+			require.NoError(t, err)
+			assert.Equal(t, expected, *config.Env)
+		})
+		t.Run(tt.name+" content", func(t *testing.T) {
+			config, err := ParseConfigFromContent([]byte(tt.content), ConfigFormatFromPath(tt.file))
+			require.NoError(t, err)
+			assert.Equal(t, expected, *config.Env)
+		})
+	}
+}
+
+func TestEnvVarsSourceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown key", "env:\n  FOO: { path: x }\n"},
+		{"multiple sources", "env:\n  FOO: { file: x, command: y }\n"},
+		{"non-boolean secret", "env:\n  FOO: { value: x, secret: yes please }\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfigFromContent([]byte(tt.content), ConfigFormatYAML)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEnvVarsResolveSources(t *testing.T) {
+	platform.SetOS(runtime.GOOS)
+	logger.SetLogFile(filepath.Join(t.TempDir(), "sofmani.log"))
+	logger.InitLogger(false)
+	t.Cleanup(logger.CloseLogger)
+	t.Setenv("SOFMANI_TEST_SOURCE", "from-env")
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-value\n"), 0644))
+
+	env := EnvVars{
+		{Name: "DIR", Value: dir},
+		{Name: "FROM_FILE", File: "${DIR}/secret", Secret: true},
+		{Name: "FROM_COMMAND", Command: "echo command-$DIR"},
+		{Name: "FROM_ENV", Env: "SOFMANI_TEST_SOURCE"},
+	}
+	resolved, err := env.Resolve(os.LookupEnv)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"DIR=" + dir,
+		"FROM_FILE=file-value",
+		"FROM_COMMAND=command-" + dir,
+		"FROM_ENV=from-env",
+	}, resolved)
+	assert.Equal(t, "token=****", logger.Redact("token=file-value"))
+
+	_, err = (&EnvVars{{Name: "MISSING", Env: "SOFMANI_TEST_UNSET"}}).Resolve(os.LookupEnv)
+	assert.ErrorContains(t, err, "MISSING")
+	_, err = (&EnvVars{{Name: "MISSING", File: filepath.Join(dir, "missing")}}).Resolve(os.LookupEnv)
+	assert.Error(t, err)
+	_, err = (&EnvVars{{Name: "FAILING", Command: "exit 3"}}).Resolve(os.LookupEnv)
+	assert.Error(t, err)
+}
+
+func TestEnvVarDescribe(t *testing.T) {
+	tests := []struct {
+		v        EnvVar
+		expected string
+	}{
+		{EnvVar{Name: "A", Value: "1"}, "A=1"},
+		{EnvVar{Name: "A", Value: "1", Secret: true}, "A=<secret>"},
+		{EnvVar{Name: "A", Command: "pass show a", Secret: true}, "A=<secret, command: pass show a>"},
+		{EnvVar{Name: "A", File: "~/a"}, "A=<file: ~/a>"},
+		{EnvVar{Name: "A", Env: "B"}, "A=<env: B>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.v.Describe())
+		})
+	}
+}
+
+func TestEnvVarsMarshalSources(t *testing.T) {
+	env := EnvVars{{Name: "A", Value: "1"}, {Name: "B", Command: "pass show b", Secret: true}}
+	out, err := json.Marshal(env)
+	require.NoError(t, err)
+	assert.Equal(t, `{"A":"1","B":{"command":"pass show b","secret":true}}`, string(out))
+
+	var decoded EnvVars
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, env, decoded)
+}
+
+func TestEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("FROM_FILE=file\nSHARED=file\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tool.env"), []byte("TOOL=tool\n"), 0644))
+	file := filepath.Join(dir, "sofmani.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`env_file: .env
+env:
+  SHARED: env
+  DERIVED: ${FROM_FILE}-derived
+install:
+  - name: tools
+    type: group
+    steps:
+      - name: tool
+        type: shell
+        env_file: tool.env
+`), 0644))
+
+	config, err := ParseConfigFrom(file)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".env"), *config.EnvFile)
+	step := (*config.Install[0].Steps)[0]
+	assert.Equal(t, filepath.Join(dir, "tool.env"), *step.EnvFile)
+
+	env, err := config.ResolveEnviron()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FROM_FILE=file", "SHARED=env", "DERIVED=file-derived"}, env)
+
+	stepEnv, err := step.ResolveEnviron()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOOL=tool"}, stepEnv)
+
+	missing := InstallerData{EnvFile: lo.ToPtr(filepath.Join(dir, "missing.env"))}
+	_, err = missing.ResolveEnviron()
+	assert.Error(t, err)
+	assert.Empty(t, missing.Environ())
+}
+
+func TestEnvLiteralDollarReachesCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh to print the variables")
+	}
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("PASS='p$HOME'\n"), 0644))
+	data := InstallerData{
+		EnvFile: &envFile,
+		Env: &EnvVars{
+			{Name: "SECRET", Command: "printf 's$HOME'"},
+			{Name: "BIN", Value: "~/bin"},
+		},
+	}
+	env, err := data.ResolveEnviron()
+	require.NoError(t, err)
+
+	out, err := utils.RunCmdGetOutput(env, "sh", "-c", `printf '%s|%s|%s' "$PASS" "$SECRET" "$BIN"`)
+	require.NoError(t, err)
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, "p$HOME|s$HOME|"+filepath.Join(home, "bin"), string(out))
+}
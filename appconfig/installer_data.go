@@ -1,11 +1,11 @@
 package appconfig
 
 import (
+	"os"
 	"strings"
 
 	"github.com/chenasraf/sofmani/machine"
 	"github.com/chenasraf/sofmani/platform"
-	"github.com/chenasraf/sofmani/utils"
 	"github.com/samber/lo"
 )
 
@@ -51,18 +51,36 @@ type InstallerData struct {
 	Desc *string `json:"desc"              yaml:"desc"`
 	// Enabled determines if the installer is enabled. Can be a boolean string ("true", "false") or a condition.
 	Enabled *string `json:"enabled"           yaml:"enabled"`
+	// When is an expression that decides whether the installer runs, evaluated without a shell,
+	// e.g. os == "linux" && has("systemctl").
+	When *string `json:"when"              yaml:"when"`
 	// Name is the name of the installer.
 	Name *string `json:"name"              yaml:"name"`
+	// Names is a list of packages to install with a single package manager invocation, for the
+	// installer types that support it. Name defaults to the package names.
+	Names *[]string `json:"names"             yaml:"names"`
 	// Type is the type of the installer.
 	Type InstallerType `json:"type"              yaml:"type"`
+	// Extends is a list of template names to build the installer from. Later templates win, and
+	// the installer's own fields win over all of them.
+	Extends *[]string `json:"extends"           yaml:"extends"`
 	// Tags is a space-separated list of tags for the installer.
 	Tags *string `json:"tags"              yaml:"tags"`
-	// Env is a map of environment variables to set for the installer.
-	Env *map[string]string `json:"env"               yaml:"env"`
+	// Env is a map of environment variables to set for the installer. Variables are set in order,
+	// and values can reference variables declared before them, e.g. ${VAR} or ${VAR:-default}.
+	Env *EnvVars `json:"env"               yaml:"env"`
+	// EnvFile is the path to a dotenv file to load variables from for the installer. Variables in
+	// env take precedence. Relative paths are resolved against the config file's directory.
+	EnvFile *string `json:"env_file"          yaml:"env_file"`
 	// PlatformEnv is a map of platform-specific environment variables to set for the installer.
 	PlatformEnv *platform.PlatformMap[map[string]string] `json:"platform_env"      yaml:"platform_env"`
 	// Platforms is a list of platforms where this installer should run.
 	Platforms *platform.Platforms `json:"platforms"         yaml:"platforms"`
+	// Archs is a list of CPU architectures where this installer should run.
+	Archs *platform.Archs `json:"archs"             yaml:"archs"`
+	// Traits is a list of environment traits (e.g., wsl, ssh) that decide whether this installer
+	// should run.
+	Traits *platform.Traits `json:"traits"            yaml:"traits"`
 	// Machines is a list of machine IDs where this installer should run.
 	Machines *machine.Machines `json:"machines"          yaml:"machines"`
 	// Steps is a list of sub-installers for group installers.
@@ -93,6 +111,12 @@ type InstallerData struct {
 	// the installer runs. After a successful install/update, the next run will be skipped
 	// until the frequency period has elapsed.
 	Frequency *string `json:"frequency"          yaml:"frequency"`
+	// Defaults are defaults for the entries under a category, and are only valid on category
+	// entries. They work like the top-level defaults, and win over them.
+	Defaults *AppConfigDefaults `json:"defaults"          yaml:"defaults"`
+	// Section is the name of the category the entry is listed under, if any. It is set when the
+	// config is parsed.
+	Section *string `json:"-"                 yaml:"-"`
 }
 
 // InstallerType represents the type of an installer.
@@ -118,11 +142,35 @@ const (
 	InstallerTypeYay           InstallerType = "yay"            // InstallerTypeYay represents a yay (AUR helper) package installer.
 	InstallerTypeCargo         InstallerType = "cargo"          // InstallerTypeCargo represents a Rust cargo package installer.
 	InstallerTypeGo            InstallerType = "go"             // InstallerTypeGo represents a Go package installer (go install).
+	InstallerTypeDnf           InstallerType = "dnf"            // InstallerTypeDnf represents a dnf package installer.
+	InstallerTypeYum           InstallerType = "yum"            // InstallerTypeYum represents a yum package installer.
+	InstallerTypeZypper        InstallerType = "zypper"         // InstallerTypeZypper represents a zypper package installer.
+	InstallerTypeFlatpak       InstallerType = "flatpak"        // InstallerTypeFlatpak represents a Flatpak application installer.
+	InstallerTypeSnap          InstallerType = "snap"           // InstallerTypeSnap represents a snap package installer.
+	InstallerTypeUv            InstallerType = "uv"             // InstallerTypeUv represents a uv tool installer.
+	InstallerTypeGem           InstallerType = "gem"            // InstallerTypeGem represents a RubyGems package installer.
+	InstallerTypeMise          InstallerType = "mise"           // InstallerTypeMise represents a mise tool installer.
+	InstallerTypeNix           InstallerType = "nix"            // InstallerTypeNix represents a Nix profile package installer.
+	InstallerTypeURL           InstallerType = "url"            // InstallerTypeURL represents a URL download installer.
+	InstallerTypeLink          InstallerType = "link"           // InstallerTypeLink represents a symlink (stow-like) installer.
 )
 
 // Environ returns the combined environment variables for the installer as a slice of strings.
+// Variables that fail to resolve are left out; use ResolveEnviron to handle the error.
 func (i *InstallerData) Environ() []string {
-	return utils.EnvMapAsSlice(utils.CombineEnvMaps(i.Env, i.PlatformEnv.Resolve()))
+	env, _ := i.ResolveEnviron()
+	return env
+}
+
+// ResolveEnviron returns the combined environment variables for the installer as a slice of
+// strings: env_file, env, then platform_env. Variable references are resolved against the process
+// environment.
+func (i *InstallerData) ResolveEnviron() ([]string, error) {
+	env, err := resolveEnv(i.EnvFile, i.Env, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	return withPlatformEnv(env, i.PlatformEnv)
 }
 
 // GetTagsList returns the list of tags for the installer.
@@ -18,7 +18,7 @@ func TestInstallerData_Environ(t *testing.T) {
 	})
 
 	t.Run("returns Env values when only Env is set", func(t *testing.T) {
-		env := map[string]string{"KEY": "value", "OTHER": "test"}
+		env := NewEnvVars(map[string]string{"KEY": "value", "OTHER": "test"})
 		data := &InstallerData{
 			Env: &env,
 		}
@@ -46,7 +46,7 @@ func TestInstallerData_Environ(t *testing.T) {
 	})
 
 	t.Run("combines Env and PlatformEnv", func(t *testing.T) {
-		env := map[string]string{"COMMON": "value"}
+		env := NewEnvVars(map[string]string{"COMMON": "value"})
 		macEnv := map[string]string{"SPECIFIC": "mac"}
 		data := &InstallerData{
 			Env: &env,
@@ -60,7 +60,7 @@ func TestInstallerData_Environ(t *testing.T) {
 	})
 
 	t.Run("PlatformEnv overrides Env for same key", func(t *testing.T) {
-		env := map[string]string{"KEY": "original"}
+		env := NewEnvVars(map[string]string{"KEY": "original"})
 		macEnv := map[string]string{"KEY": "platform"}
 		data := &InstallerData{
 			Env: &env,
@@ -145,6 +145,17 @@ func TestInstallerType_Constants(t *testing.T) {
 		assert.Equal(t, InstallerType("yay"), InstallerTypeYay)
 		assert.Equal(t, InstallerType("cargo"), InstallerTypeCargo)
 		assert.Equal(t, InstallerType("go"), InstallerTypeGo)
+		assert.Equal(t, InstallerType("dnf"), InstallerTypeDnf)
+		assert.Equal(t, InstallerType("yum"), InstallerTypeYum)
+		assert.Equal(t, InstallerType("zypper"), InstallerTypeZypper)
+		assert.Equal(t, InstallerType("flatpak"), InstallerTypeFlatpak)
+		assert.Equal(t, InstallerType("snap"), InstallerTypeSnap)
+		assert.Equal(t, InstallerType("uv"), InstallerTypeUv)
+		assert.Equal(t, InstallerType("gem"), InstallerTypeGem)
+		assert.Equal(t, InstallerType("mise"), InstallerTypeMise)
+		assert.Equal(t, InstallerType("nix"), InstallerTypeNix)
+		assert.Equal(t, InstallerType("url"), InstallerTypeURL)
+		assert.Equal(t, InstallerType("link"), InstallerTypeLink)
 	})
 }
 
@@ -0,0 +1,261 @@
+package appconfig
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
+)
+
+// AppConfigOverlay is a partial configuration that is merged on top of the base config
+// for a specific machine. It accepts every top-level config field, plus a list of
+// installer names from the base config to disable.
+type AppConfigOverlay struct {
+	AppConfig `yaml:",inline"`
+	// Disable is a list of installer names from the base config that should not run on this machine.
+	// Only valid in machine overlay files.
+	Disable []string `json:"disable" yaml:"disable"`
+}
+
+// FindOverlayFiles returns the machine-specific overlay files that apply to the given config file,
+// in the order they should be merged. Overlays are looked up next to the config file:
+//   - sofmani.<alias>.<ext>, where alias is the machine_aliases entry for the current machine
+//   - sofmani.d/<machine-id>.<ext>
+//   - sofmani.d/<alias>.<ext>
+func FindOverlayFiles(configFile string, aliases map[string]string) []string {
+	dir := filepath.Dir(configFile)
+	machineID := machine.GetMachineID()
+	alias := machine.ResolveAlias(machineID, aliases)
+
+	bases := []string{}
+	if alias != "" {
+		bases = append(bases, filepath.Join(dir, "sofmani."+alias))
+	}
+	bases = append(bases, filepath.Join(dir, "sofmani.d", machineID))
+	if alias != "" {
+		bases = append(bases, filepath.Join(dir, "sofmani.d", alias))
+	}
+
+	files := []string{}
+	for _, base := range bases {
+		file := tryConfigBase(base)
+		// Never treat the base config itself as an overlay (e.g. an alias named "d").
+		if file != "" && file != configFile {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// FindFragmentFiles returns the config fragment files that apply to the given config file, in the
+// order they should be merged: every file with a supported config extension in the conf.d directory
+// next to the config file, sorted by name.
+func FindFragmentFiles(configFile string) []string {
+	dir := filepath.Join(filepath.Dir(configFile), "conf.d")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := []string{}
+	for _, entry := range entries {
+		ext := strings.TrimPrefix(filepath.Ext(entry.Name()), ".")
+		if entry.IsDir() || !slices.Contains(configExtensions, ext) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files
+}
+
+// ParseOverlayFrom parses an overlay configuration from the given file.
+func ParseOverlayFrom(file string) (*AppConfigOverlay, error) {
+	overlay := &AppConfigOverlay{}
+	if err := parseConfigFile(overlay, file); err != nil {
+		return nil, err
+	}
+	overlay.resolveEnvFilePaths(filepath.Dir(file))
+	return overlay, nil
+}
+
+// applyFragments finds and merges all conf.d fragments of the config file into the config. Fragments
+// are merged the same way as machine overlays, before them.
+func (c *AppConfig) applyFragments(configFile string) error {
+	for _, file := range FindFragmentFiles(configFile) {
+		fragment, err := ParseOverlayFrom(file)
+		if err != nil {
+			return err
+		}
+		// disable is only valid in machine overlay files. In a fragment it is ignored, and reported
+		// as an unknown key.
+		fragment.Disable = nil
+		c.ApplyOverlay(fragment)
+		c.Fragments = append(c.Fragments, file)
+	}
+	return nil
+}
+
+// applyMachineOverlays finds and merges all overlays for the current machine into the config.
+func (c *AppConfig) applyMachineOverlays(configFile string) error {
+	var aliases map[string]string
+	if c.MachineAliases != nil {
+		aliases = *c.MachineAliases
+	}
+	for _, file := range FindOverlayFiles(configFile, aliases) {
+		overlay, err := ParseOverlayFrom(file)
+		if err != nil {
+			return err
+		}
+		c.ApplyOverlay(overlay)
+		c.Overlays = append(c.Overlays, file)
+	}
+	return nil
+}
+
+// ApplyOverlay merges an overlay into the config. Scalar fields set in the overlay replace the
+// base values, map fields (env, platform_env, repo_update, machine_aliases, templates,
+// defaults.type, defaults.tag) are merged key by key with the overlay winning, defaults.all is
+// merged field by field, overlay installers are appended to the install list, and installers named
+// in the overlay's disable list are disabled.
+func (c *AppConfig) ApplyOverlay(o *AppConfigOverlay) {
+	if o == nil {
+		return
+	}
+	if o.Debug != nil {
+		c.Debug = o.Debug
+	}
+	if o.CheckUpdates != nil {
+		c.CheckUpdates = o.CheckUpdates
+	}
+	if o.Summary != nil {
+		c.Summary = o.Summary
+	}
+	if o.Strict != nil {
+		c.Strict = o.Strict
+	}
+	if o.CategoryDisplay != nil {
+		c.CategoryDisplay = o.CategoryDisplay
+	}
+	c.RepoUpdate = mergeMapPtr(c.RepoUpdate, o.RepoUpdate)
+	c.MachineAliases = mergeMapPtr(c.MachineAliases, o.MachineAliases)
+	c.Templates = mergeMapPtr(c.Templates, o.Templates)
+	c.Env = mergeEnvVars(c.Env, o.Env)
+	if o.EnvFile != nil {
+		c.EnvFile = o.EnvFile
+	}
+	// Overlay directories take precedence: they are prepended before, and appended after, the
+	// base config's directories.
+	c.PathPrepend = concatSlicePtr(o.PathPrepend, c.PathPrepend)
+	c.PathAppend = concatSlicePtr(c.PathAppend, o.PathAppend)
+	c.PlatformEnv = mergePlatformEnv(c.PlatformEnv, o.PlatformEnv)
+	if o.Defaults != nil {
+		if c.Defaults == nil {
+			c.Defaults = &AppConfigDefaults{}
+		}
+		if o.Defaults.All != nil {
+			all := MergeInstallerData(lo.FromPtr(c.Defaults.All), *o.Defaults.All)
+			c.Defaults.All = &all
+		}
+		c.Defaults.Type = mergeMapPtr(c.Defaults.Type, o.Defaults.Type)
+		c.Defaults.Tag = mergeMapPtr(c.Defaults.Tag, o.Defaults.Tag)
+	}
+	disableInstallers(c.Install, o.Disable)
+	if len(o.Install) > 0 {
+		c.fileStarts = append(c.fileStarts, len(c.Install))
+	}
+	c.Install = append(c.Install, o.Install...)
+}
+
+// disableInstallers marks every installer (including nested group steps) whose name is in names
+// as disabled.
+func disableInstallers(installers []InstallerData, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	for idx := range installers {
+		inst := &installers[idx]
+		if inst.Name != nil && slices.Contains(names, *inst.Name) {
+			inst.Enabled = lo.ToPtr("false")
+		}
+		if inst.Steps != nil {
+			disableInstallers(*inst.Steps, names)
+		}
+	}
+}
+
+// mergeMapPtr returns a new map containing the entries of base overridden by the entries of
+// override. It returns base unchanged when override is nil.
+func mergeMapPtr[K comparable, V any](base *map[K]V, override *map[K]V) *map[K]V {
+	if override == nil {
+		return base
+	}
+	out := map[K]V{}
+	if base != nil {
+		maps.Copy(out, *base)
+	}
+	maps.Copy(out, *override)
+	return &out
+}
+
+// mergeEnvVars merges two env lists, with override winning. Variables keep their base position, and
+// new ones are added at the end.
+func mergeEnvVars(base *EnvVars, override *EnvVars) *EnvVars {
+	if override == nil {
+		return base
+	}
+	out := EnvVars{}
+	out.Merge(base)
+	out.Merge(override)
+	return &out
+}
+
+// concatSlicePtr concatenates two optional slices.
+func concatSlicePtr[T any](first *[]T, second *[]T) *[]T {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	out := slices.Concat(*first, *second)
+	return &out
+}
+
+// mergePlatformEnv merges two platform env maps per platform, with override winning.
+func mergePlatformEnv(
+	base *platform.PlatformMap[map[string]string],
+	override *platform.PlatformMap[map[string]string],
+) *platform.PlatformMap[map[string]string] {
+	if override == nil {
+		return base
+	}
+	if base == nil {
+		base = &platform.PlatformMap[map[string]string]{}
+	}
+	merged := &platform.PlatformMap[map[string]string]{
+		MacOS:   mergeMapPtr(base.MacOS, override.MacOS),
+		Linux:   mergeMapPtr(base.Linux, override.Linux),
+		Windows: mergeMapPtr(base.Windows, override.Windows),
+	}
+	for _, id := range platform.DistroIDs {
+		if value := mergeMapPtr(base.Distros[id], override.Distros[id]); value != nil {
+			if merged.Distros == nil {
+				merged.Distros = map[platform.DistroID]*map[string]string{}
+			}
+			merged.Distros[id] = value
+		}
+	}
+	for _, key := range platform.PlatformMapKeys() {
+		if value := mergeMapPtr(base.OSArch[key], override.OSArch[key]); value != nil {
+			if merged.OSArch == nil {
+				merged.OSArch = map[string]*map[string]string{}
+			}
+			merged.OSArch[key] = value
+		}
+	}
+	return merged
+}
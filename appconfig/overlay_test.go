@@ -0,0 +1,292 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeOverlayTestFile(t *testing.T, file string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+}
+
+func TestFindOverlayFiles(t *testing.T) {
+	machine.SetMachineID("abc123")
+	defer machine.ResetMachineID()
+
+	aliases := map[string]string{"work": "abc123", "home": "def456"}
+
+	t.Run("no overlays", func(t *testing.T) {
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "sofmani.yaml")
+		writeOverlayTestFile(t, configFile, "debug: true\n")
+		assert.Empty(t, FindOverlayFiles(configFile, aliases))
+	})
+
+	t.Run("finds overlays in merge order", func(t *testing.T) {
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "sofmani.yaml")
+		writeOverlayTestFile(t, configFile, "debug: true\n")
+		writeOverlayTestFile(t, filepath.Join(dir, "sofmani.work.yml"), "debug: false\n")
+		writeOverlayTestFile(t, filepath.Join(dir, "sofmani.d", "abc123.json"), "{}")
+		writeOverlayTestFile(t, filepath.Join(dir, "sofmani.d", "work.yaml"), "debug: false\n")
+		writeOverlayTestFile(t, filepath.Join(dir, "sofmani.home.yaml"), "debug: false\n")
+
+		assert.Equal(t, []string{
+			filepath.Join(dir, "sofmani.work.yml"),
+			filepath.Join(dir, "sofmani.d", "abc123.json"),
+			filepath.Join(dir, "sofmani.d", "work.yaml"),
+		}, FindOverlayFiles(configFile, aliases))
+	})
+
+	t.Run("machine id overlay without alias", func(t *testing.T) {
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "sofmani.yaml")
+		writeOverlayTestFile(t, configFile, "debug: true\n")
+		writeOverlayTestFile(t, filepath.Join(dir, "sofmani.d", "abc123.yaml"), "debug: false\n")
+
+		assert.Equal(t, []string{filepath.Join(dir, "sofmani.d", "abc123.yaml")}, FindOverlayFiles(configFile, nil))
+	})
+}
+
+func TestApplyOverlay(t *testing.T) {
+	t.Run("nil overlay is a no-op", func(t *testing.T) {
+		c := &AppConfig{Debug: lo.ToPtr(true)}
+		c.ApplyOverlay(nil)
+		assert.True(t, *c.Debug)
+	})
+
+	t.Run("overrides scalars and merges maps", func(t *testing.T) {
+		c := &AppConfig{
+			Debug:   lo.ToPtr(false),
+			Summary: lo.ToPtr(true),
+			Env:     lo.ToPtr(NewEnvVars(map[string]string{"A": "1", "B": "2"})),
+			RepoUpdate: &map[InstallerType]RepoUpdateMode{
+				InstallerTypeApt:  RepoUpdateOnce,
+				InstallerTypeBrew: RepoUpdateOnce,
+			},
+		}
+		c.ApplyOverlay(&AppConfigOverlay{
+			AppConfig: AppConfig{
+				Debug:      lo.ToPtr(true),
+				Env:        lo.ToPtr(NewEnvVars(map[string]string{"B": "3", "C": "4"})),
+				RepoUpdate: &map[InstallerType]RepoUpdateMode{InstallerTypeBrew: RepoUpdateNever},
+			},
+		})
+		assert.True(t, *c.Debug)
+		assert.True(t, *c.Summary)
+		assert.Equal(t, EnvVars{{Name: "A", Value: "1"}, {Name: "B", Value: "3"}, {Name: "C", Value: "4"}}, *c.Env)
+		assert.Equal(t, RepoUpdateOnce, c.GetRepoUpdateMode(InstallerTypeApt))
+		assert.Equal(t, RepoUpdateNever, c.GetRepoUpdateMode(InstallerTypeBrew))
+	})
+
+	t.Run("merges type defaults", func(t *testing.T) {
+		c := &AppConfig{
+			Defaults: &AppConfigDefaults{Type: &map[InstallerType]InstallerData{
+				InstallerTypeBrew: {Tags: lo.ToPtr("base")},
+			}},
+		}
+		c.ApplyOverlay(&AppConfigOverlay{
+			AppConfig: AppConfig{
+				Defaults: &AppConfigDefaults{Type: &map[InstallerType]InstallerData{
+					InstallerTypeNpm: {Tags: lo.ToPtr("node")},
+				}},
+			},
+		})
+		assert.Len(t, *c.Defaults.Type, 2)
+		assert.Equal(t, "node", *(*c.Defaults.Type)[InstallerTypeNpm].Tags)
+	})
+
+	t.Run("merges templates", func(t *testing.T) {
+		c := &AppConfig{Templates: &map[string]InstallerData{
+			"base": {Type: InstallerTypeBrew},
+			"cask": {Opts: &map[string]any{"cask": true}},
+		}}
+		c.ApplyOverlay(&AppConfigOverlay{
+			AppConfig: AppConfig{Templates: &map[string]InstallerData{"base": {Type: InstallerTypeApt}}},
+		})
+		assert.Len(t, *c.Templates, 2)
+		assert.Equal(t, InstallerTypeApt, (*c.Templates)["base"].Type)
+	})
+
+	t.Run("merges all and tag defaults", func(t *testing.T) {
+		c := &AppConfig{
+			Defaults: &AppConfigDefaults{
+				All: &InstallerData{PreInstall: lo.ToPtr("echo base"), PostInstall: lo.ToPtr("echo base")},
+				Tag: &map[string]InstallerData{"work": {Verbose: lo.ToPtr(true)}},
+			},
+		}
+		c.ApplyOverlay(&AppConfigOverlay{
+			AppConfig: AppConfig{
+				Defaults: &AppConfigDefaults{
+					All: &InstallerData{PostInstall: lo.ToPtr("echo overlay")},
+					Tag: &map[string]InstallerData{"home": {Verbose: lo.ToPtr(false)}},
+				},
+			},
+		})
+		assert.Equal(t, "echo base", *c.Defaults.All.PreInstall)
+		assert.Equal(t, "echo overlay", *c.Defaults.All.PostInstall)
+		assert.Len(t, *c.Defaults.Tag, 2)
+	})
+
+	t.Run("appends installers and disables by name", func(t *testing.T) {
+		c := &AppConfig{
+			Install: []InstallerData{
+				{Name: lo.ToPtr("keep"), Type: InstallerTypeBrew},
+				{Name: lo.ToPtr("drop"), Type: InstallerTypeBrew},
+				{Name: lo.ToPtr("group"), Type: InstallerTypeGroup, Steps: &[]InstallerData{
+					{Name: lo.ToPtr("nested-drop"), Type: InstallerTypeShell},
+				}},
+			},
+		}
+		c.ApplyOverlay(&AppConfigOverlay{
+			AppConfig: AppConfig{
+				Install: []InstallerData{{Name: lo.ToPtr("extra"), Type: InstallerTypeNpm}},
+			},
+			Disable: []string{"drop", "nested-drop"},
+		})
+		assert.Len(t, c.Install, 4)
+		assert.Nil(t, c.Install[0].Enabled)
+		assert.Equal(t, "false", *c.Install[1].Enabled)
+		assert.Equal(t, "false", *(*c.Install[2].Steps)[0].Enabled)
+		assert.Equal(t, "extra", *c.Install[3].Name)
+	})
+}
+
+func TestParseConfigWithOverlays(t *testing.T) {
+	machine.SetMachineID("abc123")
+	defer machine.ResetMachineID()
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	writeOverlayTestFile(t, configFile, `
+debug: false
+machine_aliases:
+  work: abc123
+env:
+  EDITOR: vim
+install:
+  - name: slack
+    type: brew
+  - name: git
+    type: brew
+`)
+	writeOverlayTestFile(t, filepath.Join(dir, "sofmani.work.yaml"), `
+debug: true
+env:
+  EDITOR: nvim
+disable:
+  - slack
+install:
+  - name: awscli
+    type: brew
+`)
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: configFile})
+	require.NoError(t, err)
+	assert.True(t, *config.Debug)
+	assert.Equal(t, "nvim", config.Env.Map()["EDITOR"])
+	require.Len(t, config.Install, 3)
+	assert.Equal(t, "false", *config.Install[0].Enabled)
+	assert.Equal(t, "awscli", *config.Install[2].Name)
+	assert.Equal(t, []string{filepath.Join(dir, "sofmani.work.yaml")}, config.Overlays)
+}
+
+func TestFindFragmentFiles(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	assert.Empty(t, FindFragmentFiles(configFile))
+
+	for _, name := range []string{"20-work.yaml", "10-base.toml", "README.md", "30-extra.json"} {
+		writeOverlayTestFile(t, filepath.Join(dir, "conf.d", name), "")
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conf.d", "nested.yaml"), 0755))
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "conf.d", "10-base.toml"),
+		filepath.Join(dir, "conf.d", "20-work.yaml"),
+		filepath.Join(dir, "conf.d", "30-extra.json"),
+	}, FindFragmentFiles(configFile))
+}
+
+func TestParseConfigWithFragments(t *testing.T) {
+	machine.SetMachineID("abc123")
+	defer machine.ResetMachineID()
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	writeOverlayTestFile(t, configFile, `
+env:
+  EDITOR: vim
+install:
+  - name: git
+    type: brew
+`)
+	writeOverlayTestFile(t, filepath.Join(dir, "conf.d", "20-second.yaml"), `
+env:
+  EDITOR: hx
+install:
+  - name: second
+    type: brew
+`)
+	writeOverlayTestFile(t, filepath.Join(dir, "conf.d", "10-first.yaml"), `
+env:
+  EDITOR: nano
+  PAGER: less
+install:
+  - name: first
+    type: brew
+`)
+	// Machine overlays are merged after fragments.
+	writeOverlayTestFile(t, filepath.Join(dir, "sofmani.d", "abc123.yaml"), `
+env:
+  EDITOR: nvim
+`)
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: configFile})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"EDITOR": "nvim", "PAGER": "less"}, config.Env.Map())
+	names := lo.Map(config.Install, func(i InstallerData, _ int) string { return *i.Name })
+	assert.Equal(t, []string{"git", "first", "second"}, names)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "conf.d", "10-first.yaml"),
+		filepath.Join(dir, "conf.d", "20-second.yaml"),
+	}, config.Fragments)
+	assert.Equal(t, []string{filepath.Join(dir, "sofmani.d", "abc123.yaml")}, config.Overlays)
+}
+
+func TestParseConfigFragmentIgnoresDisable(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	writeOverlayTestFile(t, configFile, `
+install:
+  - name: git
+    type: brew
+`)
+	fragment := filepath.Join(dir, "conf.d", "10-tools.yaml")
+	writeOverlayTestFile(t, fragment, `
+disable:
+  - git
+install:
+  - name: jq
+    type: brew
+`)
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: configFile})
+	require.NoError(t, err)
+	require.Len(t, config.Install, 2)
+	assert.Nil(t, config.Install[0].Enabled)
+
+	// disable is reported as an unknown key in fragments.
+	unknown, err := FindUnknownKeys(fragment, testOptsKeys)
+	require.NoError(t, err)
+	require.Len(t, unknown, 1)
+	assert.Equal(t, "disable", unknown[0].Key)
+}
@@ -0,0 +1,279 @@
+package appconfig
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/chenasraf/sofmani/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownKey describes a config key that sofmani does not recognize.
+type UnknownKey struct {
+	// File is the config file the key was found in.
+	File string
+	// Line is the 1-based line of the key in the file, or 0 if unknown.
+	Line int
+	// Column is the 1-based column of the key in the file, or 0 if unknown.
+	Column int
+	// Path is the location of the object containing the key (e.g. "install[2].opts").
+	// It is empty for top-level keys.
+	Path string
+	// Key is the unrecognized key.
+	Key string
+	// Suggestion is the closest known key, if one is similar enough.
+	Suggestion string
+}
+
+// String returns a human-readable description of the unknown key, including its position
+// and a "did you mean" suggestion when available.
+func (k UnknownKey) String() string {
+	pos := k.File
+	if k.Line > 0 {
+		pos = fmt.Sprintf("%s:%d:%d", k.File, k.Line, k.Column)
+	}
+	where := "at top level"
+	if k.Path != "" {
+		where = "in " + k.Path
+	}
+	msg := fmt.Sprintf("%s: unknown key %q %s", pos, k.Key, where)
+	if k.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", k.Suggestion)
+	}
+	return msg
+}
+
+// OptsKeysFunc returns the opts keys accepted by an installer type. The second return value
+// is false if the type is unknown, in which case its opts are not checked.
+type OptsKeysFunc func(t InstallerType) ([]string, bool)
+
+// FindUnknownKeys returns all unrecognized keys in the given config file, at the top level,
+// in each installer entry (including group steps and type defaults), and in each installer's
+// opts. optsKeys provides the accepted opts keys per installer type.
+func FindUnknownKeys(file string, optsKeys OptsKeysFunc) ([]UnknownKey, error) {
+	return findUnknownKeys(file, reflect.TypeFor[AppConfig](), optsKeys)
+}
+
+// FindUnknownOverlayKeys is like FindUnknownKeys, but for machine overlay files, which also
+// accept the overlay-only keys (e.g. disable). conf.d fragments accept the same keys as the main
+// config, and are checked with FindUnknownKeys.
+func FindUnknownOverlayKeys(file string, optsKeys OptsKeysFunc) ([]UnknownKey, error) {
+	return findUnknownKeys(file, reflect.TypeFor[AppConfigOverlay](), optsKeys)
+}
+
+func findUnknownKeys(file string, rootType reflect.Type, optsKeys OptsKeysFunc) ([]UnknownKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	w := &unknownKeyWalker{file: file, optsKeys: optsKeys}
+	if ConfigFormatFromPath(file) == ConfigFormatTOML {
+		// Positions from the converted document are meaningless, so they are looked up
+		// in the original TOML source instead.
+		w.tomlSource = string(content)
+		content, err = tomlToYAML(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	w.checkConfig(doc.Content[0], rootType)
+	slices.SortStableFunc(w.found, func(a, b UnknownKey) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+	return w.found, nil
+}
+
+// unknownKeyWalker walks a parsed config document and collects unknown keys.
+type unknownKeyWalker struct {
+	file       string
+	optsKeys   OptsKeysFunc
+	tomlSource string
+	found      []UnknownKey
+	// templates is the templates mapping node, used to find the type of installers that extend
+	// templates.
+	templates *yaml.Node
+}
+
+var (
+	installerDataKeys = yamlKeys(reflect.TypeFor[InstallerData]())
+	defaultsKeys      = yamlKeys(reflect.TypeFor[AppConfigDefaults]())
+	platformsKeys     = yamlKeys(reflect.TypeFor[platform.Platforms]())
+	machinesKeys      = yamlKeys(reflect.TypeFor[machine.Machines]())
+	archsKeys         = yamlKeys(reflect.TypeFor[platform.Archs]())
+	traitsKeys        = yamlKeys(reflect.TypeFor[platform.Traits]())
+	platformMapKeys   = platform.PlatformMapKeys()
+)
+
+func (w *unknownKeyWalker) checkConfig(node *yaml.Node, rootType reflect.Type) {
+	w.checkMapping(node, "", yamlKeys(rootType))
+	w.checkMapping(mappingValue(node, "platform_env"), "platform_env", platformMapKeys)
+	if templates := mappingValue(node, "templates"); templates != nil && templates.Kind == yaml.MappingNode {
+		w.templates = templates
+		for idx := 0; idx+1 < len(templates.Content); idx += 2 {
+			w.checkInstaller(templates.Content[idx+1], "templates."+templates.Content[idx].Value, "")
+		}
+	}
+	w.checkDefaults(mappingValue(node, "defaults"), "defaults")
+	w.checkInstallers(mappingValue(node, "install"), "install")
+}
+
+// checkDefaults checks a defaults node, either the top-level one or a category's.
+func (w *unknownKeyWalker) checkDefaults(node *yaml.Node, path string) {
+	if node == nil {
+		return
+	}
+	w.checkMapping(node, path, defaultsKeys)
+	if types := mappingValue(node, "type"); types != nil && types.Kind == yaml.MappingNode {
+		for idx := 0; idx+1 < len(types.Content); idx += 2 {
+			t := types.Content[idx].Value
+			w.checkInstaller(types.Content[idx+1], path+".type."+t, InstallerType(t))
+		}
+	}
+	w.checkInstaller(mappingValue(node, "all"), path+".all", "")
+	if tags := mappingValue(node, "tag"); tags != nil && tags.Kind == yaml.MappingNode {
+		for idx := 0; idx+1 < len(tags.Content); idx += 2 {
+			w.checkInstaller(tags.Content[idx+1], path+".tag."+tags.Content[idx].Value, "")
+		}
+	}
+}
+
+func (w *unknownKeyWalker) checkInstallers(node *yaml.Node, path string) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+	for idx, item := range node.Content {
+		w.checkInstaller(item, fmt.Sprintf("%s[%d]", path, idx), "")
+	}
+}
+
+func (w *unknownKeyWalker) checkInstaller(node *yaml.Node, path string, t InstallerType) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	w.checkMapping(node, path, installerDataKeys)
+	w.checkMapping(mappingValue(node, "platforms"), path+".platforms", platformsKeys)
+	w.checkMapping(mappingValue(node, "archs"), path+".archs", archsKeys)
+	w.checkMapping(mappingValue(node, "traits"), path+".traits", traitsKeys)
+	w.checkMapping(mappingValue(node, "machines"), path+".machines", machinesKeys)
+	w.checkMapping(mappingValue(node, "platform_env"), path+".platform_env", platformMapKeys)
+	w.checkMapping(mappingValue(node, "env_shell"), path+".env_shell", platformMapKeys)
+	if extendsType := w.extendsType(node, nil); extendsType != "" {
+		t = extendsType
+	}
+	if typeNode := mappingValue(node, "type"); typeNode != nil {
+		t = InstallerType(typeNode.Value)
+	}
+	if keys, ok := w.optsKeys(t); ok {
+		w.checkMapping(mappingValue(node, "opts"), path+".opts", keys)
+	}
+	w.checkInstallers(mappingValue(node, "steps"), path+".steps")
+	w.checkDefaults(mappingValue(node, "defaults"), path+".defaults")
+}
+
+// extendsType returns the type an installer node gets from the templates it extends, or an empty
+// string if none of them sets one. seen guards against templates that extend themselves.
+func (w *unknownKeyWalker) extendsType(node *yaml.Node, seen []string) InstallerType {
+	extends := mappingValue(node, "extends")
+	if extends == nil || extends.Kind != yaml.SequenceNode {
+		return ""
+	}
+	// Later templates win, so the last one with a type decides.
+	for _, nameNode := range slices.Backward(extends.Content) {
+		name := nameNode.Value
+		template := mappingValue(w.templates, name)
+		if template == nil || slices.Contains(seen, name) {
+			continue
+		}
+		if typeNode := mappingValue(template, "type"); typeNode != nil {
+			return InstallerType(typeNode.Value)
+		}
+		if t := w.extendsType(template, append(seen, name)); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// checkMapping reports every key of a mapping node that is not in known.
+func (w *unknownKeyWalker) checkMapping(node *yaml.Node, path string, known []string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key := node.Content[idx]
+		// YAML merge keys ("<<: *anchor") are resolved by the parser and are always valid.
+		if key.Tag == "!!merge" || slices.Contains(known, key.Value) {
+			continue
+		}
+		unknown := UnknownKey{File: w.file, Line: key.Line, Column: key.Column, Path: path, Key: key.Value}
+		if w.tomlSource != "" {
+			unknown.Line, unknown.Column = findTOMLKey(w.tomlSource, key.Value)
+		}
+		if suggestion, ok := utils.ClosestMatch(key.Value, known); ok {
+			unknown.Suggestion = suggestion
+		}
+		w.found = append(w.found, unknown)
+	}
+}
+
+// mappingValue returns the value node for the given key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+// yamlKeys returns the yaml keys of a struct type, including the keys of inlined structs.
+func yamlKeys(t reflect.Type) []string {
+	keys := []string{}
+	for idx := range t.NumField() {
+		field := t.Field(idx)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if opts == "inline" {
+			keys = append(keys, yamlKeys(field.Type)...)
+			continue
+		}
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// findTOMLKey returns the 1-based line and column of the first occurrence of key in TOML
+// source, either as a key/value assignment or as a table header segment. It returns 0, 0
+// if the key can't be found.
+func findTOMLKey(source string, key string) (int, int) {
+	quoted := regexp.QuoteMeta(key)
+	pattern := regexp.MustCompile(`^(\s*(?:\[\[?[^\]]*\.)?|\s*\[\[?)["']?(` + quoted + `)["']?\s*(=|\.|\]|$)`)
+	inline := regexp.MustCompile(`[{,]\s*["']?(` + quoted + `)["']?\s*=`)
+	for idx, line := range strings.Split(source, "\n") {
+		if loc := pattern.FindStringSubmatchIndex(line); loc != nil {
+			return idx + 1, loc[4] + 1
+		}
+		if loc := inline.FindStringSubmatchIndex(line); loc != nil {
+			return idx + 1, loc[2] + 1
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,268 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptsKeys(t InstallerType) ([]string, bool) {
+	switch t {
+	case InstallerTypeBrew:
+		return []string{"tap", "cask"}, true
+	case InstallerTypeGitHubRelease:
+		return []string{"repository", "download_filename"}, true
+	case InstallerTypeGroup:
+		return []string{}, true
+	}
+	return nil, false
+}
+
+func writeStrictTestFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestFindUnknownKeys(t *testing.T) {
+	t.Run("valid config has no unknown keys", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+debug: true
+strict: true
+defaults:
+  type:
+    brew:
+      opts:
+        cask: true
+install:
+  - name: jq
+    type: brew
+    platforms:
+      only: [macos]
+    opts:
+      tap: foo/bar
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		assert.Empty(t, unknown)
+	})
+
+	t.Run("accepts distro and arch keys", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+install:
+  - name: build-deps
+    type: shell
+    platforms:
+      only: [linux/debian]
+      distro: [ubuntu>=22.04]
+    archs:
+      only: [arm64]
+    env_shell:
+      linux/arm64: bash
+    platform_env:
+      linux:
+        CC: gcc
+      fedora:
+        CC: clang
+      debain:
+        CC: gcc
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 1)
+		assert.Equal(t, "install[0].platform_env", unknown[0].Path)
+		assert.Equal(t, "debain", unknown[0].Key)
+	})
+
+	t.Run("reports unknown keys with position and suggestion", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `debug: true
+chek_updates: true
+install:
+  - name: lazygit
+    type: github-release
+    post_instal: echo done
+    opts:
+      repository: jesseduffield/lazygit
+      download_file_name: lazygit.tar.gz
+  - name: tools
+    type: group
+    steps:
+      - name: jq
+        type: brew
+        platforms:
+          onyl: [macos]
+        opts:
+          tapp: foo/bar
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 5)
+
+		assert.Equal(t, UnknownKey{File: file, Line: 2, Column: 1, Key: "chek_updates", Suggestion: "check_updates"}, unknown[0])
+		assert.Equal(t, UnknownKey{File: file, Line: 6, Column: 5, Path: "install[0]", Key: "post_instal", Suggestion: "post_install"}, unknown[1])
+		assert.Equal(t, UnknownKey{File: file, Line: 9, Column: 7, Path: "install[0].opts", Key: "download_file_name", Suggestion: "download_filename"}, unknown[2])
+		assert.Equal(t, "install[1].steps[0].platforms", unknown[3].Path)
+		assert.Equal(t, "only", unknown[3].Suggestion)
+		assert.Equal(t, "install[1].steps[0].opts", unknown[4].Path)
+		assert.Equal(t, "tap", unknown[4].Suggestion)
+	})
+
+	t.Run("skips opts of unknown types", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+install:
+  - name: x
+    type: something
+    opts:
+      whatever: true
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		assert.Empty(t, unknown)
+	})
+
+	t.Run("checks type defaults opts", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+defaults:
+  type:
+    brew:
+      opts:
+        caks: true
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 1)
+		assert.Equal(t, "defaults.type.brew.opts", unknown[0].Path)
+		assert.Equal(t, "cask", unknown[0].Suggestion)
+	})
+
+	t.Run("checks all and tag defaults", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+defaults:
+  all:
+    pre_instal: echo
+  tag:
+    work:
+      platforms:
+        onyl: [macos]
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 2)
+		assert.Equal(t, "defaults.all", unknown[0].Path)
+		assert.Equal(t, "pre_install", unknown[0].Suggestion)
+		assert.Equal(t, "defaults.tag.work.platforms", unknown[1].Path)
+		assert.Equal(t, "only", unknown[1].Suggestion)
+	})
+
+	t.Run("checks category defaults", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+install:
+  - category: Tools
+    defaults:
+      al: {}
+      type:
+        brew:
+          opts:
+            caks: true
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 2)
+		assert.Equal(t, "install[0].defaults", unknown[0].Path)
+		assert.Equal(t, "all", unknown[0].Suggestion)
+		assert.Equal(t, "install[0].defaults.type.brew.opts", unknown[1].Path)
+		assert.Equal(t, "cask", unknown[1].Suggestion)
+	})
+
+	t.Run("checks templates and extended opts", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.yaml", `
+templates:
+  base:
+    type: brew
+    pre_instal: echo
+  cask:
+    extends: [base]
+install:
+  - name: app
+    extends: [cask]
+    opts:
+      caks: true
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 2)
+		assert.Equal(t, "templates.base", unknown[0].Path)
+		assert.Equal(t, "install[0].opts", unknown[1].Path)
+		assert.Equal(t, "cask", unknown[1].Suggestion)
+	})
+
+	t.Run("json config", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.json", `{
+  "debug": true,
+  "sumary": false
+}`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 1)
+		assert.Equal(t, 3, unknown[0].Line)
+		assert.Equal(t, "summary", unknown[0].Suggestion)
+	})
+
+	t.Run("toml config", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.toml", `debug = true
+
+[[install]]
+name = "jq"
+type = "brew"
+post_instal = "echo done"
+
+[install.opts]
+tapp = "foo/bar"
+`)
+		unknown, err := FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		require.Len(t, unknown, 2)
+		assert.Equal(t, UnknownKey{File: file, Line: 6, Column: 1, Path: "install[0]", Key: "post_instal", Suggestion: "post_install"}, unknown[0])
+		assert.Equal(t, 9, unknown[1].Line)
+	})
+
+	t.Run("overlay accepts disable", func(t *testing.T) {
+		file := writeStrictTestFile(t, "sofmani.work.yaml", "disable: [jq]\n")
+		unknown, err := FindUnknownOverlayKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		assert.Empty(t, unknown)
+
+		unknown, err = FindUnknownKeys(file, testOptsKeys)
+		require.NoError(t, err)
+		assert.Len(t, unknown, 1)
+	})
+}
+
+func TestUnknownKeyString(t *testing.T) {
+	assert.Equal(t,
+		`sofmani.yaml:6:5: unknown key "post_instal" in install[0] (did you mean "post_install"?)`,
+		UnknownKey{File: "sofmani.yaml", Line: 6, Column: 5, Path: "install[0]", Key: "post_instal", Suggestion: "post_install"}.String(),
+	)
+	assert.Equal(t,
+		`sofmani.toml: unknown key "foo" at top level`,
+		UnknownKey{File: "sofmani.toml", Key: "foo"}.String(),
+	)
+}
+
+func TestFindUnknownKeysIgnoresMergeKeys(t *testing.T) {
+	file := writeStrictTestFile(t, "sofmani.yaml", `
+install:
+  - &base
+    name: jq
+    type: brew
+  - <<: *base
+    name: yq
+`)
+	unknown, err := FindUnknownKeys(file, testOptsKeys)
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}
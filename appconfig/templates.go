@@ -0,0 +1,123 @@
+package appconfig
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/chenasraf/sofmani/utils"
+	"github.com/samber/lo"
+)
+
+// ResolveTemplates builds every installer that extends templates from them, including group steps
+// and defaults. The templates are merged in order on top of each other, and the installer's own
+// fields on top of them, so opts, env and platform_env are merged key by key. Templates can extend
+// other templates.
+func (c *AppConfig) ResolveTemplates() error {
+	r := &templateResolver{
+		templates: lo.FromPtr(c.Templates),
+		resolved:  map[string]InstallerData{},
+		resolving: map[string]bool{},
+	}
+	if err := r.resolveList(c.Install, "install"); err != nil {
+		return err
+	}
+	if c.Defaults == nil {
+		return nil
+	}
+	if c.Defaults.All != nil {
+		all, err := r.resolve(*c.Defaults.All, "defaults.all")
+		if err != nil {
+			return err
+		}
+		c.Defaults.All = &all
+	}
+	if err := resolveMap(r, c.Defaults.Type, "defaults.type"); err != nil {
+		return err
+	}
+	return resolveMap(r, c.Defaults.Tag, "defaults.tag")
+}
+
+// templateResolver resolves installers against a set of templates, caching resolved templates.
+type templateResolver struct {
+	templates map[string]InstallerData
+	resolved  map[string]InstallerData
+	resolving map[string]bool
+}
+
+// resolveList resolves a list of installers in place.
+func (r *templateResolver) resolveList(installers []InstallerData, path string) error {
+	for idx := range installers {
+		resolved, err := r.resolve(installers[idx], fmt.Sprintf("%s[%d]", path, idx))
+		if err != nil {
+			return err
+		}
+		installers[idx] = resolved
+	}
+	return nil
+}
+
+// resolveMap resolves a map of installers in place.
+func resolveMap[K ~string](r *templateResolver, installers *map[K]InstallerData, path string) error {
+	if installers == nil {
+		return nil
+	}
+	for key, data := range *installers {
+		resolved, err := r.resolve(data, fmt.Sprintf("%s.%s", path, key))
+		if err != nil {
+			return err
+		}
+		(*installers)[key] = resolved
+	}
+	return nil
+}
+
+// resolve returns data built from the templates it extends, with its group steps resolved too.
+func (r *templateResolver) resolve(data InstallerData, path string) (InstallerData, error) {
+	if data.Extends != nil {
+		base := InstallerData{}
+		for _, name := range *data.Extends {
+			template, err := r.template(name)
+			if err != nil {
+				return data, fmt.Errorf("%s: %w", path, err)
+			}
+			base = MergeInstallerData(base, template)
+		}
+		data = MergeInstallerData(base, data)
+		data.Extends = nil
+	}
+	if data.Steps != nil {
+		// Steps may come from a template, so they are copied before being resolved in place.
+		steps := slices.Clone(*data.Steps)
+		if err := r.resolveList(steps, path+".steps"); err != nil {
+			return data, err
+		}
+		data.Steps = &steps
+	}
+	return data, nil
+}
+
+// template returns the resolved template with the given name.
+func (r *templateResolver) template(name string) (InstallerData, error) {
+	if resolved, ok := r.resolved[name]; ok {
+		return resolved, nil
+	}
+	template, ok := r.templates[name]
+	if !ok {
+		if suggestion, ok := utils.ClosestMatch(name, slices.Sorted(maps.Keys(r.templates))); ok {
+			return template, fmt.Errorf("unknown template %q (did you mean %q?)", name, suggestion)
+		}
+		return template, fmt.Errorf("unknown template %q", name)
+	}
+	if r.resolving[name] {
+		return template, fmt.Errorf("template %q extends itself", name)
+	}
+	r.resolving[name] = true
+	defer delete(r.resolving, name)
+	resolved, err := r.resolve(template, "templates."+name)
+	if err != nil {
+		return resolved, err
+	}
+	r.resolved[name] = resolved
+	return resolved, nil
+}
@@ -0,0 +1,136 @@
+package appconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTemplates(t *testing.T) {
+	t.Run("merges templates in order", func(t *testing.T) {
+		c := &AppConfig{
+			Templates: &map[string]InstallerData{
+				"release": {
+					Type: InstallerTypeGitHubRelease,
+					Opts: &map[string]any{"destination": "~/.local/bin", "strategy": "tar"},
+					Env:  &EnvVars{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}},
+				},
+				"zip": {
+					Opts:        &map[string]any{"strategy": "zip"},
+					PlatformEnv: &platform.PlatformMap[map[string]string]{Linux: &map[string]string{"C": "1"}},
+				},
+			},
+			Install: []InstallerData{
+				{
+					Name:        lo.ToPtr("tool"),
+					Extends:     &[]string{"release", "zip"},
+					Opts:        &map[string]any{"repository": "owner/tool"},
+					Env:         &EnvVars{{Name: "B", Value: "2"}},
+					PlatformEnv: &platform.PlatformMap[map[string]string]{Linux: &map[string]string{"D": "1"}},
+				},
+			},
+		}
+		require.NoError(t, c.ResolveTemplates())
+		tool := c.Install[0]
+		assert.Equal(t, "tool", *tool.Name)
+		assert.Equal(t, InstallerTypeGitHubRelease, tool.Type)
+		assert.Nil(t, tool.Extends)
+		assert.Equal(t, map[string]any{
+			"destination": "~/.local/bin",
+			"strategy":    "zip",
+			"repository":  "owner/tool",
+		}, *tool.Opts)
+		assert.Equal(t, map[string]string{"A": "1", "B": "2"}, tool.Env.Map())
+		assert.Equal(t, map[string]string{"C": "1", "D": "1"}, *tool.PlatformEnv.Linux)
+		// The templates themselves are left untouched.
+		assert.Equal(t, map[string]any{"strategy": "zip"}, *(*c.Templates)["zip"].Opts)
+	})
+
+	t.Run("templates extend templates", func(t *testing.T) {
+		c := &AppConfig{
+			Templates: &map[string]InstallerData{
+				"base":  {Type: InstallerTypeBrew, PostInstall: lo.ToPtr("echo base")},
+				"cask":  {Extends: &[]string{"base"}, Opts: &map[string]any{"cask": true}},
+				"other": {Extends: &[]string{"cask"}},
+			},
+			Install: []InstallerData{{Name: lo.ToPtr("app"), Extends: &[]string{"other"}}},
+		}
+		require.NoError(t, c.ResolveTemplates())
+		assert.Equal(t, InstallerTypeBrew, c.Install[0].Type)
+		assert.Equal(t, "echo base", *c.Install[0].PostInstall)
+		assert.Equal(t, true, (*c.Install[0].Opts)["cask"])
+	})
+
+	t.Run("resolves group steps and defaults", func(t *testing.T) {
+		c := &AppConfig{
+			Templates: &map[string]InstallerData{"brew": {Type: InstallerTypeBrew}},
+			Defaults: &AppConfigDefaults{
+				Tag: &map[string]InstallerData{"work": {Extends: &[]string{"brew"}}},
+			},
+			Install: []InstallerData{{
+				Name:  lo.ToPtr("group"),
+				Type:  InstallerTypeGroup,
+				Steps: &[]InstallerData{{Name: lo.ToPtr("step"), Extends: &[]string{"brew"}}},
+			}},
+		}
+		require.NoError(t, c.ResolveTemplates())
+		assert.Equal(t, InstallerTypeBrew, (*c.Install[0].Steps)[0].Type)
+		assert.Equal(t, InstallerTypeBrew, (*c.Defaults.Tag)["work"].Type)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		c := &AppConfig{
+			Templates: &map[string]InstallerData{"release": {}},
+			Install:   []InstallerData{{Name: lo.ToPtr("tool"), Extends: &[]string{"relase"}}},
+		}
+		assert.EqualError(t, c.ResolveTemplates(), `install[0]: unknown template "relase" (did you mean "release"?)`)
+	})
+
+	t.Run("template cycle", func(t *testing.T) {
+		c := &AppConfig{
+			Templates: &map[string]InstallerData{
+				"a": {Extends: &[]string{"b"}},
+				"b": {Extends: &[]string{"a"}},
+			},
+			Install: []InstallerData{{Name: lo.ToPtr("tool"), Extends: &[]string{"a"}}},
+		}
+		assert.ErrorContains(t, c.ResolveTemplates(), `template "a" extends itself`)
+	})
+}
+
+func TestParseConfigWithTemplates(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "sofmani.yaml")
+	writeOverlayTestFile(t, configFile, `
+templates:
+  release:
+    type: github-release
+    opts:
+      destination: ~/.local/bin
+      strategy: tar
+install:
+  - name: tool
+    extends: [release]
+    opts:
+      repository: owner/tool
+`)
+	// Templates are shared with fragments, including JSON ones.
+	writeOverlayTestFile(t, filepath.Join(dir, "conf.d", "10-more.json"), `{
+  "install": [
+    {"name": "other", "extends": ["release"], "opts": {"repository": "owner/other", "strategy": "zip"}}
+  ]
+}`)
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: configFile})
+	require.NoError(t, err)
+	require.Len(t, config.Install, 2)
+	assert.Equal(t, InstallerTypeGitHubRelease, config.Install[0].Type)
+	assert.Equal(t, "tar", (*config.Install[0].Opts)["strategy"])
+	assert.Equal(t, InstallerTypeGitHubRelease, config.Install[1].Type)
+	assert.Equal(t, "zip", (*config.Install[1].Opts)["strategy"])
+	assert.Equal(t, "~/.local/bin", (*config.Install[1].Opts)["destination"])
+}
@@ -0,0 +1,99 @@
+package appconfig
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// tomlToYAML converts TOML config content into equivalent YAML content.
+// TOML configs are decoded through the same YAML unmarshaling path as YAML and JSON configs,
+// so custom unmarshalers (e.g. SkipSummary) and loosely typed fields (e.g. opts that accept
+// either a single value or a platform map) behave identically regardless of the file format.
+// Keys keep the order they are declared in, which matters for ordered fields such as env.
+func tomlToYAML(content []byte) ([]byte, error) {
+	data := map[string]any{}
+	meta, err := toml.Decode(string(content), &data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+	order := map[string]int{}
+	for idx, key := range meta.Keys() {
+		path := strings.Join(key, ".")
+		if _, ok := order[path]; !ok {
+			order[path] = idx
+		}
+	}
+	node, err := tomlValueNode(data, "", order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert TOML: %w", err)
+	}
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert TOML: %w", err)
+	}
+	return out, nil
+}
+
+// tomlValueNode converts a decoded TOML value at the given dotted key path into a YAML node.
+// Table keys are sorted by their position in the TOML source.
+func tomlValueNode(value any, path string, order map[string]int) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		position := func(k string) int {
+			if idx, ok := order[strings.TrimPrefix(path+"."+k, ".")]; ok {
+				return idx
+			}
+			return math.MaxInt
+		}
+		sort.SliceStable(keys, func(a, b int) bool {
+			pa, pb := position(keys[a]), position(keys[b])
+			if pa != pb {
+				return pa < pb
+			}
+			return keys[a] < keys[b]
+		})
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range keys {
+			child, err := tomlValueNode(v[k], strings.TrimPrefix(path+"."+k, "."), order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, child)
+		}
+		return node, nil
+	case []map[string]any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			child, err := tomlValueNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			child, err := tomlValueNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,118 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTomlConfig = `
+debug = true
+check_updates = false
+
+[repo_update]
+brew = "never"
+
+[env]
+EDITOR = "nvim"
+
+[platform_env.macos]
+HOMEBREW_NO_ANALYTICS = "1"
+
+[defaults.type.brew.platforms]
+only = ["macos"]
+
+[[install]]
+name = "jq"
+type = "brew"
+enabled = true
+skip_summary = { install = true }
+
+[[install]]
+name = "lazygit"
+type = "github-release"
+skip_summary = true
+
+[install.opts]
+repository = "jesseduffield/lazygit"
+strip_components = 1
+destination = { macos = "/usr/local/bin", linux = "~/.local/bin" }
+
+[[install.opts.bin_links]]
+source = "lazygit"
+target = "lg"
+`
+
+func TestParseTomlConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "sofmani.toml")
+	require.NoError(t, os.WriteFile(file, []byte(testTomlConfig), 0644))
+
+	config, err := ParseConfig(&AppCliConfig{ConfigFile: file})
+	require.NoError(t, err)
+
+	assert.True(t, *config.Debug)
+	assert.False(t, *config.CheckUpdates)
+	assert.Equal(t, RepoUpdateNever, config.GetRepoUpdateMode(InstallerTypeBrew))
+	assert.Equal(t, "nvim", config.Env.Map()["EDITOR"])
+	assert.Equal(t, "1", (*config.PlatformEnv.MacOS)["HOMEBREW_NO_ANALYTICS"])
+	assert.Equal(t, []platform.Platform{platform.PlatformMacos}, *(*config.Defaults.Type)[InstallerTypeBrew].Platforms.Only)
+
+	require.Len(t, config.Install, 2)
+	jq := config.Install[0]
+	assert.Equal(t, "true", *jq.Enabled)
+	assert.Equal(t, SkipSummary{Install: true, Update: false}, *jq.SkipSummary)
+
+	lazygit := config.Install[1]
+	assert.Equal(t, SkipSummary{Install: true, Update: true}, *lazygit.SkipSummary)
+	opts := *lazygit.Opts
+	assert.Equal(t, "jesseduffield/lazygit", opts["repository"])
+	assert.Equal(t, 1, opts["strip_components"])
+	dest, err := platform.NewPlatformMap[string](opts["destination"])
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/local/bin", *dest.MacOS)
+	assert.Equal(t, "~/.local/bin", *dest.Linux)
+	links, ok := opts["bin_links"].([]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"source": "lazygit", "target": "lg"}, links[0])
+}
+
+func TestParseConfigFromContentToml(t *testing.T) {
+	config, err := ParseConfigFromContent([]byte(testTomlConfig), ConfigFormatTOML)
+	require.NoError(t, err)
+	assert.Len(t, config.Install, 2)
+
+	_, err = ParseConfigFromContent([]byte("debug = "), ConfigFormatTOML)
+	assert.Error(t, err)
+}
+
+func TestConfigFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected ConfigFormat
+	}{
+		{"sofmani.yaml", ConfigFormatYAML},
+		{"sofmani.yml", ConfigFormatYAML},
+		{"sofmani.json", ConfigFormatJSON},
+		{"sofmani.toml", ConfigFormatTOML},
+		{"SOFMANI.TOML", ConfigFormatTOML},
+		{"https://example.com/manifest.toml?ref=main", ConfigFormatTOML},
+		{"", ConfigFormatYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConfigFormatFromPath(tt.path))
+		})
+	}
+}
+
+func TestFindConfigFileToml(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "sofmani.toml")
+	require.NoError(t, os.WriteFile(file, []byte("debug = true\n"), 0644))
+	assert.Equal(t, file, tryConfigDir(dir))
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/scan"
+	"github.com/spf13/cobra"
+)
+
+// starterConfig is written by `sofmani init` when --scan is not given.
+const starterConfig = `# yaml-language-server: $schema=https://raw.githubusercontent.com/chenasraf/sofmani/master/schema/sofmani.schema.json
+debug: false
+check_updates: true
+summary: true
+
+install:
+  - category: Tools
+  - name: jq
+    type: brew
+`
+
+var (
+	initScan   bool
+	initOutput string
+	initForce  bool
+
+	// initCmd writes a new config file, optionally populated from the installed packages.
+	initCmd = &cobra.Command{
+		Use:   "init [flags]",
+		Short: "Create a new config file",
+		Long: `Create a new sofmani config file.
+
+With --scan, the installed package managers (brew, npm, pipx, cargo, apt, pacman and go) are
+queried and every discovered package is added as an install entry, grouped by category.`,
+		Args: cobra.NoArgs,
+		// init does not read an existing config file, so the root command's config lookup is skipped.
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		SilenceUsage:     true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if initOutput != "" && !initForce {
+				if _, err := os.Stat(initOutput); err == nil {
+					return fmt.Errorf("%s already exists, use --force to overwrite it", initOutput)
+				}
+			}
+			content := []byte(starterConfig)
+			if initScan {
+				var err error
+				content, err = scanInstalled()
+				if err != nil {
+					return err
+				}
+			}
+			if initOutput == "" {
+				_, err := os.Stdout.Write(content)
+				return err
+			}
+			if err := os.WriteFile(initOutput, content, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %s\n", initOutput)
+			return nil
+		},
+	}
+)
+
+// scanInstalled scans the installed package managers and renders the results as a config. Progress
+// and failures are reported on stderr so the config can be piped from stdout.
+func scanInstalled() ([]byte, error) {
+	// Commands are logged at debug level, which only goes to the log file.
+	logger.InitLogger(false)
+	results := scan.NewScanner().Scan(scan.DefaultSources())
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %v\n", result.Err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Found %d packages in %s\n", len(result.Packages), result.Source.Category)
+	}
+	return scan.Render(results)
+}
+
+func init() {
+	initCmd.Flags().SortFlags = false
+	initCmd.Flags().BoolVar(&initScan, "scan", false, "Populate the config from the packages installed on this machine")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "", "Write the config to the given file instead of stdout")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite the output file if it already exists")
+	rootCmd.AddCommand(initCmd)
+}
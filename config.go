@@ -2,6 +2,9 @@ package main
 
 import (
 	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/installer"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
 )
 
 // loadConfigFromCli loads the application configuration from pre-parsed CLI config.
@@ -12,3 +15,44 @@ func loadConfigFromCli(overrides *appconfig.AppCliConfig) (*appconfig.AppConfig,
 	}
 	return cfg, nil
 }
+
+// checkUnknownConfigKeys reports unknown keys in the config file, its conf.d fragments and its
+// machine overlays.
+// Unknown keys, and files that can't be checked, are logged as warnings, or as errors when strict
+// mode is enabled. It returns false if strict mode is enabled and unknown keys were found or a file
+// couldn't be checked.
+func checkUnknownConfigKeys(configFile string, cfg *appconfig.AppConfig) bool {
+	strict := lo.FromPtrOr(cfg.Strict, false)
+	report := logger.Warn
+	if strict {
+		report = logger.Error
+	}
+
+	ok := true
+	unknown, err := appconfig.FindUnknownKeys(configFile, installer.GetOptsKeys)
+	if err != nil {
+		report("Failed to check config %s for unknown keys: %v", configFile, err)
+		ok = false
+	}
+	for _, fragment := range cfg.Fragments {
+		fragmentUnknown, err := appconfig.FindUnknownKeys(fragment, installer.GetOptsKeys)
+		if err != nil {
+			report("Failed to check %s for unknown keys: %v", fragment, err)
+			ok = false
+		}
+		unknown = append(unknown, fragmentUnknown...)
+	}
+	for _, overlay := range cfg.Overlays {
+		overlayUnknown, err := appconfig.FindUnknownOverlayKeys(overlay, installer.GetOptsKeys)
+		if err != nil {
+			report("Failed to check %s for unknown keys: %v", overlay, err)
+			ok = false
+		}
+		unknown = append(unknown, overlayUnknown...)
+	}
+
+	for _, k := range unknown {
+		report("%s", k.String())
+	}
+	return !strict || (ok && len(unknown) == 0)
+}
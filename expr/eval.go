@@ -0,0 +1,202 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/chenasraf/sofmani/platform"
+)
+
+// Facts provides the values an expression is evaluated against.
+type Facts struct {
+	// Vars holds the value of each identifier, e.g. "os" or "distro".
+	Vars map[string]string
+	// LookupEnv returns the value of an environment variable, for env().
+	LookupEnv func(name string) (string, bool)
+	// HasCommand returns true if a command is available in PATH, for has().
+	HasCommand func(name string) bool
+	// FileExists returns true if a file or directory exists, for exists().
+	FileExists func(path string) bool
+	// Version returns the installed version of a tool, or an empty string if it is not installed,
+	// for version().
+	Version func(tool string) string
+}
+
+// Eval parses and evaluates an expression against facts.
+func Eval(src string, facts Facts) (bool, error) {
+	e, err := Parse(src)
+	if err != nil {
+		return false, err
+	}
+	return e.Eval(facts)
+}
+
+// Eval evaluates the expression against facts. The result is true if the expression evaluates to
+// true, a non-empty string or a non-empty list.
+func (e *Expr) Eval(facts Facts) (bool, error) {
+	value, err := facts.eval(e.root)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// eval evaluates a node. Values are strings, booleans or lists of values.
+func (f Facts) eval(n node) (any, error) {
+	switch n := n.(type) {
+	case literalNode:
+		return n.value, nil
+	case identNode:
+		return f.Vars[n.name], nil
+	case listNode:
+		items := make([]any, 0, len(n.items))
+		for _, item := range n.items {
+			value, err := f.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case notNode:
+		value, err := f.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	case callNode:
+		return f.call(n)
+	case binaryNode:
+		return f.binary(n)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", n)
+}
+
+// binary evaluates a logical or comparison operation. Logical operations short-circuit, so that
+// e.g. `has("node") && version("node") >= "20"` does not run node when it is not installed.
+func (f Facts) binary(n binaryNode) (any, error) {
+	left, err := f.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := f.eval(n.right)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := f.eval(n.right)
+		return truthy(right), err
+	}
+	right, err := f.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		list, ok := right.([]any)
+		if !ok {
+			return nil, fmt.Errorf("the right side of \"in\" must be a list")
+		}
+		return slices.ContainsFunc(list, func(item any) bool { return equal(left, item) }), nil
+	}
+	// Ordering comparisons compare versions. They are false when either side is not a version,
+	// e.g. when version() returns an empty string for a tool that is not installed.
+	a, aok := left.(string)
+	b, bok := right.(string)
+	if !aok || !bok {
+		return false, nil
+	}
+	cmp, ok := platform.CompareVersions(a, b)
+	if !ok {
+		return false, nil
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", n.op)
+}
+
+// call evaluates a call to a built-in function.
+func (f Facts) call(n callNode) (any, error) {
+	args := make([]string, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := f.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s() arguments must be strings", n.name)
+		}
+		args = append(args, s)
+	}
+	switch n.name {
+	case "has":
+		return f.HasCommand != nil && f.HasCommand(args[0]), nil
+	case "env":
+		if f.LookupEnv == nil {
+			return "", nil
+		}
+		value, _ := f.LookupEnv(args[0])
+		return value, nil
+	case "exists":
+		return f.FileExists != nil && f.FileExists(args[0]), nil
+	case "version":
+		if f.Version == nil {
+			return "", nil
+		}
+		return f.Version(args[0]), nil
+	case "matches":
+		re, err := regexp.Compile(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("matches(): invalid pattern %q: %w", args[1], err)
+		}
+		return re.MatchString(args[0]), nil
+	}
+	return nil, fmt.Errorf("unknown function %q", n.name)
+}
+
+// truthy returns the boolean value of a value.
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+// equal compares two values. Booleans compare equal to their "true"/"false" string form.
+func equal(a any, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// ExtractVersion returns the first version number found in text, e.g. "20.11.1" from
+// "node v20.11.1". It returns an empty string if there is none.
+func ExtractVersion(text string) string {
+	return anyVersionPattern.FindString(text)
+}
+
+// anyVersionPattern matches a version number anywhere in a string.
+var anyVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)+|\d+`)
@@ -0,0 +1,133 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFacts() Facts {
+	return Facts{
+		Vars: map[string]string{
+			"os":             "linux",
+			"arch":           "arm64",
+			"distro":         "ubuntu",
+			"distro_version": "22.04",
+			"hostname":       "work-laptop",
+			"alias":          "work",
+		},
+		LookupEnv: func(name string) (string, bool) {
+			if name == "CI" {
+				return "true", true
+			}
+			return "", false
+		},
+		HasCommand: func(name string) bool { return name == "systemctl" || name == "node" },
+		FileExists: func(path string) bool { return path == "/etc/hosts" },
+		Version: func(tool string) string {
+			if tool == "node" {
+				return "20.11.1"
+			}
+			return ""
+		},
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected bool
+	}{
+		{`os == "linux" && arch == "arm64" && has("systemctl")`, true},
+		{`os == "macos" || arch == "amd64"`, false},
+		{`os != "windows"`, true},
+		{`!has("brew")`, true},
+		{`!(os == "linux")`, false},
+		{`distro in ["ubuntu", "debian"]`, true},
+		{`distro in []`, false},
+		{`distro_version >= 22.04`, true},
+		{`distro_version < "22.10"`, true},
+		{`distro_version > 24`, false},
+		{`version("node") >= "20"`, true},
+		{`version("node") >= "20.12"`, false},
+		{`version("node") == "20.11.1"`, true},
+		{`version("python") >= "3"`, false},
+		{`version("python") < "3"`, false},
+		{`version("python") == ""`, true},
+		{`env("CI")`, true},
+		{`env("CI") == "true"`, true},
+		{`env("MISSING")`, false},
+		{`exists("/etc/hosts") && !exists("/nope")`, true},
+		{`matches(hostname, "^work-")`, true},
+		{`alias == "home"`, false},
+		{`true && !false`, true},
+		{`has("brew") || has("node") && version("node") >= "18"`, true},
+	}
+	facts := testFacts()
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			result, err := Eval(tt.src, facts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEvalShortCircuits(t *testing.T) {
+	facts := testFacts()
+	calls := 0
+	facts.Version = func(tool string) string {
+		calls++
+		return "1.0"
+	}
+	result, err := Eval(`has("missing") && version("missing") >= "1"`, facts)
+	require.NoError(t, err)
+	assert.False(t, result)
+	result, err = Eval(`has("node") || version("node") >= "1"`, facts)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Zero(t, calls)
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`os in "linux"`, `the right side of "in" must be a list`},
+		{`matches(os, "(")`, "matches(): invalid pattern"},
+		{`has(true)`, "has() arguments must be strings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Eval(tt.src, testFacts())
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestEvalWithoutFacts(t *testing.T) {
+	result, err := Eval(`has("git") || exists("/") || env("HOME") || version("git")`, Facts{})
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"v20.11.1\n", "20.11.1"},
+		{"git version 2.45.1", "2.45.1"},
+		{"Python 3.12.2", "3.12.2"},
+		{"go version go1.24.0 linux/arm64", "1.24.0"},
+		{"tool 7", "7"},
+		{"no version", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExtractVersion(tt.text))
+		})
+	}
+}
@@ -0,0 +1,378 @@
+// Package expr implements the expression language used by `when` conditions. Expressions are
+// evaluated in-process over a fixed set of facts about the current machine, without running a
+// shell, e.g.:
+//
+//	os == "linux" && arch == "arm64" && has("systemctl")
+package expr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/chenasraf/sofmani/utils"
+)
+
+// Identifiers lists the facts that can be referenced by name in an expression.
+var Identifiers = []string{"os", "arch", "distro", "distro_version", "hostname", "alias"}
+
+// functionArity maps each built-in function to its number of arguments.
+var functionArity = map[string]int{
+	"has":     1,
+	"env":     1,
+	"exists":  1,
+	"version": 1,
+	"matches": 2,
+}
+
+// Expr is a parsed expression.
+type Expr struct {
+	source string
+	root   node
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// node is a node in the expression tree.
+type node any
+
+type (
+	// literalNode is a string or boolean literal.
+	literalNode struct{ value any }
+	// identNode is a reference to a fact.
+	identNode struct{ name string }
+	// callNode is a call to a built-in function.
+	callNode struct {
+		name string
+		args []node
+	}
+	// listNode is a list literal, e.g. ["ubuntu", "debian"].
+	listNode struct{ items []node }
+	// notNode negates its operand.
+	notNode struct{ operand node }
+	// binaryNode is a logical or comparison operation.
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+)
+
+// tokenKind is the kind of a lexed token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenIdent
+	tokenOp
+)
+
+// token is a lexed token.
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// operators lists the supported operators, longest first so that "==" is matched before "=".
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+// lex splits an expression into tokens.
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	for pos := 0; pos < len(src); {
+		c := rune(src[pos])
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+		case c == '"' || c == '\'':
+			value, end, err := lexString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: pos})
+			pos = end
+		case unicode.IsDigit(c):
+			end := pos
+			for end < len(src) && (unicode.IsDigit(rune(src[end])) || src[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: src[pos:end], pos: pos})
+			pos = end
+		case unicode.IsLetter(c) || c == '_':
+			end := pos
+			for end < len(src) && (unicode.IsLetter(rune(src[end])) || unicode.IsDigit(rune(src[end])) || src[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: src[pos:end], pos: pos})
+			pos = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos+1)
+			}
+			tokens = append(tokens, token{kind: tokenOp, value: op, pos: pos})
+			pos += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// lexString reads a quoted string starting at pos, returning its value and the position after the
+// closing quote. Backslash escapes the quote character and itself.
+func lexString(src string, pos int) (string, int, error) {
+	quote := src[pos]
+	var sb strings.Builder
+	for idx := pos + 1; idx < len(src); idx++ {
+		c := src[idx]
+		switch {
+		case c == quote:
+			return sb.String(), idx + 1, nil
+		case c == '\\' && idx+1 < len(src) && (src[idx+1] == quote || src[idx+1] == '\\'):
+			idx++
+			sb.WriteByte(src[idx])
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at position %d", pos+1)
+}
+
+// parser is a recursive descent parser over lexed tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses an expression. The grammar, from lowest to highest precedence, is:
+//
+//	expr       = or
+//	or         = and { "||" and }
+//	and        = not { "&&" not }
+//	not        = "!" not | comparison
+//	comparison = primary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" ) primary ]
+//	primary    = string | number | "true" | "false" | identifier | call | list | "(" expr ")"
+//	call       = identifier "(" [ expr { "," expr } ] ")"
+//	list       = "[" [ expr { "," expr } ] "]"
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", describeToken(tok), tok.pos+1)
+	}
+	return &Expr{source: src, root: root}, nil
+}
+
+// peek returns the current token.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances to the next one.
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept advances past the current token if it is the given operator.
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokenOp && tok.value == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect advances past the given operator, or returns an error.
+func (p *parser) expect(op string) error {
+	if p.accept(op) {
+		return nil
+	}
+	tok := p.peek()
+	return fmt.Errorf("expected %q but found %s at position %d", op, describeToken(tok), tok.pos+1)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	isComparison := tok.kind == tokenOp && slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, tok.value)
+	isIn := tok.kind == tokenIdent && tok.value == "in"
+	if !isComparison && !isIn {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: tok.value, left: left, right: right}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString, tokenNumber:
+		return literalNode{value: tok.value}, nil
+	case tokenIdent:
+		switch tok.value {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		if !slices.Contains(Identifiers, tok.value) {
+			return nil, unknownNameError("identifier", tok, Identifiers)
+		}
+		return identNode{name: tok.value}, nil
+	case tokenOp:
+		switch tok.value {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return listNode{items: items}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", describeToken(tok), tok.pos+1)
+}
+
+// parseCall parses the arguments of a function call, after the opening parenthesis.
+func (p *parser) parseCall(name token) (node, error) {
+	arity, ok := functionArity[name.value]
+	if !ok {
+		names := make([]string, 0, len(functionArity))
+		for fn := range functionArity {
+			names = append(names, fn)
+		}
+		slices.Sort(names)
+		return nil, unknownNameError("function", name, names)
+	}
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != arity {
+		return nil, fmt.Errorf("%s() takes %d argument(s) but got %d, at position %d", name.value, arity, len(args), name.pos+1)
+	}
+	return callNode{name: name.value, args: args}, nil
+}
+
+// parseList parses comma-separated expressions up to and including the closing operator.
+func (p *parser) parseList(closing string) ([]node, error) {
+	items := []node{}
+	if p.accept(closing) {
+		return items, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.accept(closing) {
+			return items, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// unknownNameError returns an error for an unknown identifier or function, suggesting the closest
+// known name.
+func unknownNameError(kind string, tok token, known []string) error {
+	msg := fmt.Sprintf("unknown %s %q at position %d", kind, tok.value, tok.pos+1)
+	if suggestion, ok := utils.ClosestMatch(tok.value, known); ok {
+		msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// describeToken returns a description of a token for error messages.
+func describeToken(tok token) string {
+	switch tok.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string %q", tok.value)
+	}
+	return fmt.Sprintf("%q", tok.value)
+}
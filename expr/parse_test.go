@@ -0,0 +1,51 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []string{
+		`os == "linux"`,
+		`os == 'linux' && arch == "arm64" && has("systemctl")`,
+		`!(os == "windows") || env("CI")`,
+		`distro in ["ubuntu", "debian"] && distro_version >= 22.04`,
+		`version("node") >= "20" && exists("~/.nvm")`,
+		`matches(hostname, "^work-")`,
+		`true`,
+		`"it\"s"`,
+	}
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			e, err := Parse(src)
+			require.NoError(t, err)
+			assert.Equal(t, src, e.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`os ==`, "unexpected end of expression at position 6"},
+		{`os = "linux"`, `unexpected character '=' at position 4`},
+		{`oss == "linux"`, `unknown identifier "oss" at position 1 (did you mean "os"?)`},
+		{`hsa("git")`, `unknown function "hsa" at position 1 (did you mean "has"?)`},
+		{`has("git", "x")`, "has() takes 1 argument(s) but got 2, at position 1"},
+		{`(os == "linux"`, `expected ")" but found end of expression at position 15`},
+		{`os == "linux`, "unterminated string at position 7"},
+		{`os == "linux" arch`, `unexpected "arch" at position 15`},
+		{`distro in ["a" "b"]`, `expected "," but found string "b" at position 16`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
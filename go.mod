@@ -3,6 +3,7 @@ module github.com/chenasraf/sofmani
 go 1.24.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/davecgh/go-spew v1.1.1
 	github.com/eschao/config v0.1.0
 	github.com/mattn/go-runewidth v0.0.19
@@ -10,6 +11,7 @@ require (
 	github.com/spf13/cobra v1.10.2
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/term v0.39.0
+	gopkg.in/yaml.v2 v2.4.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -20,5 +22,4 @@ require (
 	github.com/spf13/pflag v1.0.9 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
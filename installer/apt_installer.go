@@ -21,11 +21,11 @@ type AptInstaller struct {
 // AptOpts represents options for the AptInstaller.
 type AptOpts struct {
 	// Flags is a string of additional flags to pass to the apt/apk command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // AptPackageManager represents a package manager type.
@@ -60,7 +60,11 @@ func (i *AptInstaller) runRepoUpdate() error {
 
 // Install implements IInstaller.
 func (i *AptInstaller) Install() error {
-	name := *i.Info.Name
+	return i.InstallPackages([]string{*i.Info.Name})
+}
+
+// InstallPackages implements IBatchInstaller.
+func (i *AptInstaller) InstallPackages(names []string) error {
 	opts := i.GetOpts()
 	err := i.runRepoUpdate()
 	if err != nil {
@@ -84,7 +88,7 @@ func (i *AptInstaller) Install() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough(string(i.PackageManager), args...)
 }
 
@@ -100,6 +104,11 @@ func (i *AptInstaller) getConfirmArg() string {
 
 // Update implements IInstaller.
 func (i *AptInstaller) Update() error {
+	return i.UpdatePackages([]string{*i.Info.Name})
+}
+
+// UpdatePackages implements IBatchInstaller.
+func (i *AptInstaller) UpdatePackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"upgrade"}
 	if i.IsVerbose() {
@@ -115,7 +124,7 @@ func (i *AptInstaller) Update() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, *i.Info.Name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough(string(i.PackageManager), args...)
 }
 
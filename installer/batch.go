@@ -0,0 +1,153 @@
+package installer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/summary"
+	"github.com/samber/lo"
+)
+
+// IBatchInstaller is implemented by installers that can install or update several packages with a
+// single package manager invocation, for entries that list their packages in names.
+type IBatchInstaller interface {
+	IInstaller
+	// InstallPackages installs the given packages.
+	InstallPackages(names []string) error
+	// UpdatePackages updates the given packages.
+	UpdatePackages(names []string) error
+}
+
+// batchInstallerTypes lists the installer types that accept names.
+var batchInstallerTypes = []appconfig.InstallerType{
+	appconfig.InstallerTypeApt,
+	appconfig.InstallerTypeApk,
+	appconfig.InstallerTypePacman,
+	appconfig.InstallerTypeYay,
+	appconfig.InstallerTypeBrew,
+	appconfig.InstallerTypeNpm,
+	appconfig.InstallerTypePnpm,
+	appconfig.InstallerTypeYarn,
+	appconfig.InstallerTypePipx,
+	appconfig.InstallerTypeDnf,
+	appconfig.InstallerTypeYum,
+	appconfig.InstallerTypeZypper,
+}
+
+// validateNames checks the names of an installer, if it has any.
+func validateNames(info *appconfig.InstallerData) []ValidationError {
+	if info.Names == nil {
+		return nil
+	}
+	errors := []ValidationError{}
+	if !slices.Contains(batchInstallerTypes, info.Type) {
+		errors = append(errors, ValidationError{
+			FieldName: "names",
+			Message:   fmt.Sprintf("Not supported for type %s", info.Type),
+		})
+	}
+	if len(*info.Names) == 0 || slices.Contains(*info.Names, "") {
+		errors = append(errors, ValidationError{FieldName: "names", Message: validationIsNotEmpty()})
+	}
+	if info.BinName != nil {
+		errors = append(errors, ValidationError{FieldName: "bin_name", Message: "Cannot be used with names"})
+	}
+	return errors
+}
+
+// packageInstallers returns an installer for each package of a batch installer, which checks
+// whether that package is installed or outdated.
+func packageInstallers(config *appconfig.AppConfig, installer IBatchInstaller) ([]IInstaller, error) {
+	info := installer.GetData()
+	installers := make([]IInstaller, 0, len(*info.Names))
+	for _, name := range *info.Names {
+		data := *info
+		data.Name = lo.ToPtr(name)
+		data.Names = nil
+		pkg, err := GetInstaller(config, &data)
+		if err != nil {
+			return nil, err
+		}
+		if vars := installer.GetTemplateVars(); vars != nil {
+			pkgVars := *vars
+			pkgVars.Package = name
+			pkg.SetTemplateVars(&pkgVars)
+		}
+		installers = append(installers, pkg)
+	}
+	return installers, nil
+}
+
+// runBatch checks each package of a batch installer using the package installers, then installs
+// the missing ones and updates the outdated ones with one invocation each. The hooks run once around
+// each invocation. Each package is reported as a child of the result.
+func runBatch(
+	config *appconfig.AppConfig,
+	installer IBatchInstaller,
+	packages []IInstaller,
+	result *summary.InstallResult,
+	runHook func(hook *string) error,
+) error {
+	info := installer.GetData()
+
+	missing := []string{}
+	outdated := []string{}
+	for _, pkg := range packages {
+		name := *pkg.GetData().Name
+		child := summary.InstallResult{Name: name, Type: result.Type, Action: summary.ActionUpToDate}
+		logger.Debug("Checking %s: %s", logger.H(string(info.Type)), logger.H(name))
+		installed, err := pkg.CheckIsInstalled()
+		if err != nil {
+			return err
+		}
+		if !installed {
+			missing = append(missing, name)
+			child.Action = summary.ActionInstalled
+		} else if *config.CheckUpdates {
+			needsUpdate, err := pkg.CheckNeedsUpdate()
+			if err != nil {
+				return err
+			}
+			if needsUpdate {
+				outdated = append(outdated, name)
+				child.Action = summary.ActionUpgraded
+			}
+		}
+		result.Children = append(result.Children, child)
+	}
+
+	result.Action = summary.ActionUpToDate
+	if len(outdated) > 0 {
+		logger.Info("Updating %s: %s", logger.H(string(info.Type)), logger.H(strings.Join(outdated, " ")))
+		if err := runHook(info.PreUpdate); err != nil {
+			return err
+		}
+		if err := installer.UpdatePackages(outdated); err != nil {
+			return fmt.Errorf("failed to update %s: %w", strings.Join(outdated, " "), err)
+		}
+		if err := runHook(info.PostUpdate); err != nil {
+			return err
+		}
+		result.Action = summary.ActionUpgraded
+	}
+	if len(missing) > 0 {
+		logger.Info("Installing %s: %s", logger.H(string(info.Type)), logger.H(strings.Join(missing, " ")))
+		if err := runHook(info.PreInstall); err != nil {
+			return err
+		}
+		if err := installer.InstallPackages(missing); err != nil {
+			return err
+		}
+		if err := runHook(info.PostInstall); err != nil {
+			return err
+		}
+		result.Action = summary.ActionInstalled
+	}
+	if len(missing)+len(outdated) == 0 && *config.CheckUpdates {
+		logger.Info("%s: %s is up-to-date", logger.H(string(info.Type)), logger.H(*info.Name))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/summary"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	logger.InitLogger(false)
+
+	newPackage := func(name string, installed bool, needsUpdate bool) IInstaller {
+		return &MockInstaller{
+			data:        &appconfig.InstallerData{Name: lo.ToPtr(name), Type: appconfig.InstallerTypeApt},
+			isInstalled: installed,
+			needsUpdate: needsUpdate,
+		}
+	}
+	newBatch := func() *MockBatchInstaller {
+		return &MockBatchInstaller{MockInstaller: MockInstaller{data: &appconfig.InstallerData{
+			Name:        lo.ToPtr("git curl jq wget"),
+			Names:       &[]string{"git", "curl", "jq", "wget"},
+			Type:        appconfig.InstallerTypeApt,
+			PreInstall:  lo.ToPtr("pre-install"),
+			PostInstall: lo.ToPtr("post-install"),
+			PreUpdate:   lo.ToPtr("pre-update"),
+		}}}
+	}
+	packages := []IInstaller{
+		newPackage("git", true, false),
+		newPackage("curl", false, false),
+		newPackage("jq", true, true),
+		newPackage("wget", false, false),
+	}
+
+	t.Run("installs and updates in one invocation each", func(t *testing.T) {
+		batch := newBatch()
+		hooks := []string{}
+		runHook := func(hook *string) error {
+			if hook != nil {
+				hooks = append(hooks, *hook)
+			}
+			return nil
+		}
+		result := &summary.InstallResult{Name: "git curl jq wget", Type: "apt"}
+		config := &appconfig.AppConfig{CheckUpdates: lo.ToPtr(true)}
+		require.NoError(t, runBatch(config, batch, packages, result, runHook))
+
+		assert.Equal(t, [][]string{{"curl", "wget"}}, batch.installed)
+		assert.Equal(t, [][]string{{"jq"}}, batch.updated)
+		assert.Equal(t, []string{"pre-update", "pre-install", "post-install"}, hooks)
+		assert.Equal(t, summary.ActionInstalled, result.Action)
+		assert.Equal(t, []summary.InstallResult{
+			{Name: "git", Type: "apt", Action: summary.ActionUpToDate},
+			{Name: "curl", Type: "apt", Action: summary.ActionInstalled},
+			{Name: "jq", Type: "apt", Action: summary.ActionUpgraded},
+			{Name: "wget", Type: "apt", Action: summary.ActionInstalled},
+		}, result.Children)
+	})
+
+	t.Run("skips update checks when disabled", func(t *testing.T) {
+		batch := newBatch()
+		result := &summary.InstallResult{Name: "git curl jq wget", Type: "apt"}
+		config := &appconfig.AppConfig{CheckUpdates: lo.ToPtr(false)}
+		require.NoError(t, runBatch(config, batch, packages, result, func(*string) error { return nil }))
+
+		assert.Equal(t, [][]string{{"curl", "wget"}}, batch.installed)
+		assert.Empty(t, batch.updated)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		batch := newBatch()
+		result := &summary.InstallResult{Name: "git", Type: "apt"}
+		config := &appconfig.AppConfig{CheckUpdates: lo.ToPtr(true)}
+		require.NoError(t, runBatch(config, batch, packages[:1], result, func(*string) error { return nil }))
+
+		assert.Empty(t, batch.installed)
+		assert.Empty(t, batch.updated)
+		assert.Equal(t, summary.ActionUpToDate, result.Action)
+	})
+}
+
+func TestValidateNames(t *testing.T) {
+	assert.Empty(t, validateNames(&appconfig.InstallerData{Type: appconfig.InstallerTypeApt}))
+	assert.Empty(t, validateNames(&appconfig.InstallerData{
+		Type:  appconfig.InstallerTypeApt,
+		Names: &[]string{"git", "curl"},
+	}))
+	assertValidationError(t, validateNames(&appconfig.InstallerData{
+		Type:  appconfig.InstallerTypeShell,
+		Names: &[]string{"git"},
+	}), "names")
+	assertValidationError(t, validateNames(&appconfig.InstallerData{
+		Type:  appconfig.InstallerTypeApt,
+		Names: &[]string{},
+	}), "names")
+	assertValidationError(t, validateNames(&appconfig.InstallerData{
+		Type:    appconfig.InstallerTypeApt,
+		Names:   &[]string{"git"},
+		BinName: lo.ToPtr("git"),
+	}), "bin_name")
+}
+
+func TestBatchInstallerTypes(t *testing.T) {
+	// Every type that accepts names must support batching.
+	for _, installerType := range batchInstallerTypes {
+		inst, err := GetInstaller(&appconfig.AppConfig{}, &appconfig.InstallerData{
+			Name: lo.ToPtr("pkg"),
+			Type: installerType,
+		})
+		require.NoError(t, err)
+		_, ok := inst.(IBatchInstaller)
+		assert.Truef(t, ok, "%s installer does not implement IBatchInstaller", installerType)
+	}
+}
+
+func TestPackageInstallers(t *testing.T) {
+	batch := NewAptInstaller(&appconfig.AppConfig{}, &appconfig.InstallerData{
+		Names: &[]string{"git", "curl"},
+		Type:  appconfig.InstallerTypeApt,
+		Opts:  &map[string]any{"flags": "--no-install-recommends"},
+	})
+	batch.SetTemplateVars(&TemplateVars{OS: "linux"})
+	packages, err := packageInstallers(&appconfig.AppConfig{}, batch)
+	require.NoError(t, err)
+	require.Len(t, packages, 2)
+	assert.Equal(t, "curl", *packages[1].GetData().Name)
+	assert.Equal(t, &TemplateVars{OS: "linux", Package: "curl"}, packages[1].GetTemplateVars())
+	assert.Empty(t, batch.GetTemplateVars().Package)
+	assert.Nil(t, packages[1].GetData().Names)
+	assert.Equal(t, "--no-install-recommends", (*packages[1].GetData().Opts)["flags"])
+}
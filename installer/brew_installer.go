@@ -13,6 +13,7 @@ import (
 	"github.com/chenasraf/sofmani/appconfig"
 	"github.com/chenasraf/sofmani/logger"
 	"github.com/chenasraf/sofmani/utils"
+	"github.com/samber/lo"
 )
 
 // BrewInstaller is an installer for Homebrew packages.
@@ -27,15 +28,15 @@ type BrewInstaller struct {
 // BrewOpts represents options for the BrewInstaller.
 type BrewOpts struct {
 	// Tap is the Homebrew tap to use for the package.
-	Tap *string
+	Tap *string `json:"tap"           yaml:"tap"`
 	// Cask installs the formula as a cask instead of a regular package.
-	Cask *bool
+	Cask *bool `json:"cask"          yaml:"cask"`
 	// Flags is a string of additional flags to pass to the brew command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // Validate validates the installer configuration.
@@ -53,7 +54,11 @@ func (i *BrewInstaller) Validate() []ValidationError {
 
 // Install implements IInstaller.
 func (i *BrewInstaller) Install() error {
-	name := i.GetFullName()
+	return i.InstallPackages([]string{*i.Info.Name})
+}
+
+// InstallPackages implements IBatchInstaller.
+func (i *BrewInstaller) InstallPackages(names []string) error {
 	opts := i.GetOpts()
 	if err := i.ensureTapped(); err != nil {
 		return err
@@ -71,14 +76,18 @@ func (i *BrewInstaller) Install() error {
 	} else if opts.Flags != nil {
 		cmd += " " + *opts.Flags
 	}
-	err := i.RunCmdAsFile(fmt.Sprintf("%s %s", cmd, name))
+	err := i.RunCmdAsFile(fmt.Sprintf("%s %s", cmd, i.fullNames(names)))
 	i.markBrewRepoUpdated()
 	return err
 }
 
 // Update implements IInstaller.
 func (i *BrewInstaller) Update() error {
-	name := i.GetFullName()
+	return i.UpdatePackages([]string{*i.Info.Name})
+}
+
+// UpdatePackages implements IBatchInstaller.
+func (i *BrewInstaller) UpdatePackages(names []string) error {
 	opts := i.GetOpts()
 	if err := i.ensureTapped(); err != nil {
 		return err
@@ -96,18 +105,22 @@ func (i *BrewInstaller) Update() error {
 	} else if opts.Flags != nil {
 		cmd += " " + *opts.Flags
 	}
-	err := i.RunCmdAsFile(fmt.Sprintf("%s %s", cmd, name))
+	err := i.RunCmdAsFile(fmt.Sprintf("%s %s", cmd, i.fullNames(names)))
 	i.markBrewRepoUpdated()
 	return err
 }
 
 // GetFullName returns the full name of the package, including the tap if specified.
 func (i *BrewInstaller) GetFullName() string {
-	name := *i.Info.Name
-	if i.GetOpts().Tap != nil {
-		name = *i.GetOpts().Tap + "/" + name
+	return i.fullNames([]string{*i.Info.Name})
+}
+
+// fullNames returns the space-separated full names of the packages, including the tap if specified.
+func (i *BrewInstaller) fullNames(names []string) string {
+	if tap := i.GetOpts().Tap; tap != nil {
+		names = lo.Map(names, func(name string, _ int) string { return *tap + "/" + name })
 	}
-	return name
+	return strings.Join(names, " ")
 }
 
 // ensureTapped runs `brew tap <tap>` once per process for the installer's configured tap.
@@ -19,11 +19,11 @@ type CargoInstaller struct {
 // CargoOpts represents options for the CargoInstaller.
 type CargoOpts struct {
 	// Flags is a string of additional flags to pass to the cargo command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // Validate validates the installer configuration.
@@ -0,0 +1,251 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/utils"
+)
+
+// DnfInstaller is an installer for dnf, yum and zypper packages.
+type DnfInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+	// PackageManager is the package manager to use (dnf, yum or zypper).
+	PackageManager DnfPackageManager
+}
+
+// DnfOpts represents options for the DnfInstaller.
+type DnfOpts struct {
+	// Flags is a string of additional flags to pass to the dnf/yum/zypper command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+// DnfPackageManager represents an RPM-based package manager type.
+type DnfPackageManager string
+
+// Constants for supported RPM-based package managers.
+const (
+	PackageManagerDnf    DnfPackageManager = "dnf"    // PackageManagerDnf represents the dnf package manager.
+	PackageManagerYum    DnfPackageManager = "yum"    // PackageManagerYum represents the yum package manager.
+	PackageManagerZypper DnfPackageManager = "zypper" // PackageManagerZypper represents the zypper package manager.
+)
+
+// Validate validates the installer configuration.
+func (i *DnfInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	return errors
+}
+
+// runRepoUpdate refreshes the package manager's metadata according to the configured mode.
+func (i *DnfInstaller) runRepoUpdate() error {
+	refresh := func() error {
+		return i.RunCmdPassThrough(string(i.PackageManager), i.command(i.refreshCommand())...)
+	}
+	mode := i.Config.GetRepoUpdateMode(i.Info.Type)
+	switch mode {
+	case appconfig.RepoUpdateNever:
+		return nil
+	case appconfig.RepoUpdateAlways:
+		return refresh()
+	default: // once
+		return RunRepoUpdateOnce(string(i.PackageManager)+"-update", refresh)
+	}
+}
+
+// refreshCommand returns the command that refreshes the package metadata.
+func (i *DnfInstaller) refreshCommand() string {
+	if i.PackageManager == PackageManagerZypper {
+		return "refresh"
+	}
+	return "makecache"
+}
+
+// command returns the arguments for a package manager command, with the flags that make it run
+// without prompting. zypper takes them before the command, dnf and yum after it.
+func (i *DnfInstaller) command(name string) []string {
+	if i.PackageManager == PackageManagerZypper {
+		args := []string{"--non-interactive"}
+		if i.IsVerbose() {
+			args = append(args, "--verbose")
+		}
+		return append(args, name)
+	}
+	args := []string{name, "-y"}
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	return args
+}
+
+// Install implements IInstaller.
+func (i *DnfInstaller) Install() error {
+	return i.InstallPackages([]string{*i.Info.Name})
+}
+
+// InstallPackages implements IBatchInstaller.
+func (i *DnfInstaller) InstallPackages(names []string) error {
+	err := i.runRepoUpdate()
+	if err != nil {
+		return err
+	}
+	return i.RunCmdPassThrough(string(i.PackageManager), i.installArgs(names)...)
+}
+
+// installArgs returns the arguments to install the given packages.
+func (i *DnfInstaller) installArgs(names []string) []string {
+	opts := i.GetOpts()
+	args := i.command("install")
+	if opts.InstallFlags != nil {
+		args = append(args, strings.Fields(*opts.InstallFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, names...)
+}
+
+// Update implements IInstaller.
+func (i *DnfInstaller) Update() error {
+	return i.UpdatePackages([]string{*i.Info.Name})
+}
+
+// UpdatePackages implements IBatchInstaller.
+func (i *DnfInstaller) UpdatePackages(names []string) error {
+	return i.RunCmdPassThrough(string(i.PackageManager), i.updateArgs(names)...)
+}
+
+// updateArgs returns the arguments to update the given packages.
+func (i *DnfInstaller) updateArgs(names []string) []string {
+	opts := i.GetOpts()
+	update := "upgrade"
+	if i.PackageManager != PackageManagerDnf {
+		update = "update"
+	}
+	args := i.command(update)
+	if opts.UpdateFlags != nil {
+		args = append(args, strings.Fields(*opts.UpdateFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, names...)
+}
+
+// CheckNeedsUpdate implements IInstaller.
+func (i *DnfInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	err := i.runRepoUpdate()
+	if err != nil {
+		return false, err
+	}
+	if i.PackageManager == PackageManagerZypper {
+		out, err := i.RunCmdGetOutput(string(i.PackageManager), "--non-interactive", "--quiet", "list-updates")
+		if err != nil {
+			return false, err
+		}
+		return zypperHasUpdate(string(out), *i.Info.Name), nil
+	}
+	_, err = i.RunCmdGetOutput(string(i.PackageManager), "--quiet", "check-update", *i.Info.Name)
+	return checkUpdateResult(err)
+}
+
+// checkUpdateResult interprets the result of `dnf check-update` or `yum check-update`, which exit
+// with 100 when updates are available and 0 when there are none. Any other exit code is a failure.
+func checkUpdateResult(err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 100 {
+		return true, nil
+	}
+	if exitErr != nil && len(exitErr.Stderr) > 0 {
+		return false, fmt.Errorf("check-update failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return false, fmt.Errorf("check-update failed: %w", err)
+}
+
+// zypperHasUpdate returns whether the output of `zypper list-updates` lists the given package. The
+// output is a table with the package name in its third column.
+func zypperHasUpdate(output string, name string) bool {
+	for line := range strings.Lines(output) {
+		columns := strings.Split(line, "|")
+		if len(columns) > 2 && strings.TrimSpace(columns[2]) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *DnfInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	return i.RunCmdGetSuccess(utils.GetShellWhich(), i.GetBinName())
+}
+
+// GetData implements IInstaller.
+func (i *DnfInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the DnfInstaller.
+func (i *DnfInstaller) GetOpts() *DnfOpts {
+	opts := &DnfOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// GetBinName returns the binary name for the installer.
+// It uses the BinName from the installer data if provided, otherwise it uses the installer name.
+func (i *DnfInstaller) GetBinName() string {
+	info := i.GetData()
+	if info.BinName != nil && len(*info.BinName) > 0 {
+		return *info.BinName
+	}
+	return *info.Name
+}
+
+// NewDnfInstaller creates a new DnfInstaller.
+func NewDnfInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *DnfInstaller {
+	var packageManager DnfPackageManager
+	switch installer.Type {
+	case appconfig.InstallerTypeDnf:
+		packageManager = PackageManagerDnf
+	case appconfig.InstallerTypeYum:
+		packageManager = PackageManagerYum
+	case appconfig.InstallerTypeZypper:
+		packageManager = PackageManagerZypper
+	}
+	i := &DnfInstaller{
+		InstallerBase:  InstallerBase{Data: installer},
+		Config:         cfg,
+		Info:           installer,
+		PackageManager: packageManager,
+	}
+
+	return i
+}
@@ -0,0 +1,159 @@
+package installer
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDnfInstaller(data *appconfig.InstallerData) *DnfInstaller {
+	return NewDnfInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestDnfValidation(t *testing.T) {
+	logger.InitLogger(false)
+	for _, installerType := range []appconfig.InstallerType{
+		appconfig.InstallerTypeDnf,
+		appconfig.InstallerTypeYum,
+		appconfig.InstallerTypeZypper,
+	} {
+		installer := newTestDnfInstaller(&appconfig.InstallerData{Name: lo.ToPtr("vim"), Type: installerType})
+		assertNoValidationErrors(t, installer.Validate())
+		assert.Equal(t, DnfPackageManager(installerType), installer.PackageManager)
+	}
+}
+
+func TestDnfGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestDnfInstaller(&appconfig.InstallerData{Name: lo.ToPtr("vim"), Type: appconfig.InstallerTypeDnf})
+	opts := installer.GetOpts()
+	assert.Nil(t, opts.Flags)
+	assert.Nil(t, opts.InstallFlags)
+	assert.Nil(t, opts.UpdateFlags)
+
+	installer = newTestDnfInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("vim"),
+		Type: appconfig.InstallerTypeDnf,
+		Opts: &map[string]any{
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts = installer.GetOpts()
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+}
+
+func TestDnfArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	tests := []struct {
+		name    string
+		data    *appconfig.InstallerData
+		install []string
+		update  []string
+	}{
+		{
+			name:    "dnf",
+			data:    &appconfig.InstallerData{Name: lo.ToPtr("vim"), Type: appconfig.InstallerTypeDnf},
+			install: []string{"install", "-y", "vim"},
+			update:  []string{"upgrade", "-y", "vim"},
+		},
+		{
+			name: "yum with flags",
+			data: &appconfig.InstallerData{
+				Name: lo.ToPtr("vim"),
+				Type: appconfig.InstallerTypeYum,
+				Opts: &map[string]any{"flags": "--nogpgcheck", "update_flags": "--security"},
+			},
+			install: []string{"install", "-y", "--nogpgcheck", "vim"},
+			update:  []string{"update", "-y", "--security", "vim"},
+		},
+		{
+			name: "verbose zypper",
+			data: &appconfig.InstallerData{
+				Name:    lo.ToPtr("vim"),
+				Type:    appconfig.InstallerTypeZypper,
+				Verbose: lo.ToPtr(true),
+				Opts:    &map[string]any{"install_flags": "--no-recommends"},
+			},
+			install: []string{"--non-interactive", "--verbose", "install", "--no-recommends", "vim"},
+			update:  []string{"--non-interactive", "--verbose", "update", "vim"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := newTestDnfInstaller(tt.data)
+			assert.Equal(t, tt.install, installer.installArgs([]string{"vim"}))
+			assert.Equal(t, tt.update, installer.updateArgs([]string{"vim"}))
+		})
+	}
+}
+
+func TestDnfRefreshCommand(t *testing.T) {
+	dnf := newTestDnfInstaller(&appconfig.InstallerData{Name: lo.ToPtr("vim"), Type: appconfig.InstallerTypeDnf})
+	assert.Equal(t, "makecache", dnf.refreshCommand())
+	zypper := newTestDnfInstaller(&appconfig.InstallerData{Name: lo.ToPtr("vim"), Type: appconfig.InstallerTypeZypper})
+	assert.Equal(t, "refresh", zypper.refreshCommand())
+}
+
+func TestZypperHasUpdate(t *testing.T) {
+	output := `S | Repository | Name    | Current Version | Available Version | Arch
+--+------------+---------+-----------------+-------------------+-------
+v | repo-oss   | vim     | 9.0.1           | 9.1.0             | x86_64
+v | repo-oss   | vim-data| 9.0.1           | 9.1.0             | noarch
+`
+	assert.True(t, zypperHasUpdate(output, "vim"))
+	assert.True(t, zypperHasUpdate(output, "vim-data"))
+	assert.False(t, zypperHasUpdate(output, "git"))
+	assert.False(t, zypperHasUpdate("", "vim"))
+}
+
+func TestCheckUpdateResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh to produce exit codes")
+	}
+	exitWith := func(code string) error {
+		_, err := exec.Command("sh", "-c", "echo 'repo error' >&2; exit "+code).Output()
+		return err
+	}
+
+	hasUpdate, err := checkUpdateResult(nil)
+	require.NoError(t, err)
+	assert.False(t, hasUpdate)
+
+	hasUpdate, err = checkUpdateResult(exitWith("100"))
+	require.NoError(t, err)
+	assert.True(t, hasUpdate)
+
+	for _, code := range []string{"1", "2"} {
+		hasUpdate, err = checkUpdateResult(exitWith(code))
+		assert.ErrorContains(t, err, "repo error", code)
+		assert.False(t, hasUpdate, code)
+	}
+	_, err = checkUpdateResult(exec.Command("sofmani-missing-binary").Run())
+	assert.Error(t, err)
+}
+
+func TestDnfCheckNeedsUpdate(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestDnfInstaller(&appconfig.InstallerData{
+		Name:           lo.ToPtr("vim"),
+		Type:           appconfig.InstallerTypeDnf,
+		CheckHasUpdate: lo.ToPtr("true"),
+	})
+	result, err := installer.CheckNeedsUpdate()
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
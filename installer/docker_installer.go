@@ -23,11 +23,11 @@ type DockerInstaller struct {
 // DockerOpts represents options for the DockerInstaller.
 type DockerOpts struct {
 	// Flags is a string of flags to pass to the `docker run` command.
-	Flags *string
+	Flags *string `json:"flags"               yaml:"flags"`
 	// Platform is a platform-specific map of Docker platform strings (e.g., "linux/amd64").
-	Platform *platform.PlatformMap[string]
+	Platform *platform.PlatformMap[string] `json:"platform"            yaml:"platform"`
 	// SkipIfUnavailable indicates whether to skip installation if Docker is unavailable.
-	SkipIfUnavailable *bool
+	SkipIfUnavailable *bool `json:"skip_if_unavailable" yaml:"skip_if_unavailable"`
 }
 
 // NewDockerInstaller creates a new DockerInstaller.
@@ -42,6 +42,7 @@ func NewDockerInstaller(cfg *appconfig.AppConfig, installer *appconfig.Installer
 // Validate validates the installer configuration.
 func (i *DockerInstaller) Validate() []ValidationError {
 	errors := i.BaseValidate()
+	errors = append(errors, i.validatePlatformMapOpt("platform")...)
 	return errors
 }
 
@@ -117,7 +118,8 @@ func (i *DockerInstaller) GetOpts() *DockerOpts {
 			opts.Flags = &flags
 		}
 		if raw, ok := (*i.Info.Opts)["platform"]; ok && raw != nil {
-			opts.Platform = platform.NewPlatformMap[string](raw)
+			// Unknown platform keys are reported by Validate.
+			opts.Platform, _ = platform.NewPlatformMap[string](raw)
 		}
 	}
 	if skip, ok := (*i.Info.Opts)["skip_if_unavailable"].(bool); ok {
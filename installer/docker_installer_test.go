@@ -41,6 +41,16 @@ func TestDockerValidation(t *testing.T) {
 	}
 	assertNoValidationErrors(t, newTestDockerInstaller(withFlags).Validate())
 
+	// 🔴 Invalid: unknown platform key
+	unknownPlatformKey := &appconfig.InstallerData{
+		Name: lo.ToPtr("ghcr.io/open-webui/open-webui:main"),
+		Type: appconfig.InstallerTypeDocker,
+		Opts: &map[string]any{
+			"platform": map[any]any{"linux": "linux/amd64", "freebsd": "linux/amd64"},
+		},
+	}
+	assertValidationError(t, newTestDockerInstaller(unknownPlatformKey).Validate(), "platform")
+
 	// 🔴 Invalid: missing name (should be caught by BaseValidate)
 	invalid := &appconfig.InstallerData{
 		Type: appconfig.InstallerTypeDocker,
@@ -9,7 +9,8 @@ import (
 
 // FilterInstaller determines whether an installer should be included based on a list of filters.
 // Filters can be positive (e.g., "name") or negative (e.g., "!name").
-// Filters can also target specific fields like type (e.g., "type:brew") or tags (e.g., "tag:database").
+// Filters can also target specific fields like type (e.g., "type:brew"), tags (e.g., "tag:database")
+// or the category an installer is listed under (e.g., "category:Development Tools").
 func FilterInstaller(installer IInstaller, filters []string) bool {
 	if len(filters) == 0 {
 		return true
@@ -52,6 +53,12 @@ func isFilteredIn(installer IInstaller, filter string) bool {
 			return true
 		}
 	}
+	if strings.HasPrefix(filter, "category:") {
+		categoryName := filter[len("category:"):]
+		if data.Section != nil && strings.EqualFold(*data.Section, categoryName) {
+			return true
+		}
+	}
 	return strings.Contains(*data.Name, filter)
 }
 
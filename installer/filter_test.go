@@ -88,6 +88,30 @@ func TestFilterInstaller(t *testing.T) {
 			filters:  []string{"type:npm"},
 			expected: false,
 		},
+		{
+			name: "Category filter match",
+			installer: &MockInstaller{
+				data: &appconfig.InstallerData{Name: lo.ToPtr("test"), Section: lo.ToPtr("Dev Tools")},
+			},
+			filters:  []string{"category:dev tools"},
+			expected: true,
+		},
+		{
+			name: "Category filter no match",
+			installer: &MockInstaller{
+				data: &appconfig.InstallerData{Name: lo.ToPtr("test"), Section: lo.ToPtr("Dev Tools")},
+			},
+			filters:  []string{"category:Work"},
+			expected: false,
+		},
+		{
+			name: "Category filter without category",
+			installer: &MockInstaller{
+				data: &appconfig.InstallerData{Name: lo.ToPtr("test")},
+			},
+			filters:  []string{"!category:Work"},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,215 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+)
+
+// FlatpakInstaller is an installer for Flatpak applications.
+type FlatpakInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// FlatpakOpts represents options for the FlatpakInstaller.
+type FlatpakOpts struct {
+	// Remote is the name of the remote to install the application from. Defaults to "flathub".
+	Remote *string `json:"remote"        yaml:"remote"`
+	// RemoteURL is the URL of a .flatpakrepo file, used to add the remote if it doesn't exist.
+	// Defaults to the Flathub repo when the remote is "flathub".
+	RemoteURL *string `json:"remote_url"    yaml:"remote_url"`
+	// Scope is the installation to use, either "user" or "system". Defaults to "system".
+	Scope *string `json:"scope"         yaml:"scope"`
+	// Flags is a string of additional flags to pass to the flatpak command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+const (
+	flatpakDefaultRemote = "flathub"
+	flathubRepoURL       = "https://dl.flathub.org/repo/flathub.flatpakrepo"
+)
+
+// Validate validates the installer configuration.
+func (i *FlatpakInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	info := i.GetData()
+	opts := i.GetOpts()
+	if opts.Scope != nil && *opts.Scope != "user" && *opts.Scope != "system" {
+		errors = append(errors, ValidationError{FieldName: "scope", Message: validationInvalidFormat(), InstallerName: *info.Name})
+	}
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *FlatpakInstaller) Install() error {
+	if err := i.ensureRemote(); err != nil {
+		return err
+	}
+	return i.RunCmdPassThrough("flatpak", i.installArgs()...)
+}
+
+// installArgs returns the arguments to install the application.
+func (i *FlatpakInstaller) installArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("install")
+	if opts.InstallFlags != nil {
+		args = append(args, strings.Fields(*opts.InstallFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, i.GetRemote(), *i.Info.Name)
+}
+
+// Update implements IInstaller.
+func (i *FlatpakInstaller) Update() error {
+	return i.RunCmdPassThrough("flatpak", i.updateArgs()...)
+}
+
+// updateArgs returns the arguments to update the application.
+func (i *FlatpakInstaller) updateArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("update")
+	if opts.UpdateFlags != nil {
+		args = append(args, strings.Fields(*opts.UpdateFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// command returns the arguments for a non-interactive flatpak command in the installer's scope.
+func (i *FlatpakInstaller) command(name string) []string {
+	args := []string{name, "--noninteractive", "-y", i.scopeFlag()}
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	return args
+}
+
+// scopeFlag returns the flag that selects the installation to use.
+func (i *FlatpakInstaller) scopeFlag() string {
+	return "--" + i.GetScope()
+}
+
+// ensureRemote adds the installer's remote once per run, if it has a URL to add it from.
+func (i *FlatpakInstaller) ensureRemote() error {
+	remote := i.GetRemote()
+	url := i.GetOpts().RemoteURL
+	if url == nil && remote == flatpakDefaultRemote {
+		url = lo.ToPtr(flathubRepoURL)
+	}
+	if url == nil {
+		return nil
+	}
+	scope := i.scopeFlag()
+	return RunRepoUpdateOnce("flatpak-remote:"+scope+":"+remote, func() error {
+		logger.Debug("Adding flatpak remote %s", remote)
+		err := i.RunCmdPassThrough("flatpak", "remote-add", "--if-not-exists", scope, remote, *url)
+		if err != nil {
+			return fmt.Errorf("failed to add flatpak remote %s: %w", remote, err)
+		}
+		return nil
+	})
+}
+
+// CheckNeedsUpdate implements IInstaller.
+func (i *FlatpakInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	out, err := i.RunCmdGetOutput("flatpak", "remote-ls", "--updates", i.scopeFlag(), "--columns=application")
+	if err != nil {
+		return false, err
+	}
+	return listsPackage(string(out), *i.Info.Name), nil
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *FlatpakInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	return i.RunCmdGetSuccess("flatpak", "info", i.scopeFlag(), *i.Info.Name)
+}
+
+// listsPackage returns whether any line of a command's tabular output starts with the given
+// package name.
+func listsPackage(output string, name string) bool {
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetData implements IInstaller.
+func (i *FlatpakInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the FlatpakInstaller.
+func (i *FlatpakInstaller) GetOpts() *FlatpakOpts {
+	opts := &FlatpakOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if remote, ok := (*info.Opts)["remote"].(string); ok {
+			opts.Remote = &remote
+		}
+		if remoteURL, ok := (*info.Opts)["remote_url"].(string); ok {
+			opts.RemoteURL = &remoteURL
+		}
+		if scope, ok := (*info.Opts)["scope"].(string); ok {
+			opts.Scope = &scope
+		}
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// GetRemote returns the remote to install the application from.
+func (i *FlatpakInstaller) GetRemote() string {
+	if remote := i.GetOpts().Remote; remote != nil && len(*remote) > 0 {
+		return *remote
+	}
+	return flatpakDefaultRemote
+}
+
+// GetScope returns the installation to use, "user" or "system".
+func (i *FlatpakInstaller) GetScope() string {
+	if scope := i.GetOpts().Scope; scope != nil && len(*scope) > 0 {
+		return *scope
+	}
+	return "system"
+}
+
+// NewFlatpakInstaller creates a new FlatpakInstaller.
+func NewFlatpakInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *FlatpakInstaller {
+	i := &FlatpakInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
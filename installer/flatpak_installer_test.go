@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFlatpakInstaller(data *appconfig.InstallerData) *FlatpakInstaller {
+	return NewFlatpakInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestFlatpakValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	valid := newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+		Opts: &map[string]any{"scope": "user"},
+	})
+	assertNoValidationErrors(t, valid.Validate())
+
+	invalid := newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+		Opts: &map[string]any{"scope": "global"},
+	})
+	errors := invalid.Validate()
+	require.Len(t, errors, 1)
+	assert.Equal(t, "scope", errors[0].FieldName)
+}
+
+func TestFlatpakGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+	})
+	assert.Equal(t, "flathub", installer.GetRemote())
+	assert.Equal(t, "system", installer.GetScope())
+
+	installer = newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+		Opts: &map[string]any{
+			"remote":        "fedora",
+			"remote_url":    "oci+https://registry.fedoraproject.org",
+			"scope":         "user",
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts := installer.GetOpts()
+	assert.Equal(t, "oci+https://registry.fedoraproject.org", *opts.RemoteURL)
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+	assert.Equal(t, "fedora", installer.GetRemote())
+	assert.Equal(t, "user", installer.GetScope())
+}
+
+func TestFlatpakArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+	})
+	assert.Equal(t,
+		[]string{"install", "--noninteractive", "-y", "--system", "flathub", "org.mozilla.firefox"},
+		installer.installArgs(),
+	)
+	assert.Equal(t,
+		[]string{"update", "--noninteractive", "-y", "--system", "org.mozilla.firefox"},
+		installer.updateArgs(),
+	)
+
+	installer = newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name:    lo.ToPtr("org.mozilla.firefox"),
+		Type:    appconfig.InstallerTypeFlatpak,
+		Verbose: lo.ToPtr(true),
+		Opts:    &map[string]any{"remote": "fedora", "scope": "user", "flags": "--no-related"},
+	})
+	assert.Equal(t,
+		[]string{"install", "--noninteractive", "-y", "--user", "--verbose", "--no-related", "fedora", "org.mozilla.firefox"},
+		installer.installArgs(),
+	)
+	assert.Equal(t,
+		[]string{"update", "--noninteractive", "-y", "--user", "--verbose", "--no-related", "org.mozilla.firefox"},
+		installer.updateArgs(),
+	)
+}
+
+func TestFlatpakEnsureRemote(t *testing.T) {
+	logger.InitLogger(false)
+	ResetRepoUpdateTracker()
+	defer ResetRepoUpdateTracker()
+
+	// Remotes without a URL are expected to exist already.
+	installer := newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+		Opts: &map[string]any{"remote": "fedora"},
+	})
+	assert.NoError(t, installer.ensureRemote())
+	assert.False(t, IsRepoUpdated("flatpak-remote:--system:fedora"))
+
+	// Remotes are added once per run.
+	MarkRepoUpdated("flatpak-remote:--user:flathub")
+	installer = newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("org.mozilla.firefox"),
+		Type: appconfig.InstallerTypeFlatpak,
+		Opts: &map[string]any{"scope": "user"},
+	})
+	assert.NoError(t, installer.ensureRemote())
+}
+
+func TestListsPackage(t *testing.T) {
+	flatpakOutput := "org.mozilla.firefox\norg.gnome.Platform\n"
+	assert.True(t, listsPackage(flatpakOutput, "org.mozilla.firefox"))
+	assert.False(t, listsPackage(flatpakOutput, "org.mozilla"))
+
+	snapOutput := `Name     Version  Rev   Size   Publisher   Notes
+firefox  125.0    4173  262MB  mozilla✓    -
+`
+	assert.True(t, listsPackage(snapOutput, "firefox"))
+	assert.False(t, listsPackage(snapOutput, "code"))
+	assert.False(t, listsPackage("All snaps up to date.\n", "firefox"))
+}
+
+func TestFlatpakCheckIsInstalled(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestFlatpakInstaller(&appconfig.InstallerData{
+		Name:           lo.ToPtr("org.mozilla.firefox"),
+		Type:           appconfig.InstallerTypeFlatpak,
+		CheckInstalled: lo.ToPtr("true"),
+	})
+	result, err := installer.CheckIsInstalled()
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
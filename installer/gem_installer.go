@@ -0,0 +1,274 @@
+package installer
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/utils"
+)
+
+// GemInstaller is an installer for RubyGems packages.
+type GemInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// GemOpts represents options for the GemInstaller.
+type GemOpts struct {
+	// GemBin is the gem binary to use, e.g. a shim of an rbenv or asdf managed ruby. Defaults to
+	// "gem" from the PATH.
+	GemBin *string `json:"gem_bin"       yaml:"gem_bin"`
+	// UserInstall installs the gem into the user's home directory (--user-install flag).
+	UserInstall *bool `json:"user_install"  yaml:"user_install"`
+	// Version is a version constraint for the gem (--version flag), e.g. "~> 2.0".
+	Version *string `json:"version"       yaml:"version"`
+	// Flags is a string of additional flags to pass to the gem command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+var (
+	gemOutdatedMu    sync.Mutex
+	gemOutdatedCache = map[string][]string{}
+)
+
+// Validate validates the installer configuration.
+func (i *GemInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *GemInstaller) Install() error {
+	return i.RunCmdPassThrough(i.GetGemBin(), i.installArgs()...)
+}
+
+// installArgs returns the arguments to install the gem.
+func (i *GemInstaller) installArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("install")
+	if opts.Version != nil {
+		args = append(args, "--version", *opts.Version)
+	}
+	if opts.InstallFlags != nil {
+		args = append(args, strings.Fields(*opts.InstallFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// Update implements IInstaller.
+func (i *GemInstaller) Update() error {
+	return i.RunCmdPassThrough(i.GetGemBin(), i.updateArgs()...)
+}
+
+// updateArgs returns the arguments to update the gem. `gem update` ignores version constraints, so
+// gems with a version are updated by installing the newest version that matches it.
+func (i *GemInstaller) updateArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("update")
+	if opts.Version != nil {
+		args = i.command("install")
+		args = append(args, "--version", *opts.Version)
+	}
+	if opts.UpdateFlags != nil {
+		args = append(args, strings.Fields(*opts.UpdateFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// command returns the arguments for a gem command, with the installation options.
+func (i *GemInstaller) command(name string) []string {
+	args := []string{name}
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	if i.IsUserInstall() {
+		args = append(args, "--user-install")
+	}
+	return args
+}
+
+// CheckNeedsUpdate implements IInstaller.
+func (i *GemInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	if version := i.GetOpts().Version; version != nil {
+		return i.checkConstrainedUpdate(*version)
+	}
+	outdated, err := i.outdatedGems()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range outdated {
+		if name == *i.Info.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkConstrainedUpdate returns whether the newest remote version of the gem that matches the
+// version constraint isn't installed. `gem outdated` ignores constraints, and would report a gem
+// pinned below its latest version as outdated forever.
+func (i *GemInstaller) checkConstrainedUpdate(version string) (bool, error) {
+	name := *i.Info.Name
+	out, err := i.RunCmdGetOutput(i.GetGemBin(), "list", "--remote", "--exact", name, "--version", version)
+	if err != nil {
+		return false, err
+	}
+	remote := parseGemListVersions(string(out), name)
+	if len(remote) == 0 {
+		logger.Debug("No remote version of %s matches %s", name, version)
+		return false, nil
+	}
+	out, err = i.RunCmdGetOutput(i.GetGemBin(), "list", "--installed", "--exact", name, "--version", version)
+	if err != nil {
+		return false, err
+	}
+	installed := parseGemListVersions(string(out), name)
+	logger.Debug("Latest %s matching %s: %s, installed: %v", name, version, remote[0], installed)
+	return !slices.Contains(installed, remote[0]), nil
+}
+
+// parseGemListVersions returns the versions of the given gem in the output of `gem list`, which
+// lists each gem as "<name> (<version>, default: <version>, <version> <platform>)", newest first.
+func parseGemListVersions(output string, name string) []string {
+	for line := range strings.Lines(output) {
+		list, ok := strings.CutPrefix(strings.TrimSpace(line), name+" (")
+		if !ok {
+			continue
+		}
+		versions := []string{}
+		for entry := range strings.SplitSeq(strings.TrimSuffix(list, ")"), ",") {
+			entry = strings.TrimPrefix(strings.TrimSpace(entry), "default: ")
+			if fields := strings.Fields(entry); len(fields) > 0 && !slices.Contains(versions, fields[0]) {
+				versions = append(versions, fields[0])
+			}
+		}
+		return versions
+	}
+	return nil
+}
+
+// outdatedGems returns the outdated gems of the installer's gem binary. `gem outdated` is slow, so it
+// runs once per gem binary during the process lifetime.
+func (i *GemInstaller) outdatedGems() ([]string, error) {
+	gemBin := i.GetGemBin()
+	gemOutdatedMu.Lock()
+	defer gemOutdatedMu.Unlock()
+	if outdated, ok := gemOutdatedCache[gemBin]; ok {
+		logger.Debug("Using cached gem outdated list for %s", gemBin)
+		return outdated, nil
+	}
+	out, err := i.RunCmdGetOutput(gemBin, "outdated")
+	if err != nil {
+		return nil, err
+	}
+	outdated := parseGemOutdated(string(out))
+	gemOutdatedCache[gemBin] = outdated
+	return outdated, nil
+}
+
+// parseGemOutdated returns the gem names in the output of `gem outdated`, which lists each
+// outdated gem as "<name> (<installed> < <latest>)".
+func parseGemOutdated(output string) []string {
+	names := []string{}
+	for line := range strings.Lines(output) {
+		name, versions, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && strings.HasPrefix(versions, "(") && strings.Contains(versions, "<") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ResetGemOutdatedCache clears the cached `gem outdated` results. Intended for testing.
+func ResetGemOutdatedCache() {
+	gemOutdatedMu.Lock()
+	defer gemOutdatedMu.Unlock()
+	gemOutdatedCache = map[string][]string{}
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *GemInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	args := []string{"list", "--installed", "--exact", *i.Info.Name}
+	if version := i.GetOpts().Version; version != nil {
+		args = append(args, "--version", *version)
+	}
+	return i.RunCmdGetSuccess(i.GetGemBin(), args...)
+}
+
+// GetData implements IInstaller.
+func (i *GemInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the GemInstaller.
+func (i *GemInstaller) GetOpts() *GemOpts {
+	opts := &GemOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if gemBin, ok := (*info.Opts)["gem_bin"].(string); ok {
+			gemBin = utils.GetRealPath(i.GetData().Environ(), gemBin)
+			opts.GemBin = &gemBin
+		}
+		if userInstall, ok := (*info.Opts)["user_install"].(bool); ok {
+			opts.UserInstall = &userInstall
+		}
+		if version, ok := (*info.Opts)["version"].(string); ok {
+			opts.Version = &version
+		}
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// GetGemBin returns the gem binary to run.
+func (i *GemInstaller) GetGemBin() string {
+	if gemBin := i.GetOpts().GemBin; gemBin != nil && len(*gemBin) > 0 {
+		return *gemBin
+	}
+	return "gem"
+}
+
+// IsUserInstall returns whether the gem is installed into the user's home directory.
+func (i *GemInstaller) IsUserInstall() bool {
+	opts := i.GetOpts()
+	return opts.UserInstall != nil && *opts.UserInstall
+}
+
+// NewGemInstaller creates a new GemInstaller.
+func NewGemInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *GemInstaller {
+	i := &GemInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
@@ -0,0 +1,175 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGemInstaller(data *appconfig.InstallerData) *GemInstaller {
+	return NewGemInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestGemValidation(t *testing.T) {
+	logger.InitLogger(false)
+	installer := newTestGemInstaller(&appconfig.InstallerData{Name: lo.ToPtr("rake"), Type: appconfig.InstallerTypeGem})
+	assertNoValidationErrors(t, installer.Validate())
+}
+
+func TestGemGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestGemInstaller(&appconfig.InstallerData{Name: lo.ToPtr("rake"), Type: appconfig.InstallerTypeGem})
+	assert.Equal(t, "gem", installer.GetGemBin())
+	assert.False(t, installer.IsUserInstall())
+	assert.Nil(t, installer.GetOpts().Version)
+
+	installer = newTestGemInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("rake"),
+		Type: appconfig.InstallerTypeGem,
+		Opts: &map[string]any{
+			"gem_bin":       "/opt/ruby/bin/gem",
+			"user_install":  true,
+			"version":       "~> 13.0",
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts := installer.GetOpts()
+	assert.Equal(t, "/opt/ruby/bin/gem", installer.GetGemBin())
+	assert.True(t, installer.IsUserInstall())
+	assert.Equal(t, "~> 13.0", *opts.Version)
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+}
+
+func TestGemArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestGemInstaller(&appconfig.InstallerData{Name: lo.ToPtr("rake"), Type: appconfig.InstallerTypeGem})
+	assert.Equal(t, []string{"install", "rake"}, installer.installArgs())
+	assert.Equal(t, []string{"update", "rake"}, installer.updateArgs())
+
+	installer = newTestGemInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("rake"),
+		Type: appconfig.InstallerTypeGem,
+		Opts: &map[string]any{"user_install": true, "flags": "--no-document"},
+	})
+	assert.Equal(t, []string{"install", "--user-install", "--no-document", "rake"}, installer.installArgs())
+	assert.Equal(t, []string{"update", "--user-install", "--no-document", "rake"}, installer.updateArgs())
+
+	// Gems with a version constraint are updated by installing the newest matching version.
+	installer = newTestGemInstaller(&appconfig.InstallerData{
+		Name:    lo.ToPtr("rake"),
+		Type:    appconfig.InstallerTypeGem,
+		Verbose: lo.ToPtr(true),
+		Opts:    &map[string]any{"version": "~> 13.0"},
+	})
+	assert.Equal(t, []string{"install", "--verbose", "--version", "~> 13.0", "rake"}, installer.installArgs())
+	assert.Equal(t, []string{"install", "--verbose", "--version", "~> 13.0", "rake"}, installer.updateArgs())
+}
+
+func TestParseGemOutdated(t *testing.T) {
+	output := `rake (13.0.6 < 13.2.1)
+rubocop (1.50.0 < 1.64.1)
+`
+	assert.Equal(t, []string{"rake", "rubocop"}, parseGemOutdated(output))
+	assert.Empty(t, parseGemOutdated(""))
+	assert.Empty(t, parseGemOutdated("WARNING: something happened\n"))
+}
+
+func TestGemCheckNeedsUpdateCachesOutdated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake gem binary")
+	}
+	logger.InitLogger(false)
+	ResetGemOutdatedCache()
+	defer ResetGemOutdatedCache()
+
+	// A fake gem binary that counts how many times `gem outdated` runs.
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "count")
+	gemBin := filepath.Join(dir, "gem")
+	script := "#!/bin/sh\necho run >> " + counter + "\necho 'rake (13.0.6 < 13.2.1)'\n"
+	require.NoError(t, os.WriteFile(gemBin, []byte(script), 0o755))
+
+	for _, tc := range []struct {
+		name     string
+		expected bool
+	}{
+		{"rake", true},
+		{"rubocop", false},
+	} {
+		installer := newTestGemInstaller(&appconfig.InstallerData{
+			Name: lo.ToPtr(tc.name),
+			Type: appconfig.InstallerTypeGem,
+			Opts: &map[string]any{"gem_bin": gemBin},
+		})
+		result, err := installer.CheckNeedsUpdate()
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, result, tc.name)
+	}
+
+	runs, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	assert.Equal(t, "run\n", string(runs))
+}
+
+func TestParseGemListVersions(t *testing.T) {
+	output := `rake (13.2.1, default: 13.0.6)
+rake-compiler (1.2.7)
+nokogiri (1.16.5 x86_64-linux, 1.16.5 arm64-darwin, 1.15.0)
+`
+	assert.Equal(t, []string{"13.2.1", "13.0.6"}, parseGemListVersions(output, "rake"))
+	assert.Equal(t, []string{"1.16.5", "1.15.0"}, parseGemListVersions(output, "nokogiri"))
+	assert.Nil(t, parseGemListVersions(output, "rubocop"))
+	assert.Nil(t, parseGemListVersions("", "rake"))
+}
+
+func TestGemCheckNeedsUpdateWithVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake gem binary")
+	}
+	logger.InitLogger(false)
+
+	for _, tc := range []struct {
+		name      string
+		remote    string
+		installed string
+		expected  bool
+	}{
+		{"newest matching version installed", "rake (2.3.1)", "rake (2.3.1, 2.0.0)", false},
+		{"newer matching version available", "rake (2.3.1)", "rake (2.0.0)", true},
+		{"no matching remote version", "", "rake (2.0.0)", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// A fake gem binary that answers `gem list`, and fails `gem outdated`, which would ignore the
+			// version constraint.
+			gemBin := filepath.Join(t.TempDir(), "gem")
+			script := "#!/bin/sh\ncase \"$*\" in\n" +
+				"*outdated*) exit 1 ;;\n" +
+				"*--remote*'--version ~> 2.0'*) echo '" + tc.remote + "' ;;\n" +
+				"*--installed*'--version ~> 2.0'*) echo '" + tc.installed + "' ;;\n" +
+				"*) exit 1 ;;\nesac\n"
+			require.NoError(t, os.WriteFile(gemBin, []byte(script), 0o755))
+
+			installer := newTestGemInstaller(&appconfig.InstallerData{
+				Name: lo.ToPtr("rake"),
+				Type: appconfig.InstallerTypeGem,
+				Opts: &map[string]any{"gem_bin": gemBin, "version": "~> 2.0"},
+			})
+			result, err := installer.CheckNeedsUpdate()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
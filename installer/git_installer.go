@@ -22,15 +22,15 @@ type GitInstaller struct {
 // GitOpts represents options for the GitInstaller.
 type GitOpts struct {
 	// Destination is the directory where the repository will be cloned.
-	Destination *string
+	Destination *string `json:"destination"   yaml:"destination"`
 	// Ref is the Git reference (branch, tag, or commit) to checkout.
-	Ref *string
+	Ref *string `json:"ref"           yaml:"ref"`
 	// Flags is a string of additional flags to pass to git commands.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only to git clone.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only to git pull.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // Validate validates the installer configuration.
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -30,38 +31,78 @@ type GitHubReleaseInstaller struct {
 
 // GitHubReleaseOpts represents options for the GitHubReleaseInstaller.
 type GitHubReleaseOpts struct {
-	// Repository is the GitHub repository (e.g., "owner/repo").
-	Repository *string
-	// Destination is the directory where the release asset will be installed.
-	Destination *string
+	// Repository is the repository (e.g., "owner/repo"). For GitLab, this is the full project
+	// path, including any subgroups (e.g., "group/subgroup/project").
+	Repository *string `json:"repository"        yaml:"repository"`
+	// Provider is the service hosting the releases (github, gitlab, gitea). Defaults to github.
+	// Forgejo instances, such as Codeberg, use the gitea provider.
+	Provider *GitHubReleaseProvider `json:"provider"          yaml:"provider"`
+	// APIURL is the base URL of the provider's API, for self-hosted instances and GitHub
+	// Enterprise (e.g., "https://github.example.com/api/v3", "https://gitlab.example.com/api/v4",
+	// "https://codeberg.org/api/v1"). Defaults to the provider's public instance.
+	APIURL *string `json:"api_url"           yaml:"api_url"`
 	// DownloadFilename is a platform-specific map of the filename to download from the release.
 	// Supports Go template syntax with variables: {{ .Tag }}, {{ .Version }}, {{ .Arch }}, {{ .ArchAlias }}, {{ .ArchGnu }}, {{ .OS }}.
 	// Legacy placeholders {tag}, {version}, {arch}, {arch_alias}, {arch_gnu}, {os} are deprecated but still supported.
-	DownloadFilename *platform.PlatformMap[string]
-	// Strategy is the installation strategy to use (none, tar, zip, gzip).
-	Strategy *GitHubReleaseInstallStrategy
-	// GithubToken is the GitHub personal access token for authenticated API requests.
+	DownloadFilename *platform.PlatformMap[string] `json:"download_filename" yaml:"download_filename"`
+	// GithubToken is the access token for authenticated API requests and downloads, sent in the
+	// provider's auth header. Despite the name, it is used for all providers.
 	// Supports environment variable expansion (e.g., "$GITHUB_TOKEN" or "${GITHUB_TOKEN}").
-	GithubToken *string
+	GithubToken *string `json:"github_token"      yaml:"github_token"`
+	AssetOpts   `yaml:",inline"`
+}
+
+// GitHubReleaseProvider represents the service hosting the releases of a github-release installer.
+type GitHubReleaseProvider string
+
+// Constants for release providers.
+const (
+	GitHubReleaseProviderGitHub GitHubReleaseProvider = "github" // GitHubReleaseProviderGitHub is GitHub or GitHub Enterprise.
+	GitHubReleaseProviderGitLab GitHubReleaseProvider = "gitlab" // GitHubReleaseProviderGitLab is GitLab, including self-managed instances.
+	GitHubReleaseProviderGitea  GitHubReleaseProvider = "gitea"  // GitHubReleaseProviderGitea is Gitea or Forgejo.
+)
+
+// defaultReleaseAPIURLs are the API base URLs of each provider's public instance.
+var defaultReleaseAPIURLs = map[GitHubReleaseProvider]string{
+	GitHubReleaseProviderGitHub: "https://api.github.com",
+	GitHubReleaseProviderGitLab: "https://gitlab.com/api/v4",
+	GitHubReleaseProviderGitea:  "https://gitea.com/api/v1",
+}
+
+// releaseInfo is the part of a release API response used to download an asset.
+type releaseInfo struct {
+	// Tag is the release's tag name.
+	Tag string
+	// Assets maps asset names to their download URLs.
+	Assets map[string]string
+}
+
+// AssetOpts represents the options for installing a downloaded asset, shared by the
+// github-release and url installers.
+type AssetOpts struct {
+	// Destination is the directory where the asset will be installed.
+	Destination *string `json:"destination"      yaml:"destination"`
+	// Strategy is the installation strategy to use (none, tar, zip, gzip, custom).
+	Strategy *GitHubReleaseInstallStrategy `json:"strategy"         yaml:"strategy"`
 	// ArchiveBinName is the name of the binary file inside the archive (tar/zip).
 	// Use this when the filename inside the archive differs from the desired output bin_name.
 	// Accepts either a string or a per-platform map. Supports Go template syntax with the
 	// usual variables ({{ .Tag }}, {{ .Version }}, {{ .Arch }}, {{ .OS }}, ...).
 	// If not set, falls back to bin_name (or the installer name).
-	ArchiveBinName *platform.PlatformMap[string]
+	ArchiveBinName *platform.PlatformMap[string] `json:"archive_bin_name" yaml:"archive_bin_name"`
 	// ExtractTo, when set, switches the installer to "tree mode": the full archive contents
 	// are extracted to this directory, preserving sibling files (lib/, share/, etc.) that
 	// many toolchains rely on at runtime. Requires strategy 'tar' or 'zip'. When tree mode
 	// is active, Destination and ArchiveBinName are ignored.
-	ExtractTo *string
+	ExtractTo *string `json:"extract_to"       yaml:"extract_to"`
 	// StripComponents drops this many leading path components from each archive entry, the
 	// same way `tar --strip-components=N` does. Useful because release tarballs typically
 	// wrap their contents in a single versioned directory. Only meaningful with ExtractTo.
-	StripComponents *int
+	StripComponents *int `json:"strip_components" yaml:"strip_components"`
 	// BinLinks lists binaries to expose from inside ExtractTo. On unix, each entry becomes
 	// a symlink at Target pointing to Source; on Windows, the file is copied instead (since
 	// symlinks require elevated privileges). Only meaningful with ExtractTo.
-	BinLinks []GitHubReleaseBinLink
+	BinLinks []GitHubReleaseBinLink `json:"bin_links"        yaml:"bin_links"`
 	// ExtractCommand is a user-provided shell command that performs the extraction when
 	// Strategy is "custom". The command is run through Go template substitution with these
 	// extra variables available (in addition to the usual .OS, .Arch, .Tag, ...):
@@ -72,16 +113,16 @@ type GitHubReleaseOpts struct {
 	//   {{ .ArchiveBinName }} - the filename sofmani will copy from ExtractDir to Destination
 	// After the command finishes, sofmani copies ExtractDir/ArchiveBinName to
 	// Destination/BinName, the same way the tar and zip strategies do.
-	ExtractCommand *string
+	ExtractCommand *string `json:"extract_command"  yaml:"extract_command"`
 }
 
 // GitHubReleaseBinLink describes a single binary exposed from a tree-mode install.
 type GitHubReleaseBinLink struct {
 	// Source is the path to the binary inside the extracted tree. If relative, it is
 	// resolved against ExtractTo; absolute paths are also accepted.
-	Source string
+	Source string `json:"source" yaml:"source"`
 	// Target is the absolute path where the symlink (or copied file, on Windows) is placed.
-	Target string
+	Target string `json:"target" yaml:"target"`
 }
 
 // GitHubReleaseInstallStrategy represents the installation strategy for a GitHub release.
@@ -104,6 +145,29 @@ func (i *GitHubReleaseInstaller) Validate() []ValidationError {
 	if opts.Repository == nil || len(*opts.Repository) == 0 {
 		errors = append(errors, ValidationError{FieldName: "repository", Message: validationIsRequired(), InstallerName: *info.Name})
 	}
+	if opts.DownloadFilename == nil {
+		errors = append(errors, ValidationError{FieldName: "download_filename", Message: validationIsRequired(), InstallerName: *info.Name})
+	} else if info.Platforms.GetShouldRunOnOS(platform.GetPlatform()) && (opts.DownloadFilename.Resolve() == nil || len(*opts.DownloadFilename.Resolve()) == 0) {
+		errors = append(errors, ValidationError{FieldName: fmt.Sprintf("download_filename.%s", platform.GetPlatform()), Message: validationIsRequired(), InstallerName: *info.Name})
+	}
+	errors = append(errors, i.validatePlatformMapOpt("download_filename")...)
+	if opts.Provider != nil {
+		if _, ok := defaultReleaseAPIURLs[*opts.Provider]; !ok {
+			errors = append(errors, ValidationError{FieldName: "provider", Message: validationInvalidFormat(), InstallerName: *info.Name})
+		}
+	}
+	if opts.APIURL != nil && !isHTTPURL(*opts.APIURL) {
+		errors = append(errors, ValidationError{FieldName: "api_url", Message: validationInvalidFormat(), InstallerName: *info.Name})
+	}
+	errors = append(errors, i.validateAsset()...)
+	return errors
+}
+
+// validateAsset validates the options for installing the downloaded asset.
+func (i *GitHubReleaseInstaller) validateAsset() []ValidationError {
+	errors := []ValidationError{}
+	info := i.GetData()
+	opts := i.GetOpts()
 	// In tree mode (extract_to set), destination is not required — bin_links handle
 	// surfacing binaries on $PATH instead.
 	if opts.ExtractTo == nil {
@@ -111,11 +175,6 @@ func (i *GitHubReleaseInstaller) Validate() []ValidationError {
 			errors = append(errors, ValidationError{FieldName: "destination", Message: validationIsRequired(), InstallerName: *info.Name})
 		}
 	}
-	if opts.DownloadFilename == nil {
-		errors = append(errors, ValidationError{FieldName: "download_filename", Message: validationIsRequired(), InstallerName: *info.Name})
-	} else if info.Platforms.GetShouldRunOnOS(platform.GetPlatform()) && (opts.DownloadFilename.Resolve() == nil || len(*opts.DownloadFilename.Resolve()) == 0) {
-		errors = append(errors, ValidationError{FieldName: fmt.Sprintf("download_filename.%s", platform.GetPlatform()), Message: validationIsRequired(), InstallerName: *info.Name})
-	}
 	if opts.Strategy != nil {
 		switch *opts.Strategy {
 		case GitHubReleaseInstallStrategyNone,
@@ -167,94 +226,62 @@ func (i *GitHubReleaseInstaller) Validate() []ValidationError {
 			}
 		}
 	}
+	errors = append(errors, i.validatePlatformMapOpt("archive_bin_name")...)
 	return errors
 }
 
 // Install implements IInstaller.
 func (i *GitHubReleaseInstaller) Install() error {
-	opts := i.GetOpts()
-	if opts.ExtractTo != nil {
-		return i.installTree()
-	}
-	data := i.GetData()
-	name := *data.Name
+	name := *i.GetData().Name
 	tmpDir, err := os.MkdirTemp("", "sofmani")
 	if err != nil {
 		return fmt.Errorf("failed to create temp directory: %w", err)
 	}
-	tmpFile := fmt.Sprintf("%s/%s.download", tmpDir, name)
-	logger.Debug("Created temp directory: %s", tmpDir)
-	tmpOut, err := os.Create(tmpFile)
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file %s: %w", tmpFile, err)
-	}
 	defer func() {
-		if cerr := tmpOut.Close(); cerr != nil {
-			logger.Warn("failed to close tmpOut file: %v", cerr)
+		if rerr := os.RemoveAll(tmpDir); rerr != nil {
+			logger.Warn("failed to remove temp dir %s: %v", tmpDir, rerr)
 		}
 	}()
+	logger.Debug("Created temp directory: %s", tmpDir)
 
-	err = os.MkdirAll(*opts.Destination, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create destination directory %s: %w", *opts.Destination, err)
-	}
-	// defer os.RemoveAll(tmpDir)
-
-	tag, err := i.GetLatestTag()
+	tmpFile, templateVars, err := i.downloadRelease(tmpDir, name)
 	if err != nil {
 		return err
 	}
-
-	filename := i.GetFilename()
-	if filename == "" {
-		return fmt.Errorf("no download filename matched for the current platform (%s/%s)", runtime.GOOS, runtime.GOARCH)
-	}
-	var machineAliases map[string]string
-	if i.Config.MachineAliases != nil {
-		machineAliases = *i.Config.MachineAliases
-	}
-	templateVars := NewTemplateVars(tag, machineAliases)
-	rawFilename := filename
-	filename, err = ApplyTemplate(filename, templateVars, name)
-	if err != nil {
-		return fmt.Errorf("failed to apply template to download_filename %q: %w", rawFilename, err)
+	if err := i.installAsset(tmpDir, tmpFile, templateVars); err != nil {
+		return err
 	}
-	downloadUrl := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", *opts.Repository, tag, filename)
-	logger.Debug("Downloading file: %s", filename)
-	logger.Debug("Download URL: %s", downloadUrl)
-	logger.Debug("Temp file: %s", tmpFile)
+	return i.UpdateCache(templateVars.Tag)
+}
 
-	req, err := http.NewRequest("GET", downloadUrl, nil)
-	if err != nil {
-		return fmt.Errorf("failed to build request for %s: %w", downloadUrl, err)
-	}
-	if opts.GithubToken != nil && *opts.GithubToken != "" {
-		logger.Debug("Using GitHub token for authentication")
-		req.Header.Set("Authorization", "Bearer "+*opts.GithubToken)
+// installAsset installs an asset downloaded to tmpFile, either as a single binary in the
+// destination or, in tree mode, as an extracted tree. templateVars renders the templates in the
+// asset options.
+func (i *GitHubReleaseInstaller) installAsset(tmpDir, tmpFile string, templateVars *TemplateVars) error {
+	if i.GetOpts().ExtractTo != nil {
+		return i.installTree(tmpFile)
 	}
+	return i.installFile(tmpDir, tmpFile, templateVars)
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// installFile installs the binary from an asset downloaded to tmpFile into the destination,
+// extracting it according to the strategy. tmpDir is used as the extraction directory.
+func (i *GitHubReleaseInstaller) installFile(tmpDir, tmpFile string, templateVars *TemplateVars) error {
+	opts := i.GetOpts()
+	tmpOut, err := os.Open(tmpFile)
 	if err != nil {
-		return fmt.Errorf("failed to download release asset from %s: %w", downloadUrl, err)
+		return fmt.Errorf("failed to open downloaded file %s: %w", tmpFile, err)
 	}
 	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			logger.Warn("failed to close response body: %v", cerr)
+		if cerr := tmpOut.Close(); cerr != nil {
+			logger.Warn("failed to close tmpOut file: %v", cerr)
 		}
 	}()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to download release asset: %s returned status %d", downloadUrl, resp.StatusCode)
-	}
-
-	n, err := io.Copy(tmpOut, resp.Body)
+	err = os.MkdirAll(*opts.Destination, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to write downloaded asset from %s to %s: %w", downloadUrl, tmpFile, err)
-	}
-	if n == 0 {
-		return fmt.Errorf("no data was written to %s from %s", tmpFile, downloadUrl)
+		return fmt.Errorf("failed to create destination directory %s: %w", *opts.Destination, err)
 	}
-	logger.Debug("Downloaded %d bytes to temp file", n)
 
 	strategy := GitHubReleaseInstallStrategyNone
 
@@ -382,12 +409,7 @@ func (i *GitHubReleaseInstaller) Install() error {
 	}
 	logger.Debug("Set executable permissions on %s", outPath)
 
-	err = i.UpdateCache(tag)
-	if err != nil {
-		return err
-	}
-
-	logger.Debug("Installation complete: %s -> %s", filename, outPath)
+	logger.Debug("Installation complete: %s -> %s", tmpFile, outPath)
 	return nil
 }
 
@@ -401,6 +423,12 @@ func (i *GitHubReleaseInstaller) CheckIsInstalled() (bool, error) {
 	if i.HasCustomInstallCheck() {
 		return i.RunCustomInstallCheck()
 	}
+	return i.assetInstalled()
+}
+
+// assetInstalled returns whether the installed asset exists: the binary in the destination, or
+// the extracted tree and its bin links in tree mode.
+func (i *GitHubReleaseInstaller) assetInstalled() (bool, error) {
 	opts := i.GetOpts()
 	if opts.ExtractTo != nil {
 		// Tree mode: the install is present iff the extracted tree exists AND every
@@ -672,8 +700,17 @@ func (i *GitHubReleaseInstaller) GetOpts() *GitHubReleaseOpts {
 			destination = utils.GetRealPath(i.GetData().Environ(), destination)
 			opts.Destination = &destination
 		}
+		if provider, ok := (*info.Opts)["provider"].(string); ok {
+			p := GitHubReleaseProvider(strings.ToLower(provider))
+			opts.Provider = &p
+		}
+		if apiURL, ok := (*info.Opts)["api_url"].(string); ok {
+			apiURL = utils.GetRealPath(i.GetData().Environ(), apiURL)
+			opts.APIURL = &apiURL
+		}
 		if filename, ok := (*info.Opts)["download_filename"]; ok {
-			opts.DownloadFilename = platform.NewPlatformMap[string](filename)
+			// Unknown platform keys are reported by Validate.
+			opts.DownloadFilename, _ = platform.NewPlatformMap[string](filename)
 		}
 		if strategy, ok := (*info.Opts)["strategy"].(string); ok {
 			strat := GitHubReleaseInstallStrategy(strings.ToLower(strategy))
@@ -688,7 +725,7 @@ func (i *GitHubReleaseInstaller) GetOpts() *GitHubReleaseOpts {
 			opts.GithubToken = &token
 		}
 		if raw, ok := (*info.Opts)["archive_bin_name"]; ok {
-			opts.ArchiveBinName = platform.NewPlatformMap[string](raw)
+			opts.ArchiveBinName, _ = platform.NewPlatformMap[string](raw)
 		}
 		if extractCommand, ok := (*info.Opts)["extract_command"].(string); ok {
 			opts.ExtractCommand = &extractCommand
@@ -756,22 +793,85 @@ func parseBinLinkEntry(entry any, env []string) (GitHubReleaseBinLink, bool) {
 	return link, true
 }
 
+// GetLatestTag returns the tag of the repository's latest release.
 func (i *GitHubReleaseInstaller) GetLatestTag() (string, error) {
+	release, err := i.latestRelease()
+	if err != nil {
+		return "", err
+	}
+	return release.Tag, nil
+}
+
+// GetProvider returns the service hosting the releases, defaulting to GitHub.
+func (i *GitHubReleaseInstaller) GetProvider() GitHubReleaseProvider {
 	opts := i.GetOpts()
-	latestReleaseUrl := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", *opts.Repository)
+	if opts.Provider != nil && *opts.Provider != "" {
+		return *opts.Provider
+	}
+	return GitHubReleaseProviderGitHub
+}
+
+// GetAPIURL returns the base URL of the provider's API, without a trailing slash.
+func (i *GitHubReleaseInstaller) GetAPIURL() string {
+	opts := i.GetOpts()
+	if opts.APIURL != nil && *opts.APIURL != "" {
+		return strings.TrimRight(*opts.APIURL, "/")
+	}
+	return defaultReleaseAPIURLs[i.GetProvider()]
+}
+
+// latestReleaseURL returns the API endpoint of the repository's latest release.
+func (i *GitHubReleaseInstaller) latestReleaseURL() string {
+	repository := *i.GetOpts().Repository
+	if i.GetProvider() == GitHubReleaseProviderGitLab {
+		// GitLab identifies projects by their URL-encoded path.
+		return fmt.Sprintf("%s/projects/%s/releases/permalink/latest", i.GetAPIURL(), url.PathEscape(repository))
+	}
+	return fmt.Sprintf("%s/repos/%s/releases/latest", i.GetAPIURL(), repository)
+}
+
+// authorize sets the provider's auth header on req when a token is configured.
+func (i *GitHubReleaseInstaller) authorize(req *http.Request) {
+	opts := i.GetOpts()
+	if opts.GithubToken == nil || *opts.GithubToken == "" {
+		return
+	}
+	switch i.GetProvider() {
+	case GitHubReleaseProviderGitLab:
+		req.Header.Set("PRIVATE-TOKEN", *opts.GithubToken)
+	case GitHubReleaseProviderGitea:
+		req.Header.Set("Authorization", "token "+*opts.GithubToken)
+	default:
+		req.Header.Set("Authorization", "Bearer "+*opts.GithubToken)
+	}
+}
+
+// isProviderHost returns whether u is on the provider's host, which may receive the token.
+// Assets on github.com are always downloaded with the token, as before; assets linked from other
+// providers may be hosted anywhere and must not receive it.
+func (i *GitHubReleaseInstaller) isProviderHost(u *url.URL) bool {
+	if i.GetProvider() == GitHubReleaseProviderGitHub && u.Host == "github.com" {
+		return true
+	}
+	api, err := url.Parse(i.GetAPIURL())
+	return err == nil && strings.EqualFold(api.Host, u.Host)
+}
+
+// latestRelease fetches the repository's latest release from the provider's API.
+func (i *GitHubReleaseInstaller) latestRelease() (*releaseInfo, error) {
+	opts := i.GetOpts()
+	latestReleaseUrl := i.latestReleaseURL()
 	logger.Debug("Getting latest release from %s", latestReleaseUrl)
 
 	req, err := http.NewRequest("GET", latestReleaseUrl, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to build request for %s: %w", latestReleaseUrl, err)
-	}
-	if opts.GithubToken != nil && *opts.GithubToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*opts.GithubToken)
+		return nil, fmt.Errorf("failed to build request for %s: %w", latestReleaseUrl, err)
 	}
+	i.authorize(req)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch latest release from %s: %w", latestReleaseUrl, err)
+		return nil, fmt.Errorf("failed to fetch latest release from %s: %w", latestReleaseUrl, err)
 	}
 	defer func() {
 		err := resp.Body.Close()
@@ -781,23 +881,82 @@ func (i *GitHubReleaseInstaller) GetLatestTag() (string, error) {
 	}()
 	contents, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response from %s: %w", latestReleaseUrl, err)
+		return nil, fmt.Errorf("failed to read response from %s: %w", latestReleaseUrl, err)
 	}
-	jsonMap := make(map[string]any)
-	err = json.Unmarshal(contents, &jsonMap)
+	release, err := parseRelease(i.GetProvider(), contents)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse JSON response from %s: %w", latestReleaseUrl, err)
+		return nil, fmt.Errorf("failed to parse JSON response from %s: %w", latestReleaseUrl, err)
+	}
+	if release.Tag == "" {
+		logger.Warn("Invalid %s API response from %s: %s", i.GetProvider(), latestReleaseUrl, string(contents))
+		jsonMap := make(map[string]any)
+		if json.Unmarshal(contents, &jsonMap) == nil {
+			if msg, ok := jsonMap["message"].(string); ok {
+				return nil, fmt.Errorf("%s API error for %s: %s", i.GetProvider(), latestReleaseUrl, msg)
+			}
+		}
+		return nil, fmt.Errorf("no releases found for repository %s (queried %s)", *opts.Repository, latestReleaseUrl)
 	}
-	tag, ok := jsonMap["tag_name"].(string)
-	if !ok || tag == "" {
-		logger.Warn("Invalid GitHub API response from %s: %s", latestReleaseUrl, string(contents))
-		if msg, ok := jsonMap["message"].(string); ok {
-			return "", fmt.Errorf("GitHub API error for %s: %s", latestReleaseUrl, msg)
+	logger.Debug("Latest release is %s", release.Tag)
+	return release, nil
+}
+
+// parseRelease parses a release API response. GitHub and Gitea list the assets with their
+// browser_download_url, GitLab lists them as links with a url and a permanent direct_asset_url.
+func parseRelease(provider GitHubReleaseProvider, contents []byte) (*releaseInfo, error) {
+	release := &releaseInfo{Assets: map[string]string{}}
+	if provider == GitHubReleaseProviderGitLab {
+		response := struct {
+			TagName string `json:"tag_name"`
+			Assets  struct {
+				Links []struct {
+					Name           string `json:"name"`
+					URL            string `json:"url"`
+					DirectAssetURL string `json:"direct_asset_url"`
+				} `json:"links"`
+			} `json:"assets"`
+		}{}
+		if err := json.Unmarshal(contents, &response); err != nil {
+			return nil, err
+		}
+		release.Tag = response.TagName
+		for _, link := range response.Assets.Links {
+			release.Assets[link.Name] = link.URL
+			if link.DirectAssetURL != "" {
+				release.Assets[link.Name] = link.DirectAssetURL
+			}
 		}
-		return "", fmt.Errorf("no releases found for repository %s (queried %s)", *opts.Repository, latestReleaseUrl)
+		return release, nil
 	}
-	logger.Debug("Latest release is %s", tag)
-	return tag, nil
+	response := struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		} `json:"assets"`
+	}{}
+	if err := json.Unmarshal(contents, &response); err != nil {
+		return nil, err
+	}
+	release.Tag = response.TagName
+	for _, asset := range response.Assets {
+		release.Assets[asset.Name] = asset.BrowserDownloadURL
+	}
+	return release, nil
+}
+
+// assetURL returns the download URL of the named asset of release. Releases on github.com are
+// downloaded from their well-known URL, other providers and instances are looked up in the
+// release's assets.
+func (i *GitHubReleaseInstaller) assetURL(release *releaseInfo, filename string) (string, error) {
+	if i.GetProvider() == GitHubReleaseProviderGitHub && i.GetAPIURL() == defaultReleaseAPIURLs[GitHubReleaseProviderGitHub] {
+		return fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", *i.GetOpts().Repository, release.Tag, filename), nil
+	}
+	assetUrl, ok := release.Assets[filename]
+	if !ok || assetUrl == "" {
+		return "", fmt.Errorf("release %s of %s has no asset named %s", release.Tag, *i.GetOpts().Repository, filename)
+	}
+	return assetUrl, nil
 }
 
 // GetFilename returns the filename to download from the release, resolved for the current platform.
@@ -834,15 +993,13 @@ func (i *GitHubReleaseInstaller) GetInstallDir() string {
 	return i.GetDestination()
 }
 
-// installTree handles "tree mode" installs where the full archive contents are extracted
-// into opts.ExtractTo and individual binaries are exposed via opts.BinLinks. The extracted
+// installTree handles "tree mode" installs where the full contents of the archive at tmpFile
+// are extracted into opts.ExtractTo and individual binaries are exposed via opts.BinLinks. The extracted
 // tree is swapped into place atomically so an interrupted or failed install cannot leave
 // a half-written directory behind, and a successful update fully replaces the previous
 // version (no stale files from an old release linger).
-func (i *GitHubReleaseInstaller) installTree() error {
+func (i *GitHubReleaseInstaller) installTree(tmpFile string) error {
 	opts := i.GetOpts()
-	data := i.GetData()
-	name := *data.Name
 
 	strategy := GitHubReleaseInstallStrategyNone
 	if opts.Strategy != nil {
@@ -852,21 +1009,6 @@ func (i *GitHubReleaseInstaller) installTree() error {
 		return fmt.Errorf("extract_to requires strategy 'tar' or 'zip', got %q", strategy)
 	}
 
-	tmpDir, err := os.MkdirTemp("", "sofmani")
-	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer func() {
-		if rerr := os.RemoveAll(tmpDir); rerr != nil {
-			logger.Warn("failed to remove temp dir %s: %v", tmpDir, rerr)
-		}
-	}()
-
-	tmpFile, tag, err := i.downloadRelease(tmpDir, name)
-	if err != nil {
-		return err
-	}
-
 	extractTo := *opts.ExtractTo
 	stripComponents := 0
 	if opts.StripComponents != nil {
@@ -956,43 +1098,72 @@ func (i *GitHubReleaseInstaller) installTree() error {
 		logger.Debug("Installed bin link %s -> %s", sourcePath, link.Target)
 	}
 
-	if err := i.UpdateCache(tag); err != nil {
-		return err
-	}
 	logger.Debug("Tree install complete: %s", extractTo)
 	return nil
 }
 
 // downloadRelease downloads the configured release asset to tmpDir and returns the on-disk
-// path plus the resolved tag. It encapsulates the tag lookup, template application, HTTP
-// fetch, and file write so both single-file and tree-mode installs can share it.
-func (i *GitHubReleaseInstaller) downloadRelease(tmpDir, name string) (string, string, error) {
+// path plus the template variables of the resolved tag. It encapsulates the tag lookup,
+// template application, and download so both single-file and tree-mode installs can share it.
+func (i *GitHubReleaseInstaller) downloadRelease(tmpDir, name string) (string, *TemplateVars, error) {
 	opts := i.GetOpts()
 
-	tag, err := i.GetLatestTag()
+	release, err := i.latestRelease()
 	if err != nil {
-		return "", "", err
+		return "", nil, err
 	}
+	tag := release.Tag
 
 	filename := i.GetFilename()
 	if filename == "" {
-		return "", "", fmt.Errorf("no download filename provided")
-	}
-	var machineAliases map[string]string
-	if i.Config != nil && i.Config.MachineAliases != nil {
-		machineAliases = *i.Config.MachineAliases
+		return "", nil, fmt.Errorf("no download filename matched for the current platform (%s/%s)", runtime.GOOS, runtime.GOARCH)
 	}
-	templateVars := NewTemplateVars(tag, machineAliases)
+	templateVars := NewTemplateVars(tag, i.machineAliases())
 	rawFilename := filename
 	filename, err = ApplyTemplate(filename, templateVars, name)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to apply template to download_filename %q: %w", rawFilename, err)
+		return "", nil, fmt.Errorf("failed to apply template to download_filename %q: %w", rawFilename, err)
+	}
+
+	downloadUrl, err := i.assetURL(release, filename)
+	if err != nil {
+		return "", nil, err
+	}
+	logger.Debug("Downloading file: %s", filename)
+	req, err := http.NewRequest("GET", downloadUrl, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build request for %s: %w", downloadUrl, err)
+	}
+	if opts.GithubToken != nil && *opts.GithubToken != "" && i.isProviderHost(req.URL) {
+		logger.Debug("Using %s token for authentication", i.GetProvider())
+		i.authorize(req)
 	}
 
 	tmpFile := filepath.Join(tmpDir, name+".download")
-	out, err := os.Create(tmpFile)
+	if _, err := downloadAsset(req, tmpFile); err != nil {
+		return "", nil, err
+	}
+	return tmpFile, templateVars, nil
+}
+
+// machineAliases returns the configured machine aliases, used to render templates.
+func (i *GitHubReleaseInstaller) machineAliases() map[string]string {
+	if i.Config != nil && i.Config.MachineAliases != nil {
+		return *i.Config.MachineAliases
+	}
+	return nil
+}
+
+// downloadAsset runs req and writes the response body to path, returning the response headers.
+// It fails on non-2xx responses and empty bodies.
+func downloadAsset(req *http.Request, path string) (http.Header, error) {
+	downloadUrl := req.URL.String()
+	logger.Debug("Download URL: %s", downloadUrl)
+	logger.Debug("Temp file: %s", path)
+
+	out, err := os.Create(path)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create temporary file %s: %w", tmpFile, err)
+		return nil, fmt.Errorf("failed to create temporary file %s: %w", path, err)
 	}
 	defer func() {
 		if cerr := out.Close(); cerr != nil {
@@ -1000,23 +1171,9 @@ func (i *GitHubReleaseInstaller) downloadRelease(tmpDir, name string) (string, s
 		}
 	}()
 
-	downloadUrl := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", *opts.Repository, tag, filename)
-	logger.Debug("Downloading file: %s", filename)
-	logger.Debug("Download URL: %s", downloadUrl)
-	logger.Debug("Temp file: %s", tmpFile)
-
-	req, err := http.NewRequest("GET", downloadUrl, nil)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to build request for %s: %w", downloadUrl, err)
-	}
-	if opts.GithubToken != nil && *opts.GithubToken != "" {
-		logger.Debug("Using GitHub token for authentication")
-		req.Header.Set("Authorization", "Bearer "+*opts.GithubToken)
-	}
-
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to download release asset from %s: %w", downloadUrl, err)
+		return nil, fmt.Errorf("failed to download asset from %s: %w", downloadUrl, err)
 	}
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil {
@@ -1025,18 +1182,18 @@ func (i *GitHubReleaseInstaller) downloadRelease(tmpDir, name string) (string, s
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", "", fmt.Errorf("failed to download release asset: %s returned status %d", downloadUrl, resp.StatusCode)
+		return nil, fmt.Errorf("failed to download asset: %s returned status %d", downloadUrl, resp.StatusCode)
 	}
 
 	n, err := io.Copy(out, resp.Body)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to write downloaded asset from %s to %s: %w", downloadUrl, tmpFile, err)
+		return nil, fmt.Errorf("failed to write downloaded asset from %s to %s: %w", downloadUrl, path, err)
 	}
 	if n == 0 {
-		return "", "", fmt.Errorf("no data was written to %s from %s", tmpFile, downloadUrl)
+		return nil, fmt.Errorf("no data was written to %s from %s", path, downloadUrl)
 	}
 	logger.Debug("Downloaded %d bytes to temp file", n)
-	return tmpFile, tag, nil
+	return resp.Header, nil
 }
 
 // extractZipWithStrip extracts a zip archive into dest, dropping the first `strip` leading
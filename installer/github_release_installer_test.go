@@ -5,9 +5,12 @@ import (
 	"bytes"
 	"compress/gzip"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/chenasraf/sofmani/appconfig"
@@ -111,6 +114,42 @@ func TestGitHubReleaseValidation(t *testing.T) {
 	}
 	assertValidationError(t, newTestGitHubReleaseInstaller(missingCurrentPlatform).Validate(), fmt.Sprintf("download_filename.%s", platform.GetPlatform()))
 
+	// 🟢 download_filename with an architecture alias key
+	archAliasKey := &appconfig.InstallerData{
+		Name: lo.ToPtr("ghr-arch-alias-key"),
+		Type: appconfig.InstallerTypeGitHubRelease,
+		Opts: &map[string]any{
+			"repository":  "owner/repo",
+			"destination": "/some/path",
+			"download_filename": map[any]any{
+				"macos":        "file-macos.tar.gz",
+				"linux":        "file-linux.tar.gz",
+				"windows":      "file-windows.zip",
+				"linux/x86_64": "file-linux-x86_64.tar.gz",
+			},
+		},
+	}
+	assertNoValidationErrors(t, newTestGitHubReleaseInstaller(archAliasKey).Validate())
+
+	// 🔴 download_filename and archive_bin_name with unknown platform keys
+	unknownPlatformKey := &appconfig.InstallerData{
+		Name: lo.ToPtr("ghr-unknown-platform-key"),
+		Type: appconfig.InstallerTypeGitHubRelease,
+		Opts: &map[string]any{
+			"repository":  "owner/repo",
+			"destination": "/some/path",
+			"download_filename": map[any]any{
+				"macos":         "file-macos.tar.gz",
+				"linux":         "file-linux.tar.gz",
+				"windows":       "file-windows.zip",
+				"linux/riscv64": "file-riscv64.tar.gz",
+			},
+			"archive_bin_name": map[any]any{"freebsd": "tool"},
+		},
+	}
+	assertValidationError(t, newTestGitHubReleaseInstaller(unknownPlatformKey).Validate(), "download_filename")
+	assertValidationError(t, newTestGitHubReleaseInstaller(unknownPlatformKey).Validate(), "archive_bin_name")
+
 	// 🔴 Invalid strategy
 	invalidStrategy := &appconfig.InstallerData{
 		Name: lo.ToPtr("ghr-invalid-strategy"),
@@ -1164,6 +1203,241 @@ func TestGitHubReleaseTreeInstallAtomicReplace(t *testing.T) {
 	assert.Equal(t, "v2", string(linked))
 }
 
+func TestGitHubReleaseProviderValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	opts := func(extra map[string]any) *map[string]any {
+		m := map[string]any{
+			"repository":        "group/project",
+			"destination":       "/some/path",
+			"download_filename": "file",
+		}
+		for k, v := range extra {
+			m[k] = v
+		}
+		return &m
+	}
+
+	for _, provider := range []string{"github", "gitlab", "gitea", "GitLab"} {
+		installer := newTestGitHubReleaseInstaller(&appconfig.InstallerData{
+			Name: lo.ToPtr("tool"),
+			Type: appconfig.InstallerTypeGitHubRelease,
+			Opts: opts(map[string]any{"provider": provider, "api_url": "https://git.example.com/api/v4"}),
+		})
+		assertNoValidationErrors(t, installer.Validate())
+	}
+
+	installer := newTestGitHubReleaseInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("tool"),
+		Type: appconfig.InstallerTypeGitHubRelease,
+		Opts: opts(map[string]any{"provider": "bitbucket", "api_url": "git.example.com"}),
+	})
+	errors := installer.Validate()
+	assertValidationError(t, errors, "provider")
+	assertValidationError(t, errors, "api_url")
+}
+
+func TestGitHubReleaseGetAPIURL(t *testing.T) {
+	logger.InitLogger(false)
+
+	for _, tc := range []struct {
+		name     string
+		opts     map[string]any
+		provider GitHubReleaseProvider
+		expected string
+	}{
+		{"defaults to github", map[string]any{}, GitHubReleaseProviderGitHub, "https://api.github.com"},
+		{"gitlab.com", map[string]any{"provider": "gitlab"}, GitHubReleaseProviderGitLab, "https://gitlab.com/api/v4"},
+		{"gitea.com", map[string]any{"provider": "gitea"}, GitHubReleaseProviderGitea, "https://gitea.com/api/v1"},
+		{"github enterprise", map[string]any{"api_url": "https://github.example.com/api/v3/"}, GitHubReleaseProviderGitHub, "https://github.example.com/api/v3"},
+		{"self-managed gitlab", map[string]any{"provider": "gitlab", "api_url": "https://git.example.com/api/v4"}, GitHubReleaseProviderGitLab, "https://git.example.com/api/v4"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			installer := newTestGitHubReleaseInstaller(&appconfig.InstallerData{
+				Name: lo.ToPtr("tool"),
+				Type: appconfig.InstallerTypeGitHubRelease,
+				Opts: &tc.opts,
+			})
+			assert.Equal(t, tc.provider, installer.GetProvider())
+			assert.Equal(t, tc.expected, installer.GetAPIURL())
+		})
+	}
+}
+
+func TestGitHubReleaseLatestReleaseURL(t *testing.T) {
+	logger.InitLogger(false)
+
+	for _, tc := range []struct {
+		provider string
+		expected string
+	}{
+		{"github", "https://api.github.com/repos/group/project/releases/latest"},
+		{"gitlab", "https://gitlab.com/api/v4/projects/group%2Fsub%2Fproject/releases/permalink/latest"},
+		{"gitea", "https://gitea.com/api/v1/repos/group/project/releases/latest"},
+	} {
+		repository := "group/project"
+		if tc.provider == "gitlab" {
+			repository = "group/sub/project"
+		}
+		installer := newTestGitHubReleaseInstaller(&appconfig.InstallerData{
+			Name: lo.ToPtr("tool"),
+			Type: appconfig.InstallerTypeGitHubRelease,
+			Opts: &map[string]any{"provider": tc.provider, "repository": repository},
+		})
+		assert.Equal(t, tc.expected, installer.latestReleaseURL(), tc.provider)
+	}
+}
+
+func TestParseRelease(t *testing.T) {
+	release, err := parseRelease(GitHubReleaseProviderGitHub, []byte(`{
+  "tag_name": "v1.0.0",
+  "assets": [{"name": "tool.tar.gz", "browser_download_url": "https://github.example.com/o/r/releases/download/v1.0.0/tool.tar.gz"}]
+}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.0", release.Tag)
+	assert.Equal(t, map[string]string{"tool.tar.gz": "https://github.example.com/o/r/releases/download/v1.0.0/tool.tar.gz"}, release.Assets)
+
+	release, err = parseRelease(GitHubReleaseProviderGitLab, []byte(`{
+  "tag_name": "v2.0.0",
+  "assets": {
+    "count": 2,
+    "sources": [{"format": "zip", "url": "https://gitlab.com/g/p/-/archive/v2.0.0/p-v2.0.0.zip"}],
+    "links": [
+      {"name": "tool-linux", "url": "https://example.com/tool-linux", "direct_asset_url": "https://gitlab.com/g/p/-/releases/v2.0.0/downloads/tool-linux"},
+      {"name": "tool-macos", "url": "https://example.com/tool-macos"}
+    ]
+  }
+}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0", release.Tag)
+	assert.Equal(t, map[string]string{
+		"tool-linux": "https://gitlab.com/g/p/-/releases/v2.0.0/downloads/tool-linux",
+		"tool-macos": "https://example.com/tool-macos",
+	}, release.Assets)
+
+	release, err = parseRelease(GitHubReleaseProviderGitea, []byte(`{"message": "Not Found"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "", release.Tag)
+
+	_, err = parseRelease(GitHubReleaseProviderGitHub, []byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestGitHubReleaseInstallFromProviders(t *testing.T) {
+	logger.InitLogger(false)
+	useTestCacheDir(t)
+
+	for _, tc := range []struct {
+		provider     string
+		apiPath      string
+		releasePath  string
+		release      string
+		authHeader   string
+		authValue    string
+		externalLink bool
+	}{
+		{
+			provider:    "github",
+			apiPath:     "/api/v3",
+			releasePath: "/api/v3/repos/owner/tool/releases/latest",
+			release:     `{"tag_name": "v1.0.0", "assets": [{"name": "tool-v1.0.0", "browser_download_url": "{{server}}/owner/tool/releases/download/v1.0.0/tool-v1.0.0"}]}`,
+			authHeader:  "Authorization",
+			authValue:   "Bearer secret",
+		},
+		{
+			provider:    "gitlab",
+			apiPath:     "/api/v4",
+			releasePath: "/api/v4/projects/owner/tool/releases/permalink/latest",
+			release:     `{"tag_name": "v1.0.0", "assets": {"links": [{"name": "tool-v1.0.0", "url": "{{server}}/owner/tool/-/releases/v1.0.0/downloads/tool-v1.0.0"}]}}`,
+			authHeader:  "PRIVATE-TOKEN",
+			authValue:   "secret",
+		},
+		{
+			provider:     "gitea",
+			apiPath:      "/api/v1",
+			releasePath:  "/api/v1/repos/owner/tool/releases/latest",
+			release:      `{"tag_name": "v1.0.0", "assets": [{"name": "tool-v1.0.0", "browser_download_url": "{{external}}/tool-v1.0.0"}]}`,
+			authHeader:   "Authorization",
+			authValue:    "token secret",
+			externalLink: true,
+		},
+	} {
+		t.Run(tc.provider, func(t *testing.T) {
+			var assetAuth string
+			external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assetAuth = r.Header.Get(tc.authHeader)
+				_, _ = w.Write([]byte("binary"))
+			}))
+			defer external.Close()
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The gitlab project path arrives decoded in r.URL.Path.
+				if r.URL.Path == tc.releasePath {
+					assert.Equal(t, tc.authValue, r.Header.Get(tc.authHeader))
+					release := strings.ReplaceAll(tc.release, "{{server}}", server.URL)
+					_, _ = w.Write([]byte(strings.ReplaceAll(release, "{{external}}", external.URL)))
+					return
+				}
+				assetAuth = r.Header.Get(tc.authHeader)
+				_, _ = w.Write([]byte("binary"))
+			}))
+			defer server.Close()
+
+			destination := t.TempDir()
+			installer := newTestGitHubReleaseInstaller(&appconfig.InstallerData{
+				Name: lo.ToPtr("provider-tool-" + tc.provider),
+				Type: appconfig.InstallerTypeGitHubRelease,
+				Opts: &map[string]any{
+					"provider":          tc.provider,
+					"api_url":           server.URL + tc.apiPath,
+					"repository":        "owner/tool",
+					"download_filename": "tool-{{ .Tag }}",
+					"destination":       destination,
+					"github_token":      "secret",
+				},
+			})
+			assertNoValidationErrors(t, installer.Validate())
+
+			tag, err := installer.GetLatestTag()
+			assert.NoError(t, err)
+			assert.Equal(t, "v1.0.0", tag)
+
+			assert.NoError(t, installer.Install())
+			contents, err := os.ReadFile(filepath.Join(destination, "provider-tool-"+tc.provider))
+			assert.NoError(t, err)
+			assert.Equal(t, "binary", string(contents))
+			if tc.externalLink {
+				assert.Empty(t, assetAuth, "the token is only sent to the provider's host")
+			} else {
+				assert.Equal(t, tc.authValue, assetAuth)
+			}
+		})
+	}
+}
+
+func TestGitHubReleaseMissingAsset(t *testing.T) {
+	logger.InitLogger(false)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v1.0.0", "assets": []}`))
+	}))
+	defer server.Close()
+
+	installer := newTestGitHubReleaseInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("tool"),
+		Type: appconfig.InstallerTypeGitHubRelease,
+		Opts: &map[string]any{
+			"provider":          "gitea",
+			"api_url":           server.URL + "/api/v1",
+			"repository":        "owner/tool",
+			"download_filename": "tool",
+			"destination":       t.TempDir(),
+		},
+	})
+	_, _, err := installer.downloadRelease(t.TempDir(), "tool")
+	assert.ErrorContains(t, err, "no asset named tool")
+}
+
 func TestNewGitHubReleaseInstaller(t *testing.T) {
 	logger.InitLogger(false)
 
@@ -21,13 +21,13 @@ type GoInstaller struct {
 type GoOpts struct {
 	// Version is the module version to install (appended as `@version`).
 	// Defaults to "latest" when neither this nor an inline `@version` on Name is set.
-	Version *string
+	Version *string `json:"version"       yaml:"version"`
 	// Flags is a string of additional flags to pass to the go install command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // Validate validates the installer configuration.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/expr"
 	"github.com/chenasraf/sofmani/logger"
 	"github.com/chenasraf/sofmani/machine"
 	"github.com/chenasraf/sofmani/platform"
@@ -59,6 +60,24 @@ func GetInstaller(config *appconfig.AppConfig, data *appconfig.InstallerData) (I
 		return NewAptInstaller(config, data), nil
 	case appconfig.InstallerTypePacman, appconfig.InstallerTypeYay:
 		return NewPacmanInstaller(config, data), nil
+	case appconfig.InstallerTypeDnf, appconfig.InstallerTypeYum, appconfig.InstallerTypeZypper:
+		return NewDnfInstaller(config, data), nil
+	case appconfig.InstallerTypeFlatpak:
+		return NewFlatpakInstaller(config, data), nil
+	case appconfig.InstallerTypeSnap:
+		return NewSnapInstaller(config, data), nil
+	case appconfig.InstallerTypeUv:
+		return NewUvInstaller(config, data), nil
+	case appconfig.InstallerTypeGem:
+		return NewGemInstaller(config, data), nil
+	case appconfig.InstallerTypeMise:
+		return NewMiseInstaller(config, data), nil
+	case appconfig.InstallerTypeNix:
+		return NewNixInstaller(config, data), nil
+	case appconfig.InstallerTypeURL:
+		return NewURLInstaller(config, data), nil
+	case appconfig.InstallerTypeLink:
+		return NewLinkInstaller(config, data), nil
 	case appconfig.InstallerTypePipx:
 		return NewPipxInstaller(config, data), nil
 	case appconfig.InstallerTypeGitHubRelease:
@@ -102,9 +121,44 @@ func (i *InstallerBase) BaseValidate() []ValidationError {
 	if info.Name == nil || len(*info.Name) == 0 {
 		errors = append(errors, ValidationError{FieldName: "name", Message: "Name is required"})
 	}
+	if err := info.Platforms.Validate(); err != nil {
+		errors = append(errors, ValidationError{FieldName: "platforms", Message: err.Error()})
+	}
+	if err := info.Traits.Validate(); err != nil {
+		errors = append(errors, ValidationError{FieldName: "traits", Message: err.Error()})
+	}
+	if err := info.Archs.Validate(); err != nil {
+		errors = append(errors, ValidationError{FieldName: "archs", Message: err.Error()})
+	}
+	if info.When != nil {
+		if _, err := expr.Parse(*info.When); err != nil {
+			errors = append(errors, ValidationError{FieldName: "when", Message: err.Error()})
+		}
+	}
+	if info.Defaults != nil {
+		errors = append(errors, ValidationError{FieldName: "defaults", Message: "Only valid on category entries"})
+	}
+	errors = append(errors, validateNames(info)...)
 	return errors
 }
 
+// validatePlatformMapOpt validates the keys of an option that takes a platform map, such as
+// download_filename.
+func (i *InstallerBase) validatePlatformMapOpt(field string) []ValidationError {
+	info := i.GetData()
+	if info.Opts == nil {
+		return nil
+	}
+	raw, ok := (*info.Opts)[field]
+	if !ok {
+		return nil
+	}
+	if _, err := platform.NewPlatformMap[string](raw); err != nil {
+		return []ValidationError{{FieldName: field, Message: err.Error(), InstallerName: *info.Name}}
+	}
+	return nil
+}
+
 // applyTemplate applies template variables to a string if TemplateVars is set.
 func (i *InstallerBase) applyTemplate(input string) string {
 	if i.TemplateVars == nil {
@@ -183,6 +237,36 @@ type IChildResultsProvider interface {
 	GetChildResults() []summary.InstallResult
 }
 
+// SkipReason returns why the installer is skipped on this machine by its platforms, archs, traits
+// or machines, or by the filters. It returns an empty string if the installer should run.
+func SkipReason(config *appconfig.AppConfig, installer IInstaller) string {
+	info := installer.GetData()
+	name := *info.Name
+	curOS := platform.GetPlatform()
+	machineID := machine.GetMachineID()
+	var machineAliases map[string]string
+	if config.MachineAliases != nil {
+		machineAliases = *config.MachineAliases
+	}
+
+	if !info.Platforms.GetShouldRunOnOS(curOS) {
+		return fmt.Sprintf("%s should not run on %s, skipping", logger.H(name), curOS)
+	}
+	if !info.Archs.GetShouldRunOnArch(platform.GetArch()) {
+		return fmt.Sprintf("%s should not run on %s, skipping", logger.H(name), platform.GetArch())
+	}
+	if !info.Traits.GetShouldRunWithTraits(platform.GetTraits()) {
+		return fmt.Sprintf("%s should not run with traits %v, skipping", logger.H(name), platform.GetTraits())
+	}
+	if !info.Machines.GetShouldRunOnMachine(machineID, machineAliases) {
+		return fmt.Sprintf("%s should not run on machine %s, skipping", logger.H(name), machineID)
+	}
+	if !FilterInstaller(installer, config.Filter) {
+		return fmt.Sprintf("%s is filtered, skipping", logger.H(name))
+	}
+	return ""
+}
+
 // RunInstaller executes the installation or update process for a given installer.
 // It returns the result of the installation/update and any error that occurred.
 func RunInstaller(config *appconfig.AppConfig, installer IInstaller) (*summary.InstallResult, error) {
@@ -231,21 +315,16 @@ func RunInstaller(config *appconfig.AppConfig, installer IInstaller) (*summary.I
 	templateVars := NewTemplateVars("", machineAliases)
 	installer.SetTemplateVars(templateVars)
 
-	if !installer.GetData().Platforms.GetShouldRunOnOS(curOS) {
-		logger.Debug("%s should not run on %s, skipping", logger.H(name), curOS)
+	if reason := SkipReason(config, installer); reason != "" {
+		logger.Debug("%s", reason)
 		result.Action = summary.ActionSkipped
 		return result, nil
 	}
 
-	if !installer.GetData().Machines.GetShouldRunOnMachine(machineID, machineAliases) {
-		logger.Debug("%s should not run on machine %s, skipping", logger.H(name), machineID)
-		result.Action = summary.ActionSkipped
-		return result, nil
-	}
-	if !FilterInstaller(installer, config.Filter) {
-		logger.Debug("%s is filtered, skipping", logger.H(name))
-		result.Action = summary.ActionSkipped
-		return result, nil
+	// Resolve the installer's env up front, so that failing env sources (e.g. a missing env_file or
+	// a failing command) are reported instead of silently dropping variables.
+	if _, err := info.ResolveEnviron(); err != nil {
+		return nil, fmt.Errorf("failed to resolve env for %s: %w", name, err)
 	}
 
 	// applyTmpl applies template variables to a string for this installer.
@@ -258,6 +337,16 @@ func RunInstaller(config *appconfig.AppConfig, installer IInstaller) (*summary.I
 		return result
 	}
 
+	matches, err := InstallerWhenMatches(installer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate when condition of %s: %w", name, err)
+	}
+	if !matches {
+		logger.Debug("%s: when condition %q is false, skipping", logger.H(name), *info.When)
+		result.Action = summary.ActionSkipped
+		return result, nil
+	}
+
 	enabled, err := InstallerIsEnabled(installer)
 
 	if err != nil {
@@ -282,6 +371,24 @@ func RunInstaller(config *appconfig.AppConfig, installer IInstaller) (*summary.I
 		}
 	}
 
+	if batch, ok := installer.(IBatchInstaller); ok && info.Names != nil {
+		runHook := func(hook *string) error {
+			if hook == nil {
+				return nil
+			}
+			return utils.RunCmdPassThrough(env, utils.GetOSShell(info.EnvShell), utils.GetOSShellArgs(applyTmpl(*hook))...)
+		}
+		packages, err := packageInstallers(config, batch)
+		if err != nil {
+			return nil, err
+		}
+		if err := runBatch(config, batch, packages, result, runHook); err != nil {
+			return nil, err
+		}
+		recordFrequency(name, info, result)
+		return result, nil
+	}
+
 	logger.Debug("Checking %s: %s", logger.H(string(info.Type)), logger.H(name))
 	installed, err := installer.CheckIsInstalled()
 	if err != nil {
@@ -357,9 +464,20 @@ func RunInstaller(config *appconfig.AppConfig, installer IInstaller) (*summary.I
 		result.Action = summary.ActionInstalled
 	}
 
-	// Write frequency timestamp on any successful completion (install, update, or up-to-date check).
-	// This ensures the next check is deferred until the frequency period has elapsed, even if no
-	// update was available this time.
+	recordFrequency(name, info, result)
+
+	// Collect child results for group/manifest installers
+	if provider, ok := installer.(IChildResultsProvider); ok {
+		result.Children = provider.GetChildResults()
+	}
+
+	return result, nil
+}
+
+// recordFrequency writes the frequency timestamp on any successful completion (install, update, or
+// up-to-date check). This ensures the next check is deferred until the frequency period has
+// elapsed, even if no update was available this time.
+func recordFrequency(name string, info *appconfig.InstallerData, result *summary.InstallResult) {
 	if info.Frequency != nil && *info.Frequency != "" &&
 		(result.Action == summary.ActionInstalled ||
 			result.Action == summary.ActionUpgraded ||
@@ -368,11 +486,4 @@ func RunInstaller(config *appconfig.AppConfig, installer IInstaller) (*summary.I
 			logger.Warn("Failed to write frequency timestamp for %s: %v", logger.H(name), err)
 		}
 	}
-
-	// Collect child results for group/manifest installers
-	if provider, ok := installer.(IChildResultsProvider); ok {
-		result.Children = provider.GetChildResults()
-	}
-
-	return result, nil
 }
@@ -1,105 +1,34 @@
 package installer
 
 import (
-	"maps"
+	"strings"
 
 	"github.com/chenasraf/sofmani/appconfig"
 	"github.com/chenasraf/sofmani/machine"
 	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
 )
 
 // InstallerWithDefaults applies default configurations to an installer data object.
-// It first applies base defaults using FillDefaults, and then applies type-specific defaults.
+// It first fills in the configured defaults (all, then type, then tag, then the installer's own
+// fields), and then initializes the remaining unset fields using FillDefaults.
 func InstallerWithDefaults(
 	data *appconfig.InstallerData,
 	installerType appconfig.InstallerType,
 	defaults *appconfig.AppConfigDefaults,
 ) *appconfig.InstallerData {
-	// set base defaults
+	*data = defaults.Resolve(*data, installerType)
 	FillDefaults(data)
-
-	// per-type overrides from defaults
-	if defaults != nil && defaults.Type != nil {
-		if override, ok := (*defaults.Type)[installerType]; ok {
-			if override.Opts != nil {
-				source := *override.Opts
-				target := *data.Opts
-				maps.Copy(target, source)
-			}
-			if override.Env != nil {
-				source := *override.Env
-				target := *data.Env
-				maps.Copy(target, source)
-			}
-			if override.PlatformEnv != nil {
-				source := *override.PlatformEnv
-				targetBase := *data.PlatformEnv
-				if source.MacOS != nil && targetBase.MacOS != nil {
-					target := *targetBase.MacOS
-					maps.Copy(target, *source.MacOS)
-				}
-				if source.Linux != nil && targetBase.Linux != nil {
-					target := *targetBase.Linux
-					maps.Copy(target, *source.Linux)
-				}
-				if source.Windows != nil && targetBase.Windows != nil {
-					target := *targetBase.Windows
-					maps.Copy(target, *source.Windows)
-				}
-			}
-			if override.EnvShell != nil {
-				source := *override.EnvShell
-				target := data.EnvShell // data.EnvShell should be initialized by FillDefaults
-				if target == nil {      // Should not happen if FillDefaults is called
-					data.EnvShell = &platform.PlatformMap[string]{}
-					target = data.EnvShell
-				}
-				if source.MacOS != nil {
-					target.MacOS = source.MacOS
-				}
-				if source.Linux != nil {
-					target.Linux = source.Linux
-				}
-				if source.Windows != nil {
-					target.Windows = source.Windows
-				}
-			}
-			if override.Platforms != nil {
-				data.Platforms = override.Platforms
-			}
-			if override.Machines != nil {
-				data.Machines = override.Machines
-			}
-			if override.PreUpdate != nil {
-				data.PreUpdate = override.PreUpdate
-			}
-			if override.PostUpdate != nil {
-				data.PostUpdate = override.PostUpdate
-			}
-			if override.PreInstall != nil {
-				data.PreInstall = override.PreInstall
-			}
-			if override.PostInstall != nil {
-				data.PostInstall = override.PostInstall
-			}
-			if override.CheckHasUpdate != nil {
-				data.CheckHasUpdate = override.CheckHasUpdate
-			}
-			if override.CheckInstalled != nil {
-				data.CheckInstalled = override.CheckInstalled
-			}
-			if override.Verbose != nil && data.Verbose == nil {
-				data.Verbose = override.Verbose
-			}
-		}
-	}
 	return data
 }
 
 // FillDefaults initializes nil fields in an InstallerData object with empty values.
 func FillDefaults(data *appconfig.InstallerData) {
+	if data.Name == nil && data.Names != nil {
+		data.Name = lo.ToPtr(strings.Join(*data.Names, " "))
+	}
 	if data.Env == nil {
-		data.Env = &map[string]string{}
+		data.Env = &appconfig.EnvVars{}
 	}
 	if data.Opts == nil {
 		data.Opts = &map[string]any{}
@@ -120,6 +49,14 @@ func FillDefaults(data *appconfig.InstallerData) {
 		platforms := platform.Platforms{}
 		data.Platforms = &platforms
 	}
+	if data.Archs == nil {
+		archs := platform.Archs{}
+		data.Archs = &archs
+	}
+	if data.Traits == nil {
+		traits := platform.Traits{}
+		data.Traits = &traits
+	}
 	if data.Machines == nil {
 		machines := machine.Machines{}
 		data.Machines = &machines
@@ -131,14 +68,43 @@ func FillDefaults(data *appconfig.InstallerData) {
 		str := ""
 		data.Tags = &str
 	}
-	// Default overrides per type — only applied when the user hasn't constrained platforms.
-	if data.Platforms.Only == nil && data.Platforms.Except == nil {
+	// Default overrides per type — only applied when the user hasn't constrained platforms. System
+	// package managers only run on the distributions that ship them (and their derivatives), and
+	// Linux-only package managers only run on Linux. Nix doesn't run on Windows.
+	// When the distribution is unknown, e.g. in a minimal container without os-release, system
+	// package managers run on any Linux, see distroPlatforms.
+	if data.Platforms.Only == nil && data.Platforms.Except == nil && data.Platforms.Distro == nil {
 		switch data.Type {
-		case appconfig.InstallerTypeApt,
-			appconfig.InstallerTypeApk,
-			appconfig.InstallerTypePacman,
+		case appconfig.InstallerTypeApt:
+			data.Platforms.Only = distroPlatforms(platform.DistroDebian)
+		case appconfig.InstallerTypeApk:
+			data.Platforms.Only = distroPlatforms(platform.DistroAlpine)
+		case appconfig.InstallerTypePacman,
 			appconfig.InstallerTypeYay:
+			data.Platforms.Only = distroPlatforms(platform.DistroArch)
+		case appconfig.InstallerTypeDnf,
+			appconfig.InstallerTypeYum:
+			data.Platforms.Only = distroPlatforms(platform.DistroFedora, platform.DistroRHEL)
+		case appconfig.InstallerTypeZypper:
+			data.Platforms.Only = distroPlatforms(platform.DistroSUSE)
+		case appconfig.InstallerTypeFlatpak,
+			appconfig.InstallerTypeSnap:
 			data.Platforms.Only = &[]platform.Platform{platform.PlatformLinux}
+		case appconfig.InstallerTypeNix:
+			data.Platforms.Only = &[]platform.Platform{platform.PlatformMacos, platform.PlatformLinux}
 		}
 	}
 }
+
+// distroPlatforms returns the platforms a system package manager runs on by default: the given
+// distributions (and their derivatives), or any Linux when the current distribution is unknown.
+func distroPlatforms(distros ...platform.DistroID) *[]platform.Platform {
+	if platform.GetDistro().ID == "" {
+		return &[]platform.Platform{platform.PlatformLinux}
+	}
+	platforms := []platform.Platform{}
+	for _, distro := range distros {
+		platforms = append(platforms, platform.Platform(string(platform.PlatformLinux)+"/"+string(distro)))
+	}
+	return &platforms
+}
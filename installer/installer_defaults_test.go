@@ -6,6 +6,7 @@ import (
 	"github.com/chenasraf/sofmani/appconfig"
 	"github.com/chenasraf/sofmani/logger"
 	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,8 +28,18 @@ func TestFillDefaults(t *testing.T) {
 		assert.NotNil(t, data.Tags)
 	})
 
+	t.Run("defaults name to names", func(t *testing.T) {
+		data := &appconfig.InstallerData{Names: &[]string{"git", "curl"}}
+		FillDefaults(data)
+		assert.Equal(t, "git curl", *data.Name)
+
+		data = &appconfig.InstallerData{Name: lo.ToPtr("tools"), Names: &[]string{"git", "curl"}}
+		FillDefaults(data)
+		assert.Equal(t, "tools", *data.Name)
+	})
+
 	t.Run("does not overwrite existing values", func(t *testing.T) {
-		existingEnv := map[string]string{"KEY": "VALUE"}
+		existingEnv := appconfig.NewEnvVars(map[string]string{"KEY": "VALUE"})
 		existingOpts := map[string]any{"opt": "val"}
 		data := &appconfig.InstallerData{
 			Env:  &existingEnv,
@@ -36,48 +47,114 @@ func TestFillDefaults(t *testing.T) {
 		}
 		FillDefaults(data)
 
-		assert.Equal(t, "VALUE", (*data.Env)["KEY"])
+		assert.Equal(t, "VALUE", data.Env.Map()["KEY"])
 		assert.Equal(t, "val", (*data.Opts)["opt"])
 	})
 
-	t.Run("sets Linux-only platforms for apt installer", func(t *testing.T) {
+	// The distro-only defaults need a known distribution, see "falls back to linux when the
+	// distribution is unknown".
+	platform.SetDistro(platform.Distro{ID: "ubuntu", IDLike: []string{"debian"}})
+	defer platform.ResetDistro()
+
+	t.Run("sets distro-only platforms for apt installer", func(t *testing.T) {
 		data := &appconfig.InstallerData{
 			Type: appconfig.InstallerTypeApt,
 		}
 		FillDefaults(data)
 
 		assert.NotNil(t, data.Platforms.Only)
-		assert.Contains(t, *data.Platforms.Only, platform.PlatformLinux)
+		assert.Equal(t, []platform.Platform{"linux/debian"}, *data.Platforms.Only)
 	})
 
-	t.Run("sets Linux-only platforms for apk installer", func(t *testing.T) {
+	t.Run("sets distro-only platforms for apk installer", func(t *testing.T) {
 		data := &appconfig.InstallerData{
 			Type: appconfig.InstallerTypeApk,
 		}
 		FillDefaults(data)
 
 		assert.NotNil(t, data.Platforms.Only)
-		assert.Contains(t, *data.Platforms.Only, platform.PlatformLinux)
+		assert.Equal(t, []platform.Platform{"linux/alpine"}, *data.Platforms.Only)
 	})
 
-	t.Run("sets Linux-only platforms for pacman installer", func(t *testing.T) {
+	t.Run("sets distro-only platforms for pacman installer", func(t *testing.T) {
 		data := &appconfig.InstallerData{
 			Type: appconfig.InstallerTypePacman,
 		}
 		FillDefaults(data)
 
 		assert.NotNil(t, data.Platforms.Only)
-		assert.Contains(t, *data.Platforms.Only, platform.PlatformLinux)
+		assert.Equal(t, []platform.Platform{"linux/arch"}, *data.Platforms.Only)
 	})
 
-	t.Run("sets Linux-only platforms for yay installer", func(t *testing.T) {
+	t.Run("sets distro-only platforms for yay installer", func(t *testing.T) {
 		data := &appconfig.InstallerData{
 			Type: appconfig.InstallerTypeYay,
 		}
 		FillDefaults(data)
 
 		assert.NotNil(t, data.Platforms.Only)
-		assert.Contains(t, *data.Platforms.Only, platform.PlatformLinux)
+		assert.Equal(t, []platform.Platform{"linux/arch"}, *data.Platforms.Only)
+	})
+
+	t.Run("sets distro-only platforms for dnf and yum installers", func(t *testing.T) {
+		for _, installerType := range []appconfig.InstallerType{appconfig.InstallerTypeDnf, appconfig.InstallerTypeYum} {
+			data := &appconfig.InstallerData{
+				Type: installerType,
+			}
+			FillDefaults(data)
+
+			assert.NotNil(t, data.Platforms.Only)
+			assert.Equal(t, []platform.Platform{"linux/fedora", "linux/rhel"}, *data.Platforms.Only)
+		}
+	})
+
+	t.Run("sets distro-only platforms for zypper installer", func(t *testing.T) {
+		data := &appconfig.InstallerData{
+			Type: appconfig.InstallerTypeZypper,
+		}
+		FillDefaults(data)
+
+		assert.NotNil(t, data.Platforms.Only)
+		assert.Equal(t, []platform.Platform{"linux/suse"}, *data.Platforms.Only)
+	})
+
+	t.Run("falls back to linux when the distribution is unknown", func(t *testing.T) {
+		platform.SetDistro(platform.Distro{})
+		defer platform.SetDistro(platform.Distro{ID: "ubuntu", IDLike: []string{"debian"}})
+
+		for _, installerType := range []appconfig.InstallerType{
+			appconfig.InstallerTypeApt,
+			appconfig.InstallerTypeApk,
+			appconfig.InstallerTypePacman,
+			appconfig.InstallerTypeDnf,
+			appconfig.InstallerTypeZypper,
+		} {
+			data := &appconfig.InstallerData{Type: installerType}
+			FillDefaults(data)
+			assert.Equal(t, []platform.Platform{platform.PlatformLinux}, *data.Platforms.Only, installerType)
+		}
+	})
+
+	t.Run("sets linux-only platforms for flatpak and snap installers", func(t *testing.T) {
+		for _, installerType := range []appconfig.InstallerType{appconfig.InstallerTypeFlatpak, appconfig.InstallerTypeSnap} {
+			data := &appconfig.InstallerData{
+				Type: installerType,
+			}
+			FillDefaults(data)
+
+			assert.NotNil(t, data.Platforms.Only)
+			assert.Equal(t, []platform.Platform{platform.PlatformLinux}, *data.Platforms.Only)
+		}
+	})
+
+	t.Run("sets macos and linux platforms for nix installer", func(t *testing.T) {
+		data := &appconfig.InstallerData{
+			Type: appconfig.InstallerTypeNix,
+		}
+		FillDefaults(data)
+
+		assert.NotNil(t, data.Platforms.Only)
+		assert.Equal(t, []platform.Platform{platform.PlatformMacos, platform.PlatformLinux}, *data.Platforms.Only)
 	})
 
 	t.Run("respects user-specified platforms for linux-only installers", func(t *testing.T) {
@@ -103,6 +180,18 @@ func TestFillDefaults(t *testing.T) {
 		assert.Nil(t, data.Platforms.Only)
 		assert.Equal(t, []platform.Platform{platform.PlatformWindows}, *data.Platforms.Except)
 	})
+
+	t.Run("respects user-specified distro for linux-only installers", func(t *testing.T) {
+		userDistro := []string{"ubuntu>=22.04"}
+		data := &appconfig.InstallerData{
+			Type:      appconfig.InstallerTypeApt,
+			Platforms: &platform.Platforms{Distro: &userDistro},
+		}
+		FillDefaults(data)
+
+		assert.Nil(t, data.Platforms.Only)
+		assert.Equal(t, []string{"ubuntu>=22.04"}, *data.Platforms.Distro)
+	})
 }
 
 func TestInstallerWithDefaults_Comprehensive(t *testing.T) {
@@ -137,7 +226,7 @@ func TestInstallerWithDefaults_Comprehensive(t *testing.T) {
 		data := &appconfig.InstallerData{
 			Type: appconfig.InstallerTypeBrew,
 		}
-		defaultEnv := map[string]string{"DEFAULT_VAR": "default_value"}
+		defaultEnv := appconfig.NewEnvVars(map[string]string{"DEFAULT_VAR": "default_value"})
 		defaults := &appconfig.AppConfigDefaults{
 			Type: &map[appconfig.InstallerType]appconfig.InstallerData{
 				appconfig.InstallerTypeBrew: {
@@ -147,7 +236,7 @@ func TestInstallerWithDefaults_Comprehensive(t *testing.T) {
 		}
 		result := InstallerWithDefaults(data, appconfig.InstallerTypeBrew, defaults)
 
-		assert.Equal(t, "default_value", (*result.Env)["DEFAULT_VAR"])
+		assert.Equal(t, "default_value", result.Env.Map()["DEFAULT_VAR"])
 	})
 
 	t.Run("applies hook defaults", func(t *testing.T) {
@@ -233,6 +322,53 @@ func TestInstallerWithDefaults_Comprehensive(t *testing.T) {
 		assert.NotContains(t, *result.Opts, "npm_option")
 	})
 
+	t.Run("does not override installer values", func(t *testing.T) {
+		data := &appconfig.InstallerData{
+			Type:       appconfig.InstallerTypeBrew,
+			PreInstall: lo.ToPtr("echo mine"),
+			Platforms:  &platform.Platforms{Only: &[]platform.Platform{platform.PlatformLinux}},
+		}
+		defaults := &appconfig.AppConfigDefaults{
+			Type: &map[appconfig.InstallerType]appconfig.InstallerData{
+				appconfig.InstallerTypeBrew: {
+					PreInstall: lo.ToPtr("echo default"),
+					PostUpdate: lo.ToPtr("echo updated"),
+					Platforms:  &platform.Platforms{Only: &[]platform.Platform{platform.PlatformMacos}},
+				},
+			},
+		}
+		result := InstallerWithDefaults(data, appconfig.InstallerTypeBrew, defaults)
+
+		assert.Equal(t, "echo mine", *result.PreInstall)
+		assert.Equal(t, "echo updated", *result.PostUpdate)
+		assert.Equal(t, []platform.Platform{platform.PlatformLinux}, *result.Platforms.Only)
+	})
+
+	t.Run("applies all, type and tag defaults in order", func(t *testing.T) {
+		data := &appconfig.InstallerData{
+			Type: appconfig.InstallerTypeBrew,
+			Tags: lo.ToPtr("work"),
+		}
+		defaults := &appconfig.AppConfigDefaults{
+			All: &appconfig.InstallerData{
+				PreInstall:  lo.ToPtr("echo all"),
+				PostInstall: lo.ToPtr("echo all"),
+				PreUpdate:   lo.ToPtr("echo all"),
+			},
+			Type: &map[appconfig.InstallerType]appconfig.InstallerData{
+				appconfig.InstallerTypeBrew: {PostInstall: lo.ToPtr("echo type"), PreUpdate: lo.ToPtr("echo type")},
+			},
+			Tag: &map[string]appconfig.InstallerData{
+				"work": {PreUpdate: lo.ToPtr("echo tag")},
+			},
+		}
+		result := InstallerWithDefaults(data, appconfig.InstallerTypeBrew, defaults)
+
+		assert.Equal(t, "echo all", *result.PreInstall)
+		assert.Equal(t, "echo type", *result.PostInstall)
+		assert.Equal(t, "echo tag", *result.PreUpdate)
+	})
+
 	t.Run("handles nil defaults gracefully", func(t *testing.T) {
 		data := &appconfig.InstallerData{
 			Type: appconfig.InstallerTypeBrew,
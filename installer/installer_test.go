@@ -7,6 +7,9 @@ import (
 
 	"github.com/chenasraf/sofmani/appconfig"
 	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/chenasraf/sofmani/summary"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
@@ -43,6 +46,65 @@ func TestRunInstaller(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestRunInstallerArchs(t *testing.T) {
+	logger.InitLogger(false)
+	originalArch := platform.GetArch()
+	defer platform.SetArch(string(originalArch))
+	platform.SetArch("aarch64")
+	config := &appconfig.AppConfig{}
+
+	skipped := &MockInstaller{
+		data: &appconfig.InstallerData{
+			Name:  lo.ToPtr("amd64-only"),
+			Type:  appconfig.InstallerTypeBrew,
+			Archs: &platform.Archs{Only: &[]platform.Architecture{platform.ArchAmd64}},
+		},
+	}
+	result, err := RunInstaller(config, skipped)
+	assert.NoError(t, err)
+	assert.Equal(t, summary.ActionSkipped, result.Action)
+
+	run := &MockInstaller{
+		data: &appconfig.InstallerData{
+			Name:  lo.ToPtr("not-amd64"),
+			Type:  appconfig.InstallerTypeBrew,
+			Archs: &platform.Archs{Except: &[]platform.Architecture{"x86_64"}},
+		},
+	}
+	result, err = RunInstaller(config, run)
+	assert.NoError(t, err)
+	assert.NotEqual(t, summary.ActionSkipped, result.Action)
+}
+
+func TestRunInstallerTraits(t *testing.T) {
+	logger.InitLogger(false)
+	platform.SetTraits([]platform.Trait{platform.TraitSSH})
+	defer platform.ResetTraits()
+	config := &appconfig.AppConfig{}
+
+	skipped := &MockInstaller{
+		data: &appconfig.InstallerData{
+			Name:   lo.ToPtr("gui-app"),
+			Type:   appconfig.InstallerTypeBrew,
+			Traits: &platform.Traits{Except: &[]platform.Trait{platform.TraitContainer, platform.TraitSSH}},
+		},
+	}
+	result, err := RunInstaller(config, skipped)
+	assert.NoError(t, err)
+	assert.Equal(t, summary.ActionSkipped, result.Action)
+
+	run := &MockInstaller{
+		data: &appconfig.InstallerData{
+			Name:   lo.ToPtr("remote-only"),
+			Type:   appconfig.InstallerTypeBrew,
+			Traits: &platform.Traits{Only: &[]platform.Trait{platform.TraitSSH}},
+		},
+	}
+	result, err = RunInstaller(config, run)
+	assert.NoError(t, err)
+	assert.NotEqual(t, summary.ActionSkipped, result.Action)
+}
+
 func TestCheckIsInstalled_UsesBinName(t *testing.T) {
 	logger.InitLogger(false)
 
@@ -129,3 +191,51 @@ func TestCheckIsInstalled_UsesBinName(t *testing.T) {
 		assert.True(t, installed, "github-release should fall back to name when bin_name is not set")
 	})
 }
+
+func TestSkipReason(t *testing.T) {
+	logger.InitLogger(false)
+	config := &appconfig.AppConfig{Filter: []string{"category:Work"}}
+
+	work := &MockInstaller{
+		data: &appconfig.InstallerData{Name: lo.ToPtr("slack"), Section: lo.ToPtr("Work")},
+	}
+	assert.Empty(t, SkipReason(config, work))
+
+	other := &MockInstaller{
+		data: &appconfig.InstallerData{Name: lo.ToPtr("htop"), Section: lo.ToPtr("System")},
+	}
+	assert.Contains(t, SkipReason(config, other), "is filtered")
+
+	wrongMachine := &MockInstaller{
+		data: &appconfig.InstallerData{
+			Name:     lo.ToPtr("slack"),
+			Section:  lo.ToPtr("Work"),
+			Machines: &machine.Machines{Only: &[]string{"not-this-machine"}},
+		},
+	}
+	assert.Contains(t, SkipReason(config, wrongMachine), "should not run on machine")
+}
+
+func TestBaseValidateDefaults(t *testing.T) {
+	base := &InstallerBase{Data: &appconfig.InstallerData{Name: lo.ToPtr("test"), Defaults: &appconfig.AppConfigDefaults{}}}
+	errors := base.BaseValidate()
+	assert.Len(t, errors, 1)
+	assert.Equal(t, "defaults", errors[0].FieldName)
+}
+
+func TestBaseValidateArchs(t *testing.T) {
+	valid := &InstallerBase{Data: &appconfig.InstallerData{
+		Name:  lo.ToPtr("test"),
+		Archs: &platform.Archs{Only: &[]platform.Architecture{platform.ArchArm64, "x86_64"}},
+	}}
+	assertNoValidationErrors(t, valid.BaseValidate())
+
+	invalid := &InstallerBase{Data: &appconfig.InstallerData{
+		Name:  lo.ToPtr("test"),
+		Archs: &platform.Archs{Only: &[]platform.Architecture{"arm"}, Except: &[]platform.Architecture{"x64"}},
+	}}
+	errors := invalid.BaseValidate()
+	assertValidationError(t, errors, "archs")
+	assert.Contains(t, errors[0].Message, `unknown architecture "arm"`)
+	assert.Contains(t, errors[0].Message, `unknown architecture "x64"`)
+}
@@ -0,0 +1,655 @@
+package installer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/utils"
+)
+
+// LinkInstaller is an installer that symlinks the files of a source directory into a target
+// directory, like GNU Stow.
+type LinkInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// LinkOpts represents options for the LinkInstaller.
+type LinkOpts struct {
+	// Source is the directory whose files are linked. With SourceInstaller, it is a subdirectory of
+	// the repository.
+	Source *string `json:"source"           yaml:"source"`
+	// SourceInstaller is the name of a git installer whose repository is the source directory.
+	SourceInstaller *string `json:"source_installer" yaml:"source_installer"`
+	// Target is the directory the links are created in. Defaults to the home directory.
+	Target *string `json:"target"           yaml:"target"`
+	// Ignore is a list of glob patterns for files and directories that are not linked, matched
+	// against their path relative to the source directory and against their base name. ".git" is
+	// always ignored.
+	Ignore []string `json:"ignore"           yaml:"ignore"`
+	// OnConflict is what to do when a file already exists where a link should be (skip, backup,
+	// overwrite, adopt). Defaults to skip.
+	OnConflict *LinkConflictStrategy `json:"on_conflict"      yaml:"on_conflict"`
+}
+
+// LinkConflictStrategy represents what the link installer does with existing files in the target.
+type LinkConflictStrategy string
+
+// Constants for link conflict strategies.
+const (
+	LinkConflictSkip      LinkConflictStrategy = "skip"      // LinkConflictSkip leaves the existing file and doesn't link it.
+	LinkConflictBackup    LinkConflictStrategy = "backup"    // LinkConflictBackup renames the existing file with a .bak suffix before linking.
+	LinkConflictOverwrite LinkConflictStrategy = "overwrite" // LinkConflictOverwrite deletes the existing file before linking.
+	LinkConflictAdopt     LinkConflictStrategy = "adopt"     // LinkConflictAdopt moves the existing file into the source directory before linking.
+)
+
+// linkState is the state of a link in the target directory.
+type linkState string
+
+// Constants for link states.
+const (
+	linkStateLinked    linkState = "linked"    // the link points to its source
+	linkStateMissing   linkState = "missing"   // nothing exists at the target
+	linkStateBroken    linkState = "broken"    // a link to a path that doesn't exist
+	linkStateElsewhere linkState = "elsewhere" // a link to another existing path
+	linkStateConflict  linkState = "conflict"  // a file or directory exists at the target
+	linkStateStale     linkState = "stale"     // a link created earlier, whose source was removed or is now ignored
+)
+
+// linkEntry is a file of the source directory and the path of its link.
+type linkEntry struct {
+	Source string
+	Target string
+}
+
+// Validate validates the installer configuration.
+func (i *LinkInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	info := i.GetData()
+	opts := i.GetOpts()
+	hasSource := opts.Source != nil && len(*opts.Source) > 0
+	hasSourceInstaller := opts.SourceInstaller != nil && len(*opts.SourceInstaller) > 0
+	if !hasSource && !hasSourceInstaller {
+		errors = append(errors, ValidationError{FieldName: "source", Message: validationIsRequired(), InstallerName: *info.Name})
+	}
+	if hasSourceInstaller && i.sourceInstaller() == nil {
+		errors = append(errors, ValidationError{FieldName: "source_installer", Message: fmt.Sprintf("no git installer named %s", *opts.SourceInstaller), InstallerName: *info.Name})
+	}
+	if opts.Target != nil && len(*opts.Target) == 0 {
+		errors = append(errors, ValidationError{FieldName: "target", Message: validationIsNotEmpty(), InstallerName: *info.Name})
+	}
+	for idx, pattern := range opts.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errors = append(errors, ValidationError{FieldName: fmt.Sprintf("ignore[%d]", idx), Message: validationInvalidFormat(), InstallerName: *info.Name})
+		}
+	}
+	if opts.OnConflict != nil {
+		switch *opts.OnConflict {
+		case LinkConflictSkip, LinkConflictBackup, LinkConflictOverwrite, LinkConflictAdopt:
+			// valid
+		default:
+			errors = append(errors, ValidationError{FieldName: "on_conflict", Message: validationInvalidFormat(), InstallerName: *info.Name})
+		}
+	}
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *LinkInstaller) Install() error {
+	source := i.GetSource()
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return fmt.Errorf("link source %s is not a directory", source)
+	}
+	entries, err := i.plan()
+	if err != nil {
+		return err
+	}
+	saved, err := i.savedLinks()
+	if err != nil {
+		return err
+	}
+	linked := []string{}
+	for _, entry := range entries {
+		ok, err := i.link(entry, saved)
+		if err != nil {
+			return err
+		}
+		if ok {
+			linked = append(linked, entry.Target)
+		}
+	}
+	stale, err := i.staleLinks(entries)
+	if err != nil {
+		return err
+	}
+	for _, target := range stale {
+		logger.Debug("Removing stale link %s", target)
+		if err := os.Remove(target); err != nil {
+			return fmt.Errorf("failed to remove stale link %s: %w", target, err)
+		}
+	}
+	return i.saveLinks(linked)
+}
+
+// link creates the link of entry, handling an existing file at its target according to the
+// conflict strategy. Broken links are replaced when they are ours (see ownsLink), and handled like
+// any other existing file otherwise. It returns whether the link is in place.
+func (i *LinkInstaller) link(entry linkEntry, saved []string) (bool, error) {
+	state, err := linkStatus(entry)
+	if err != nil {
+		return false, err
+	}
+	switch state {
+	case linkStateLinked:
+		return true, nil
+	case linkStateBroken:
+		if i.ownsLink(entry.Target, saved) {
+			logger.Debug("Replacing broken link %s", entry.Target)
+			if err := os.Remove(entry.Target); err != nil {
+				return false, fmt.Errorf("failed to remove broken link %s: %w", entry.Target, err)
+			}
+			break
+		}
+		fallthrough
+	case linkStateElsewhere, linkStateConflict:
+		resolved, err := i.resolveConflict(entry, state)
+		if err != nil || !resolved {
+			return false, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.Target), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(entry.Target), err)
+	}
+	logger.Debug("Linking %s -> %s", entry.Target, entry.Source)
+	if err := os.Symlink(entry.Source, entry.Target); err != nil {
+		return false, fmt.Errorf("failed to link %s to %s: %w", entry.Target, entry.Source, err)
+	}
+	return true, nil
+}
+
+// resolveConflict clears the target of entry according to the conflict strategy. It returns
+// whether the target was cleared and can be linked.
+func (i *LinkInstaller) resolveConflict(entry linkEntry, state linkState) (bool, error) {
+	switch i.GetConflictStrategy() {
+	case LinkConflictBackup:
+		backup, err := backupPath(entry.Target)
+		if err != nil {
+			return false, err
+		}
+		logger.Info("Backing up %s to %s", entry.Target, backup)
+		if err := os.Rename(entry.Target, backup); err != nil {
+			return false, fmt.Errorf("failed to back up %s: %w", entry.Target, err)
+		}
+	case LinkConflictOverwrite:
+		logger.Info("Overwriting %s", entry.Target)
+		if err := os.RemoveAll(entry.Target); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", entry.Target, err)
+		}
+	case LinkConflictAdopt:
+		if state == linkStateBroken {
+			logger.Warn("Not adopting %s: %s", entry.Target, linkStateDescription(state))
+			return false, nil
+		}
+		info, err := os.Stat(entry.Target)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat %s: %w", entry.Target, err)
+		}
+		if info.IsDir() {
+			logger.Warn("Not adopting %s: it is a directory, but %s is a file", entry.Target, entry.Source)
+			return false, nil
+		}
+		logger.Info("Adopting %s into %s", entry.Target, entry.Source)
+		if err := adoptFile(entry.Target, entry.Source); err != nil {
+			return false, err
+		}
+	default:
+		logger.Warn("Skipping %s: %s (use on_conflict to replace it)", entry.Target, linkStateDescription(state))
+		return false, nil
+	}
+	return true, nil
+}
+
+// adoptFile replaces the source file with the contents of the existing target file, and removes
+// the target so it can be linked.
+func adoptFile(target, source string) error {
+	// Copy rather than rename, so adopting works across filesystems and follows a target that is
+	// itself a link.
+	if err := copyFile(target, source); err != nil {
+		return fmt.Errorf("failed to adopt %s: %w", target, err)
+	}
+	if err := os.Remove(target); err != nil {
+		return fmt.Errorf("failed to remove adopted file %s: %w", target, err)
+	}
+	return nil
+}
+
+// backupPath returns a path to back up target to that doesn't exist yet: target.bak, or
+// target.bak.N if it does.
+func backupPath(target string) (string, error) {
+	backup := target + ".bak"
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			return backup, nil
+		} else if err != nil {
+			return "", fmt.Errorf("failed to stat %s: %w", backup, err)
+		}
+		backup = fmt.Sprintf("%s.bak.%d", target, n)
+	}
+}
+
+// Update implements IInstaller.
+func (i *LinkInstaller) Update() error {
+	return i.Install()
+}
+
+// CheckNeedsUpdate implements IInstaller. The links need an update when they have drifted: a link
+// is missing, broken or points elsewhere, or a link created earlier is stale. Conflicts that the
+// conflict strategy leaves in place are reported, but don't need an update.
+func (i *LinkInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	drift, err := i.Drift()
+	if err != nil {
+		return false, err
+	}
+	saved, err := i.savedLinks()
+	if err != nil {
+		return false, err
+	}
+	needsUpdate := false
+	for target, state := range drift {
+		if i.conflictSkipped(target, state, saved) {
+			logger.Warn("Skipping %s: %s (use on_conflict to replace it)", target, linkStateDescription(state))
+			continue
+		}
+		logger.Debug("%s: %s", target, linkStateDescription(state))
+		needsUpdate = true
+	}
+	return needsUpdate, nil
+}
+
+// conflictSkipped returns whether Install leaves the target of a link in the given state as it is,
+// because the conflict strategy doesn't replace it.
+func (i *LinkInstaller) conflictSkipped(target string, state linkState, saved []string) bool {
+	switch state {
+	case linkStateElsewhere, linkStateConflict:
+	case linkStateBroken:
+		if i.ownsLink(target, saved) {
+			return false
+		}
+	default:
+		return false
+	}
+	switch i.GetConflictStrategy() {
+	case LinkConflictBackup, LinkConflictOverwrite:
+		return false
+	case LinkConflictAdopt:
+		// Directories and broken links can't be adopted into a file.
+		info, err := os.Stat(target)
+		return err != nil || info.IsDir()
+	default:
+		return true
+	}
+}
+
+// ownsLink returns whether the link at target was created by the installer: it was recorded by an
+// earlier install, or it points into the source directory.
+func (i *LinkInstaller) ownsLink(target string, saved []string) bool {
+	if slices.Contains(saved, target) {
+		return true
+	}
+	dest, err := os.Readlink(target)
+	if err != nil {
+		return false
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(target), dest)
+	}
+	source := filepath.Clean(i.GetSource()) + string(filepath.Separator)
+	return strings.HasPrefix(filepath.Clean(dest), source)
+}
+
+// CheckIsInstalled implements IInstaller. The links are installed when any of them is in place.
+func (i *LinkInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	if _, err := os.Stat(i.GetSource()); err != nil {
+		return false, nil
+	}
+	entries, err := i.plan()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		state, err := linkStatus(entry)
+		if err != nil {
+			return false, err
+		}
+		if state == linkStateLinked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Drift returns the targets whose links aren't in place, mapped to their state.
+func (i *LinkInstaller) Drift() (map[string]linkState, error) {
+	drift := map[string]linkState{}
+	if _, err := os.Stat(i.GetSource()); err != nil {
+		return drift, nil
+	}
+	entries, err := i.plan()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		state, err := linkStatus(entry)
+		if err != nil {
+			return nil, err
+		}
+		if state != linkStateLinked {
+			drift[entry.Target] = state
+		}
+	}
+	stale, err := i.staleLinks(entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range stale {
+		drift[target] = linkStateStale
+	}
+	return drift, nil
+}
+
+// linkStatus returns the state of the link of entry.
+func linkStatus(entry linkEntry) (linkState, error) {
+	info, err := os.Lstat(entry.Target)
+	if os.IsNotExist(err) {
+		return linkStateMissing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", entry.Target, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return linkStateConflict, nil
+	}
+	dest, err := os.Readlink(entry.Target)
+	if err != nil {
+		return "", fmt.Errorf("failed to read link %s: %w", entry.Target, err)
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(entry.Target), dest)
+	}
+	if filepath.Clean(dest) == filepath.Clean(entry.Source) {
+		return linkStateLinked, nil
+	}
+	if _, err := os.Stat(dest); err != nil {
+		return linkStateBroken, nil
+	}
+	return linkStateElsewhere, nil
+}
+
+// linkStateDescription returns a human-readable description of a link state.
+func linkStateDescription(state linkState) string {
+	switch state {
+	case linkStateMissing:
+		return "link is missing"
+	case linkStateBroken:
+		return "link is broken"
+	case linkStateElsewhere:
+		return "link points elsewhere"
+	case linkStateConflict:
+		return "a file exists in place of the link"
+	case linkStateStale:
+		return "link's source no longer exists"
+	}
+	return string(state)
+}
+
+// plan returns the links to create: one for each file of the source directory that isn't
+// ignored, at the same relative path in the target directory.
+func (i *LinkInstaller) plan() ([]linkEntry, error) {
+	source := i.GetSource()
+	target := i.GetTarget()
+	ignore := append([]string{".git"}, i.GetOpts().Ignore...)
+	entries := []linkEntry{}
+	err := filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if linkIgnored(filepath.ToSlash(rel), ignore) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		entries = append(entries, linkEntry{Source: p, Target: filepath.Join(target, rel)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read link source %s: %w", source, err)
+	}
+	return entries, nil
+}
+
+// linkIgnored returns whether the slash-separated path relative to the source directory matches
+// any of the ignore patterns, either as a whole or by its base name.
+func linkIgnored(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// staleLinks returns the links created by an earlier install that are no longer planned, and
+// still point into the source directory.
+func (i *LinkInstaller) staleLinks(entries []linkEntry) ([]string, error) {
+	saved, err := i.savedLinks()
+	if err != nil {
+		return nil, err
+	}
+	source := filepath.Clean(i.GetSource()) + string(filepath.Separator)
+	stale := []string{}
+	for _, target := range saved {
+		if slices.ContainsFunc(entries, func(e linkEntry) bool { return e.Target == target }) {
+			continue
+		}
+		dest, err := os.Readlink(target)
+		if err != nil {
+			// Removed, or replaced by a regular file: not ours anymore.
+			continue
+		}
+		if strings.HasPrefix(filepath.Clean(dest), source) {
+			stale = append(stale, target)
+		}
+	}
+	return stale, nil
+}
+
+// linksFile returns the path of the file listing the links created by the installer.
+func (i *LinkInstaller) linksFile() (string, error) {
+	cacheDir, err := utils.GetCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve cache directory: %w", err)
+	}
+	replacer := strings.NewReplacer(
+		"/", "__",
+		"\\", "__",
+		":", "__",
+		" ", "_",
+	)
+	return filepath.Join(cacheDir, "links_"+replacer.Replace(*i.Info.Name)), nil
+}
+
+// savedLinks returns the links created by the last install.
+func (i *LinkInstaller) savedLinks() ([]string, error) {
+	file, err := i.linksFile()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read links file %s: %w", file, err)
+	}
+	links := []string{}
+	for line := range strings.Lines(string(contents)) {
+		if line = strings.TrimSpace(line); line != "" {
+			links = append(links, line)
+		}
+	}
+	return links, nil
+}
+
+// saveLinks records the links created by an install, to detect stale links later.
+func (i *LinkInstaller) saveLinks(links []string) error {
+	file, err := i.linksFile()
+	if err != nil {
+		return err
+	}
+	logger.Debug("Updating links file %s", file)
+	if err := os.WriteFile(file, []byte(strings.Join(links, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to write links file %s: %w", file, err)
+	}
+	return nil
+}
+
+// sourceInstaller returns the git installer named by SourceInstaller, or nil if there is none.
+func (i *LinkInstaller) sourceInstaller() *GitInstaller {
+	opts := i.GetOpts()
+	if opts.SourceInstaller == nil || i.Config == nil {
+		return nil
+	}
+	data := findInstallerData(i.Config.Install, *opts.SourceInstaller)
+	if data == nil || data.Type != appconfig.InstallerTypeGit {
+		return nil
+	}
+	return NewGitInstaller(i.Config, data)
+}
+
+// findInstallerData returns the installer with the given name, searching group steps too.
+func findInstallerData(installers []appconfig.InstallerData, name string) *appconfig.InstallerData {
+	for idx := range installers {
+		data := &installers[idx]
+		if data.Name != nil && *data.Name == name {
+			return data
+		}
+		if data.Steps != nil {
+			if found := findInstallerData(*data.Steps, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// GetSource returns the absolute path of the source directory.
+func (i *LinkInstaller) GetSource() string {
+	opts := i.GetOpts()
+	source := ""
+	if opts.Source != nil {
+		source = *opts.Source
+	}
+	if git := i.sourceInstaller(); git != nil {
+		source = filepath.Join(git.GetInstallDir(), source)
+	}
+	if abs, err := filepath.Abs(source); err == nil {
+		return abs
+	}
+	return source
+}
+
+// GetTarget returns the target directory, defaulting to the home directory.
+func (i *LinkInstaller) GetTarget() string {
+	opts := i.GetOpts()
+	target := utils.GetRealPath(i.GetData().Environ(), "~")
+	if opts.Target != nil && len(*opts.Target) > 0 {
+		target = *opts.Target
+	}
+	if abs, err := filepath.Abs(target); err == nil {
+		return abs
+	}
+	return target
+}
+
+// GetConflictStrategy returns the conflict strategy, defaulting to skip.
+func (i *LinkInstaller) GetConflictStrategy() LinkConflictStrategy {
+	opts := i.GetOpts()
+	if opts.OnConflict != nil {
+		return *opts.OnConflict
+	}
+	return LinkConflictSkip
+}
+
+// GetData implements IInstaller.
+func (i *LinkInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the LinkInstaller.
+func (i *LinkInstaller) GetOpts() *LinkOpts {
+	opts := &LinkOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if source, ok := (*info.Opts)["source"].(string); ok {
+			source = utils.GetRealPath(i.GetData().Environ(), source)
+			opts.Source = &source
+		}
+		if sourceInstaller, ok := (*info.Opts)["source_installer"].(string); ok {
+			opts.SourceInstaller = &sourceInstaller
+		}
+		if target, ok := (*info.Opts)["target"].(string); ok {
+			target = utils.GetRealPath(i.GetData().Environ(), target)
+			opts.Target = &target
+		}
+		if raw, ok := (*info.Opts)["ignore"].([]any); ok {
+			for _, pattern := range raw {
+				if s, ok := pattern.(string); ok {
+					opts.Ignore = append(opts.Ignore, s)
+				}
+			}
+		}
+		if onConflict, ok := (*info.Opts)["on_conflict"].(string); ok {
+			strategy := LinkConflictStrategy(strings.ToLower(onConflict))
+			opts.OnConflict = &strategy
+		}
+	}
+	return opts
+}
+
+// NewLinkInstaller creates a new LinkInstaller.
+func NewLinkInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *LinkInstaller {
+	i := &LinkInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
@@ -0,0 +1,368 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLinkInstaller(data *appconfig.InstallerData) *LinkInstaller {
+	return NewLinkInstaller(&appconfig.AppConfig{}, data)
+}
+
+// linkTestDirs creates a source directory with dotfiles and an empty target directory, and
+// returns a link installer between them with the given extra options.
+func linkTestDirs(t *testing.T, extra map[string]any) (*LinkInstaller, string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires elevated privileges on windows")
+	}
+	useTestCacheDir(t)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "dotfiles")
+	target := filepath.Join(dir, "home")
+	for name, contents := range map[string]string{
+		".zshrc":                  "zshrc",
+		".config/nvim/init.lua":   "init",
+		".config/git/config":      "gitconfig",
+		"README.md":               "readme",
+		".git/HEAD":               "ref",
+		".config/nvim/.luarc.swp": "swap",
+	} {
+		path := filepath.Join(source, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+	require.NoError(t, os.MkdirAll(target, 0755))
+	opts := map[string]any{"source": source, "target": target, "ignore": []any{"README.md", "*.swp"}}
+	for k, v := range extra {
+		opts[k] = v
+	}
+	installer := newTestLinkInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("dotfiles-" + filepath.Base(dir)),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &opts,
+	})
+	return installer, source, target
+}
+
+func TestLinkValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	valid := newTestLinkInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("dotfiles"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{"source": "~/dotfiles", "ignore": []any{"*.md"}, "on_conflict": "backup"},
+	})
+	assertNoValidationErrors(t, valid.Validate())
+
+	missingSource := newTestLinkInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("dotfiles"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{"target": "~"},
+	})
+	assertValidationError(t, missingSource.Validate(), "source")
+
+	invalid := newTestLinkInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("dotfiles"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{
+			"source":           "~/dotfiles",
+			"source_installer": "owner/dotfiles",
+			"target":           "",
+			"ignore":           []any{"[a-"},
+			"on_conflict":      "merge",
+		},
+	})
+	errors := invalid.Validate()
+	assertValidationError(t, errors, "source_installer")
+	assertValidationError(t, errors, "target")
+	assertValidationError(t, errors, "ignore[0]")
+	assertValidationError(t, errors, "on_conflict")
+}
+
+func TestLinkGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestLinkInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("dotfiles"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{
+			"source":           "zsh",
+			"source_installer": "owner/dotfiles",
+			"target":           "/tmp/home",
+			"ignore":           []any{"*.md", ".DS_Store"},
+			"on_conflict":      "Adopt",
+		},
+	})
+	opts := installer.GetOpts()
+	assert.Equal(t, "zsh", *opts.Source)
+	assert.Equal(t, "owner/dotfiles", *opts.SourceInstaller)
+	assert.Equal(t, "/tmp/home", *opts.Target)
+	assert.Equal(t, []string{"*.md", ".DS_Store"}, opts.Ignore)
+	assert.Equal(t, LinkConflictAdopt, installer.GetConflictStrategy())
+
+	installer = newTestLinkInstaller(&appconfig.InstallerData{Name: lo.ToPtr("dotfiles"), Type: appconfig.InstallerTypeLink})
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, home, installer.GetTarget())
+	assert.Equal(t, LinkConflictSkip, installer.GetConflictStrategy())
+}
+
+func TestLinkSourceInstaller(t *testing.T) {
+	logger.InitLogger(false)
+
+	cfg := &appconfig.AppConfig{
+		Install: []appconfig.InstallerData{
+			{
+				Name: lo.ToPtr("dotfiles-group"),
+				Type: appconfig.InstallerTypeGroup,
+				Steps: &[]appconfig.InstallerData{
+					{
+						Name: lo.ToPtr("owner/dotfiles"),
+						Type: appconfig.InstallerTypeGit,
+						Opts: &map[string]any{"destination": "/src"},
+					},
+				},
+			},
+			{Name: lo.ToPtr("neovim"), Type: appconfig.InstallerTypeBrew},
+		},
+	}
+	installer := NewLinkInstaller(cfg, &appconfig.InstallerData{
+		Name: lo.ToPtr("zsh"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{"source_installer": "owner/dotfiles", "source": "zsh"},
+	})
+	assertNoValidationErrors(t, installer.Validate())
+	assert.Equal(t, filepath.Join(string(filepath.Separator), "src", "dotfiles", "zsh"), installer.GetSource())
+
+	installer = NewLinkInstaller(cfg, &appconfig.InstallerData{
+		Name: lo.ToPtr("nvim"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{"source_installer": "neovim"},
+	})
+	assertValidationError(t, installer.Validate(), "source_installer")
+}
+
+func TestLinkIgnored(t *testing.T) {
+	patterns := []string{".git", "*.md", "scripts/*"}
+	for _, tc := range []struct {
+		path     string
+		expected bool
+	}{
+		{".git", true},
+		{"README.md", true},
+		{"docs/notes.md", true},
+		{"scripts/install.sh", true},
+		{".zshrc", false},
+		{".config/scripts", false},
+	} {
+		assert.Equal(t, tc.expected, linkIgnored(tc.path, patterns), tc.path)
+	}
+}
+
+func TestLinkInstall(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer, source, target := linkTestDirs(t, nil)
+	installed, err := installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.False(t, installed)
+
+	require.NoError(t, installer.Install())
+	for _, name := range []string{".zshrc", ".config/nvim/init.lua", ".config/git/config"} {
+		dest, err := os.Readlink(filepath.Join(target, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, filepath.Join(source, name), dest)
+	}
+	// Directories are created, not linked.
+	info, err := os.Lstat(filepath.Join(target, ".config", "nvim"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	for _, name := range []string{"README.md", ".git", ".config/nvim/.luarc.swp"} {
+		_, err := os.Lstat(filepath.Join(target, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	installed, err = installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.True(t, installed)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+}
+
+func TestLinkDrift(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer, source, target := linkTestDirs(t, map[string]any{"on_conflict": "overwrite"})
+	require.NoError(t, os.WriteFile(filepath.Join(source, ".bashrc"), []byte("bashrc"), 0644))
+	require.NoError(t, installer.Install())
+
+	// A stale link, a link pointing elsewhere, a broken link and a missing link.
+	require.NoError(t, os.Remove(filepath.Join(source, ".bashrc")))
+	other := filepath.Join(t.TempDir(), "other")
+	require.NoError(t, os.WriteFile(other, []byte("other"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(target, ".zshrc")))
+	require.NoError(t, os.Symlink(other, filepath.Join(target, ".zshrc")))
+	require.NoError(t, os.Remove(filepath.Join(target, ".config/git/config")))
+	require.NoError(t, os.Symlink(filepath.Join(t.TempDir(), "gone"), filepath.Join(target, ".config/git/config")))
+	require.NoError(t, os.Remove(filepath.Join(target, ".config/nvim/init.lua")))
+
+	drift, err := installer.Drift()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]linkState{
+		filepath.Join(target, ".zshrc"):                linkStateElsewhere,
+		filepath.Join(target, ".config/git/config"):    linkStateBroken,
+		filepath.Join(target, ".config/nvim/init.lua"): linkStateMissing,
+		filepath.Join(target, ".bashrc"):               linkStateStale,
+	}, drift)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.True(t, needsUpdate)
+
+	require.NoError(t, installer.Install())
+	drift, err = installer.Drift()
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+	_, err = os.Lstat(filepath.Join(target, ".bashrc"))
+	assert.True(t, os.IsNotExist(err))
+	// The file the link pointed to is left alone.
+	contents, err := os.ReadFile(other)
+	require.NoError(t, err)
+	assert.Equal(t, "other", string(contents))
+}
+
+func TestLinkConflicts(t *testing.T) {
+	logger.InitLogger(false)
+
+	for _, tc := range []struct {
+		strategy       string
+		linked         bool
+		sourceContents string
+		backup         bool
+	}{
+		{strategy: "skip", linked: false, sourceContents: "zshrc"},
+		{strategy: "backup", linked: true, sourceContents: "zshrc", backup: true},
+		{strategy: "overwrite", linked: true, sourceContents: "zshrc"},
+		{strategy: "adopt", linked: true, sourceContents: "local"},
+	} {
+		t.Run(tc.strategy, func(t *testing.T) {
+			installer, source, target := linkTestDirs(t, map[string]any{"on_conflict": tc.strategy})
+			existing := filepath.Join(target, ".zshrc")
+			require.NoError(t, os.WriteFile(existing, []byte("local"), 0644))
+			// An earlier backup is kept.
+			require.NoError(t, os.WriteFile(existing+".bak", []byte("older"), 0644))
+
+			require.NoError(t, installer.Install())
+			state, err := linkStatus(linkEntry{Source: filepath.Join(source, ".zshrc"), Target: existing})
+			require.NoError(t, err)
+			if tc.linked {
+				assert.Equal(t, linkStateLinked, state)
+			} else {
+				assert.Equal(t, linkStateConflict, state)
+			}
+			contents, err := os.ReadFile(filepath.Join(source, ".zshrc"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.sourceContents, string(contents))
+			older, err := os.ReadFile(existing + ".bak")
+			require.NoError(t, err)
+			assert.Equal(t, "older", string(older))
+			backup, err := os.ReadFile(existing + ".bak.1")
+			if tc.backup {
+				require.NoError(t, err)
+				assert.Equal(t, "local", string(backup))
+			} else {
+				assert.True(t, os.IsNotExist(err))
+			}
+			// The other files are linked regardless.
+			state, err = linkStatus(linkEntry{Source: filepath.Join(source, ".config/git/config"), Target: filepath.Join(target, ".config/git/config")})
+			require.NoError(t, err)
+			assert.Equal(t, linkStateLinked, state)
+		})
+	}
+}
+
+func TestLinkSkippedConflictsDontNeedUpdate(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer, _, target := linkTestDirs(t, nil)
+	require.NoError(t, os.WriteFile(filepath.Join(target, ".zshrc"), []byte("local"), 0644))
+	require.NoError(t, installer.Install())
+
+	drift, err := installer.Drift()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]linkState{filepath.Join(target, ".zshrc"): linkStateConflict}, drift)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+
+	// A conflict the strategy would replace still needs an update.
+	opts := *installer.Info.Opts
+	opts["on_conflict"] = "backup"
+	installer.Info.Opts = &opts
+	needsUpdate, err = installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.True(t, needsUpdate)
+}
+
+func TestLinkBrokenLinks(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer, source, target := linkTestDirs(t, nil)
+	// A broken link of the user's, e.g. to an unmounted path, and a broken link into the source
+	// directory.
+	foreign := filepath.Join(target, ".zshrc")
+	unmounted := filepath.Join(t.TempDir(), "mnt", "zshrc")
+	require.NoError(t, os.Symlink(unmounted, foreign))
+	ours := filepath.Join(target, ".config/git/config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(ours), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(source, ".config/git/old-config"), ours))
+
+	require.NoError(t, installer.Install())
+	// With on_conflict skip, the user's link is kept, and ours is replaced.
+	dest, err := os.Readlink(foreign)
+	require.NoError(t, err)
+	assert.Equal(t, unmounted, dest)
+	dest, err = os.Readlink(ours)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(source, ".config/git/config"), dest)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+
+	// A strategy that replaces existing files replaces the user's link too.
+	opts := *installer.Info.Opts
+	opts["on_conflict"] = "backup"
+	installer.Info.Opts = &opts
+	needsUpdate, err = installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.True(t, needsUpdate)
+	require.NoError(t, installer.Install())
+	dest, err = os.Readlink(foreign)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(source, ".zshrc"), dest)
+	dest, err = os.Readlink(foreign + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, unmounted, dest)
+}
+
+func TestLinkInstallMissingSource(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestLinkInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("dotfiles"),
+		Type: appconfig.InstallerTypeLink,
+		Opts: &map[string]any{"source": filepath.Join(t.TempDir(), "missing"), "target": t.TempDir()},
+	})
+	installed, err := installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.False(t, installed)
+	assert.Error(t, installer.Install())
+}
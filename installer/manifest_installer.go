@@ -12,6 +12,7 @@ import (
 	"github.com/chenasraf/sofmani/logger"
 	"github.com/chenasraf/sofmani/summary"
 	"github.com/chenasraf/sofmani/utils"
+	"github.com/samber/lo"
 )
 
 // ManifestInstaller is an installer that installs software based on another sofmani manifest file.
@@ -30,11 +31,11 @@ type ManifestInstaller struct {
 // ManifestOpts represents options for the ManifestInstaller.
 type ManifestOpts struct {
 	// Source is the source of the manifest file. It can be a local path or a Git URL.
-	Source *string
+	Source *string `json:"source" yaml:"source"`
 	// Path is the path to the manifest file within the source (if applicable, e.g., in a Git repository).
-	Path *string
+	Path *string `json:"path"   yaml:"path"`
 	// Ref is the Git reference (branch, tag, or commit) to use if the source is a Git URL.
-	Ref *string
+	Ref *string `json:"ref"    yaml:"ref"`
 }
 
 // Validate validates the installer configuration.
@@ -153,7 +154,8 @@ func (i *ManifestInstaller) FetchManifest() error {
 		if fetchErr != nil {
 			return fetchErr
 		}
-		config, err = appconfig.ParseConfigFromContent([]byte(content))
+		format := appconfig.ConfigFormatFromPath(lo.FromPtrOr(opts.Path, ""))
+		config, err = appconfig.ParseConfigFromContent([]byte(content), format)
 		if err != nil {
 			return fmt.Errorf("failed to parse manifest content from %s: %w", source, err)
 		}
@@ -163,7 +165,7 @@ func (i *ManifestInstaller) FetchManifest() error {
 		if fetchErr != nil {
 			return fetchErr
 		}
-		config, err = appconfig.ParseConfigFromContent([]byte(content))
+		config, err = appconfig.ParseConfigFromContent([]byte(content), appconfig.ConfigFormatFromPath(source))
 		if err != nil {
 			return fmt.Errorf("failed to parse manifest content from %s: %w", source, err)
 		}
@@ -187,6 +189,10 @@ func (i *ManifestInstaller) FetchManifest() error {
 
 	logger.Debug("Installers: %d", len(config.Install))
 	config = i.inheritManifest(config)
+	if err := config.ResolveTemplates(); err != nil {
+		return fmt.Errorf("failed to resolve templates in manifest %s: %w", source, err)
+	}
+	config.ApplyCategories()
 	i.ManifestConfig = config
 	return nil
 }
@@ -279,13 +285,19 @@ func (i *ManifestInstaller) inheritManifest(config *appconfig.AppConfig) *appcon
 	}
 	if self.Env != nil {
 		logger.Debug("Injecting base env variables")
-		var env map[string]string
 		if config.Env == nil {
-			env = make(map[string]string)
-		} else {
-			env = *config.Env
+			config.Env = &appconfig.EnvVars{}
+		}
+		config.Env.Merge(self.Env)
+	}
+	if self.Templates != nil {
+		// The manifest can extend the templates of the config that includes it, and its own
+		// templates take precedence.
+		templates := maps.Clone(*self.Templates)
+		if config.Templates != nil {
+			maps.Copy(templates, *config.Templates)
 		}
-		maps.Copy(env, *self.Env)
+		config.Templates = &templates
 	}
 	if self.Defaults != nil {
 		defs := self.Defaults
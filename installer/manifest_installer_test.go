@@ -1,12 +1,15 @@
 package installer
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/chenasraf/sofmani/appconfig"
 	"github.com/chenasraf/sofmani/logger"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newTestManifestInstaller(data *appconfig.InstallerData) *ManifestInstaller {
@@ -243,3 +246,37 @@ func TestNewManifestInstaller(t *testing.T) {
 		assert.Equal(t, data, installer.Data)
 	})
 }
+
+func TestManifestInheritsTemplates(t *testing.T) {
+	logger.InitLogger(false)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(`
+templates:
+  cask:
+    opts:
+      cask: true
+install:
+  - name: app
+    extends: [brew, cask]
+`), 0644))
+
+	cfg := &appconfig.AppConfig{
+		CheckUpdates: lo.ToPtr(false),
+		Templates: &map[string]appconfig.InstallerData{
+			"brew": {Type: appconfig.InstallerTypeBrew},
+			"cask": {Opts: &map[string]any{"cask": false}},
+		},
+	}
+	installer := NewManifestInstaller(cfg, &appconfig.InstallerData{
+		Name: lo.ToPtr("manifest"),
+		Type: appconfig.InstallerTypeManifest,
+		Opts: &map[string]any{"source": dir, "path": "manifest.yaml"},
+	})
+	require.NoError(t, installer.FetchManifest())
+
+	app := installer.ManifestConfig.Install[0]
+	assert.Equal(t, appconfig.InstallerTypeBrew, app.Type)
+	// The manifest's own templates take precedence over the including config's.
+	assert.Equal(t, true, (*app.Opts)["cask"])
+}
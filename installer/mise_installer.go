@@ -0,0 +1,289 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+)
+
+// MiseInstaller is an installer for runtimes and tools managed by mise.
+type MiseInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// MiseOpts represents options for the MiseInstaller.
+type MiseOpts struct {
+	// Version is the version to install, either pinned (e.g. "20.11.1"), a prefix (e.g. "20") or
+	// "latest". Defaults to "latest".
+	Version *string `json:"version"       yaml:"version"`
+	// Global sets the version as the global version (mise use --global).
+	Global *bool `json:"global"        yaml:"global"`
+	// Plugin ensures the plugin for the tool is installed before installing it, for tools that
+	// aren't built into mise (e.g. asdf plugins).
+	Plugin *bool `json:"plugin"        yaml:"plugin"`
+	// PluginURL is the git URL to install the plugin from. Setting it implies plugin.
+	PluginURL *string `json:"plugin_url"    yaml:"plugin_url"`
+	// Flags is a string of additional flags to pass to the mise command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+// miseInstalledVersion is a version of a tool in the output of `mise ls --json`.
+type miseInstalledVersion struct {
+	Version   string `json:"version"`
+	Installed bool   `json:"installed"`
+}
+
+// Validate validates the installer configuration.
+func (i *MiseInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	info := i.GetData()
+	if info.Opts != nil {
+		// Unquoted versions are parsed as numbers, which turns e.g. 3.10 into 3.1.
+		if version, ok := (*info.Opts)["version"]; ok {
+			if _, ok := version.(string); !ok {
+				errors = append(errors, ValidationError{FieldName: "version", Message: "Must be a quoted string, e.g. \"20\"", InstallerName: *info.Name})
+			}
+		}
+	}
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *MiseInstaller) Install() error {
+	if err := i.ensurePlugin(); err != nil {
+		return err
+	}
+	opts := i.GetOpts()
+	return i.install(i.GetVersion(), opts.InstallFlags, opts.Flags)
+}
+
+// Update implements IInstaller. The newest version matching the requested one is installed, since
+// mise resolves prefixes and "latest" to the newest installed version.
+func (i *MiseInstaller) Update() error {
+	if err := i.ensurePlugin(); err != nil {
+		return err
+	}
+	latest, err := i.latestVersion()
+	if err != nil {
+		return err
+	}
+	opts := i.GetOpts()
+	if err := i.RunCmdPassThrough("mise", i.installArgs(latest, opts.UpdateFlags, opts.Flags)...); err != nil {
+		return err
+	}
+	if i.IsGlobal() {
+		return i.RunCmdPassThrough("mise", i.useArgs(i.GetVersion(), nil, nil)...)
+	}
+	return nil
+}
+
+// install installs the given version of the tool, and sets it as the global version if configured.
+func (i *MiseInstaller) install(version string, flags *string, fallback *string) error {
+	if i.IsGlobal() {
+		return i.RunCmdPassThrough("mise", i.useArgs(version, flags, fallback)...)
+	}
+	return i.RunCmdPassThrough("mise", i.installArgs(version, flags, fallback)...)
+}
+
+// installArgs returns the arguments to install the given version of the tool.
+func (i *MiseInstaller) installArgs(version string, flags *string, fallback *string) []string {
+	return i.command([]string{"install"}, version, flags, fallback)
+}
+
+// useArgs returns the arguments to install the given version of the tool and set it as the global
+// version.
+func (i *MiseInstaller) useArgs(version string, flags *string, fallback *string) []string {
+	return i.command([]string{"use", "--global"}, version, flags, fallback)
+}
+
+// command returns the arguments for a mise command on the given version of the tool.
+func (i *MiseInstaller) command(args []string, version string, flags *string, fallback *string) []string {
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	if flags != nil {
+		args = append(args, strings.Fields(*flags)...)
+	} else if fallback != nil {
+		args = append(args, strings.Fields(*fallback)...)
+	}
+	return append(args, *i.Info.Name+"@"+version)
+}
+
+// ensurePlugin installs the tool's plugin once per run, if the installer needs one.
+func (i *MiseInstaller) ensurePlugin() error {
+	opts := i.GetOpts()
+	if !i.NeedsPlugin() {
+		return nil
+	}
+	name := *i.Info.Name
+	return RunRepoUpdateOnce("mise-plugin:"+name, func() error {
+		logger.Debug("Installing mise plugin %s", name)
+		args := []string{"plugins", "install", name}
+		if opts.PluginURL != nil {
+			args = append(args, *opts.PluginURL)
+		}
+		if err := i.RunCmdPassThrough("mise", args...); err != nil {
+			return fmt.Errorf("failed to install mise plugin %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// CheckNeedsUpdate implements IInstaller.
+func (i *MiseInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	latest, err := i.latestVersion()
+	if err != nil {
+		return false, err
+	}
+	installed, err := i.installedVersions()
+	if err != nil {
+		return false, err
+	}
+	return !miseHasVersion(installed, latest), nil
+}
+
+// CheckIsInstalled implements IInstaller. The tool is only considered installed if a version
+// matching the requested one is installed.
+func (i *MiseInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	installed, err := i.installedVersions()
+	if err != nil {
+		return false, err
+	}
+	return miseHasVersion(installed, i.GetVersion()), nil
+}
+
+// installedVersions returns the installed versions of the tool, from `mise ls --json`.
+func (i *MiseInstaller) installedVersions() ([]string, error) {
+	out, err := i.RunCmdGetOutput("mise", "ls", "--json")
+	if err != nil {
+		return nil, err
+	}
+	return parseMiseLs(out, *i.Info.Name)
+}
+
+// latestVersion returns the newest available version matching the requested one.
+func (i *MiseInstaller) latestVersion() (string, error) {
+	query := *i.Info.Name
+	if version := i.GetVersion(); version != "latest" {
+		query += "@" + version
+	}
+	out, err := i.RunCmdGetOutput("mise", "latest", query)
+	if err != nil {
+		return "", err
+	}
+	latest := strings.TrimSpace(string(out))
+	if latest == "" {
+		return "", fmt.Errorf("no version of %s found", query)
+	}
+	return latest, nil
+}
+
+// parseMiseLs returns the installed versions of a tool in the output of `mise ls --json`, which maps
+// each tool to its versions.
+func parseMiseLs(output []byte, name string) ([]string, error) {
+	tools := map[string][]miseInstalledVersion{}
+	if err := json.Unmarshal(output, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse mise ls output: %w", err)
+	}
+	versions := []string{}
+	for _, version := range tools[name] {
+		if version.Installed {
+			versions = append(versions, version.Version)
+		}
+	}
+	return versions, nil
+}
+
+// miseHasVersion returns whether any of the installed versions matches the requested one. A request
+// matches its own version and every version it is a prefix of, and "latest" matches any version.
+func miseHasVersion(installed []string, requested string) bool {
+	for _, version := range installed {
+		if requested == "latest" || version == requested || strings.HasPrefix(version, requested+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetData implements IInstaller.
+func (i *MiseInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the MiseInstaller.
+func (i *MiseInstaller) GetOpts() *MiseOpts {
+	opts := &MiseOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if version, ok := (*info.Opts)["version"].(string); ok {
+			opts.Version = &version
+		}
+		if global, ok := (*info.Opts)["global"].(bool); ok {
+			opts.Global = &global
+		}
+		if plugin, ok := (*info.Opts)["plugin"].(bool); ok {
+			opts.Plugin = &plugin
+		}
+		if pluginURL, ok := (*info.Opts)["plugin_url"].(string); ok {
+			opts.PluginURL = &pluginURL
+		}
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// GetVersion returns the requested version of the tool.
+func (i *MiseInstaller) GetVersion() string {
+	if version := i.GetOpts().Version; version != nil && len(*version) > 0 {
+		return *version
+	}
+	return "latest"
+}
+
+// IsGlobal returns whether the version is set as the global version.
+func (i *MiseInstaller) IsGlobal() bool {
+	opts := i.GetOpts()
+	return opts.Global != nil && *opts.Global
+}
+
+// NeedsPlugin returns whether the tool's plugin is installed before the tool.
+func (i *MiseInstaller) NeedsPlugin() bool {
+	opts := i.GetOpts()
+	return opts.PluginURL != nil || (opts.Plugin != nil && *opts.Plugin)
+}
+
+// NewMiseInstaller creates a new MiseInstaller.
+func NewMiseInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *MiseInstaller {
+	i := &MiseInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
@@ -0,0 +1,172 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMiseInstaller(data *appconfig.InstallerData) *MiseInstaller {
+	return NewMiseInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestMiseValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestMiseInstaller(&appconfig.InstallerData{Name: lo.ToPtr("node"), Type: appconfig.InstallerTypeMise})
+	assertNoValidationErrors(t, installer.Validate())
+
+	installer = newTestMiseInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("python"),
+		Type: appconfig.InstallerTypeMise,
+		Opts: &map[string]any{"version": 3.1},
+	})
+	errors := installer.Validate()
+	require.Len(t, errors, 1)
+	assert.Equal(t, "version", errors[0].FieldName)
+}
+
+func TestMiseGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestMiseInstaller(&appconfig.InstallerData{Name: lo.ToPtr("node"), Type: appconfig.InstallerTypeMise})
+	assert.Equal(t, "latest", installer.GetVersion())
+	assert.False(t, installer.IsGlobal())
+	assert.False(t, installer.NeedsPlugin())
+
+	installer = newTestMiseInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("node"),
+		Type: appconfig.InstallerTypeMise,
+		Opts: &map[string]any{
+			"version":       "20",
+			"global":        true,
+			"plugin_url":    "https://github.com/asdf-vm/asdf-nodejs.git",
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts := installer.GetOpts()
+	assert.Equal(t, "20", installer.GetVersion())
+	assert.True(t, installer.IsGlobal())
+	assert.True(t, installer.NeedsPlugin())
+	assert.Equal(t, "https://github.com/asdf-vm/asdf-nodejs.git", *opts.PluginURL)
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+
+	installer = newTestMiseInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("terraform"),
+		Type: appconfig.InstallerTypeMise,
+		Opts: &map[string]any{"plugin": true},
+	})
+	assert.True(t, installer.NeedsPlugin())
+}
+
+func TestMiseArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestMiseInstaller(&appconfig.InstallerData{Name: lo.ToPtr("node"), Type: appconfig.InstallerTypeMise})
+	assert.Equal(t, []string{"install", "node@latest"}, installer.installArgs("latest", nil, nil))
+	assert.Equal(t, []string{"use", "--global", "node@20"}, installer.useArgs("20", nil, nil))
+
+	installer = newTestMiseInstaller(&appconfig.InstallerData{
+		Name:    lo.ToPtr("node"),
+		Type:    appconfig.InstallerTypeMise,
+		Verbose: lo.ToPtr(true),
+	})
+	assert.Equal(t, []string{"install", "--verbose", "--jobs", "2", "node@20.11.1"}, installer.installArgs("20.11.1", nil, lo.ToPtr("--jobs 2")))
+	assert.Equal(t, []string{"install", "--verbose", "--raw", "node@20.11.1"}, installer.installArgs("20.11.1", lo.ToPtr("--raw"), lo.ToPtr("--jobs 2")))
+}
+
+func TestParseMiseLs(t *testing.T) {
+	output := []byte(`{
+  "node": [
+    {"version": "18.19.0", "installed": true, "active": false},
+    {"version": "20.11.1", "requested_version": "20", "installed": true, "active": true},
+    {"version": "22.0.0", "installed": false, "active": false}
+  ],
+  "python": []
+}`)
+	versions, err := parseMiseLs(output, "node")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"18.19.0", "20.11.1"}, versions)
+
+	versions, err = parseMiseLs(output, "go")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	_, err = parseMiseLs([]byte("not json"), "node")
+	assert.Error(t, err)
+}
+
+func TestMiseHasVersion(t *testing.T) {
+	installed := []string{"18.19.0", "20.11.1"}
+	for _, tc := range []struct {
+		requested string
+		expected  bool
+	}{
+		{"latest", true},
+		{"20.11.1", true},
+		{"20", true},
+		{"20.11", true},
+		{"2", false},
+		{"20.1", false},
+		{"22", false},
+	} {
+		assert.Equal(t, tc.expected, miseHasVersion(installed, tc.requested), tc.requested)
+	}
+	assert.False(t, miseHasVersion([]string{}, "latest"))
+}
+
+func TestMiseChecks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake mise binary")
+	}
+	logger.InitLogger(false)
+
+	// A fake mise binary with node 20.11.1 installed and 20.12.0 available.
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+  ls) echo '{"node": [{"version": "20.11.1", "installed": true}]}' ;;
+  latest)
+    case "$2" in
+      node@20.11.1) echo 20.11.1 ;;
+      *) echo 20.12.0 ;;
+    esac ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mise"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, tc := range []struct {
+		version     string
+		installed   bool
+		needsUpdate bool
+	}{
+		{"latest", true, true},
+		{"20", true, true},
+		{"20.11.1", true, false},
+		{"18", false, true},
+	} {
+		installer := newTestMiseInstaller(&appconfig.InstallerData{
+			Name: lo.ToPtr("node"),
+			Type: appconfig.InstallerTypeMise,
+			Opts: &map[string]any{"version": tc.version},
+		})
+		installed, err := installer.CheckIsInstalled()
+		require.NoError(t, err)
+		assert.Equal(t, tc.installed, installed, tc.version)
+		needsUpdate, err := installer.CheckNeedsUpdate()
+		require.NoError(t, err)
+		assert.Equal(t, tc.needsUpdate, needsUpdate, tc.version)
+	}
+}
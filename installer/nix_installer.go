@@ -0,0 +1,286 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+)
+
+// NixInstaller is an installer for packages installed into the user's Nix profile.
+type NixInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// NixOpts represents options for the NixInstaller.
+type NixOpts struct {
+	// Flake is the flake reference to install from, e.g. "github:owner/repo". Defaults to "nixpkgs".
+	Flake *string `json:"flake"         yaml:"flake"`
+	// Attr is the attribute of the flake to install. Defaults to the installer name for nixpkgs, and
+	// to the flake's default package otherwise.
+	Attr *string `json:"attr"          yaml:"attr"`
+	// Priority is the priority of the package in the profile (--priority flag). Lower values win
+	// when packages provide the same files.
+	Priority *int `json:"priority"      yaml:"priority"`
+	// Flags is a string of additional flags to pass to the nix command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+// nixProfileElement is a package in the output of `nix profile list --json`.
+type nixProfileElement struct {
+	// Name is the element's name in the profile, or its index for older versions of Nix.
+	Name        string   `json:"-"`
+	AttrPath    string   `json:"attrPath"`
+	OriginalURL string   `json:"originalUrl"`
+	StorePaths  []string `json:"storePaths"`
+}
+
+const nixDefaultFlake = "nixpkgs"
+
+// Validate validates the installer configuration.
+func (i *NixInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	info := i.GetData()
+	if info.Opts != nil {
+		if _, ok := (*info.Opts)["priority"]; ok && i.GetOpts().Priority == nil {
+			errors = append(errors, ValidationError{FieldName: "priority", Message: validationInvalidFormat(), InstallerName: *info.Name})
+		}
+	}
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *NixInstaller) Install() error {
+	return i.RunCmdPassThrough("nix", i.installArgs()...)
+}
+
+// installArgs returns the arguments to install the package into the profile.
+func (i *NixInstaller) installArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("install")
+	if opts.Priority != nil {
+		args = append(args, "--priority", strconv.Itoa(*opts.Priority))
+	}
+	if opts.InstallFlags != nil {
+		args = append(args, strings.Fields(*opts.InstallFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, i.GetRef())
+}
+
+// Update implements IInstaller.
+func (i *NixInstaller) Update() error {
+	element, err := i.profileElement()
+	if err != nil {
+		return err
+	}
+	if element == nil {
+		return fmt.Errorf("%s is not installed in the nix profile", i.GetRef())
+	}
+	return i.RunCmdPassThrough("nix", i.updateArgs(element.Name)...)
+}
+
+// updateArgs returns the arguments to upgrade the given profile element.
+func (i *NixInstaller) updateArgs(element string) []string {
+	opts := i.GetOpts()
+	args := i.command("upgrade")
+	if opts.UpdateFlags != nil {
+		args = append(args, strings.Fields(*opts.UpdateFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, element)
+}
+
+// command returns the arguments for a nix profile command.
+func (i *NixInstaller) command(name string) []string {
+	args := []string{"profile", name}
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	return args
+}
+
+// CheckNeedsUpdate implements IInstaller. The package needs an update when the flake currently
+// evaluates to a different store path than the one in the profile.
+func (i *NixInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	element, err := i.profileElement()
+	if err != nil || element == nil {
+		return false, err
+	}
+	out, err := i.RunCmdGetOutput("nix", "eval", "--raw", i.GetRef()+".outPath")
+	if err != nil {
+		return false, err
+	}
+	return !slices.Contains(element.StorePaths, strings.TrimSpace(string(out))), nil
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *NixInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	element, err := i.profileElement()
+	if err != nil {
+		return false, err
+	}
+	return element != nil, nil
+}
+
+// profileElement returns the profile element installed from the installer's flake and attribute, or
+// nil if it isn't installed.
+func (i *NixInstaller) profileElement() (*nixProfileElement, error) {
+	out, err := i.RunCmdGetOutput("nix", "profile", "list", "--json")
+	if err != nil {
+		return nil, err
+	}
+	elements, err := parseNixProfileList(out)
+	if err != nil {
+		return nil, err
+	}
+	flake, attr := i.flakeAttr()
+	for _, element := range elements {
+		if nixElementMatches(element, flake, attr) {
+			return &element, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseNixProfileList returns the elements in the output of `nix profile list --json`. Newer
+// versions of Nix map element names to elements, older ones list them in an array.
+func parseNixProfileList(output []byte) ([]nixProfileElement, error) {
+	list := struct {
+		Elements json.RawMessage `json:"elements"`
+	}{}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse nix profile list output: %w", err)
+	}
+	elements := []nixProfileElement{}
+	if len(list.Elements) == 0 {
+		return elements, nil
+	}
+	named := map[string]nixProfileElement{}
+	if err := json.Unmarshal(list.Elements, &named); err == nil {
+		for name, element := range named {
+			element.Name = name
+			elements = append(elements, element)
+		}
+		slices.SortFunc(elements, func(a, b nixProfileElement) int { return strings.Compare(a.Name, b.Name) })
+		return elements, nil
+	}
+	indexed := []nixProfileElement{}
+	if err := json.Unmarshal(list.Elements, &indexed); err != nil {
+		return nil, fmt.Errorf("failed to parse nix profile list output: %w", err)
+	}
+	for index, element := range indexed {
+		element.Name = strconv.Itoa(index)
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// nixElementMatches returns whether a profile element was installed from the given flake and
+// attribute. The profile records the full attribute path, e.g. "legacyPackages.x86_64-linux.hello",
+// and registry flakes with a "flake:" prefix.
+func nixElementMatches(element nixProfileElement, flake string, attr string) bool {
+	if element.OriginalURL != flake && element.OriginalURL != "flake:"+flake {
+		return false
+	}
+	return element.AttrPath == attr || strings.HasSuffix(element.AttrPath, "."+attr)
+}
+
+// flakeAttr returns the flake and the attribute to install from it.
+func (i *NixInstaller) flakeAttr() (string, string) {
+	opts := i.GetOpts()
+	flake := nixDefaultFlake
+	if opts.Flake != nil && len(*opts.Flake) > 0 {
+		flake = *opts.Flake
+	}
+	// A flake reference may include the attribute, e.g. "github:owner/repo#package".
+	flake, attr, hasAttr := strings.Cut(flake, "#")
+	switch {
+	case opts.Attr != nil && len(*opts.Attr) > 0:
+		attr = *opts.Attr
+	case hasAttr:
+		// Keep the attribute from the reference.
+	case flake == nixDefaultFlake:
+		attr = *i.Info.Name
+	default:
+		attr = "default"
+	}
+	return flake, attr
+}
+
+// GetRef returns the installable reference of the package, e.g. "nixpkgs#hello".
+func (i *NixInstaller) GetRef() string {
+	flake, attr := i.flakeAttr()
+	return flake + "#" + attr
+}
+
+// GetData implements IInstaller.
+func (i *NixInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the NixInstaller.
+func (i *NixInstaller) GetOpts() *NixOpts {
+	opts := &NixOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if flake, ok := (*info.Opts)["flake"].(string); ok {
+			opts.Flake = &flake
+		}
+		if attr, ok := (*info.Opts)["attr"].(string); ok {
+			opts.Attr = &attr
+		}
+		if raw, ok := (*info.Opts)["priority"]; ok {
+			switch v := raw.(type) {
+			case int:
+				opts.Priority = &v
+			case int64:
+				n := int(v)
+				opts.Priority = &n
+			case float64:
+				n := int(v)
+				opts.Priority = &n
+			}
+		}
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// NewNixInstaller creates a new NixInstaller.
+func NewNixInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *NixInstaller {
+	i := &NixInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
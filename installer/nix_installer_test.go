@@ -0,0 +1,199 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNixInstaller(data *appconfig.InstallerData) *NixInstaller {
+	return NewNixInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestNixValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestNixInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("ripgrep"),
+		Type: appconfig.InstallerTypeNix,
+		Opts: &map[string]any{"priority": 4},
+	})
+	assertNoValidationErrors(t, installer.Validate())
+
+	installer = newTestNixInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("ripgrep"),
+		Type: appconfig.InstallerTypeNix,
+		Opts: &map[string]any{"priority": "high"},
+	})
+	errors := installer.Validate()
+	require.Len(t, errors, 1)
+	assert.Equal(t, "priority", errors[0].FieldName)
+}
+
+func TestNixGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestNixInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("ripgrep"),
+		Type: appconfig.InstallerTypeNix,
+		Opts: &map[string]any{
+			"flake":         "github:nixos/nixpkgs/nixos-24.05",
+			"attr":          "ripgrep-all",
+			"priority":      float64(4),
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts := installer.GetOpts()
+	assert.Equal(t, "github:nixos/nixpkgs/nixos-24.05", *opts.Flake)
+	assert.Equal(t, "ripgrep-all", *opts.Attr)
+	assert.Equal(t, 4, *opts.Priority)
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+}
+
+func TestNixGetRef(t *testing.T) {
+	logger.InitLogger(false)
+
+	for _, tc := range []struct {
+		name     string
+		opts     map[string]any
+		expected string
+	}{
+		{"defaults to the package in nixpkgs", map[string]any{}, "nixpkgs#ripgrep"},
+		{"uses attr", map[string]any{"attr": "ripgrep-all"}, "nixpkgs#ripgrep-all"},
+		{"defaults to the flake's default package", map[string]any{"flake": "github:owner/repo"}, "github:owner/repo#default"},
+		{"keeps the attribute in the flake", map[string]any{"flake": "github:owner/repo#tool"}, "github:owner/repo#tool"},
+		{"attr overrides the flake's attribute", map[string]any{"flake": "github:owner/repo#tool", "attr": "other"}, "github:owner/repo#other"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			installer := newTestNixInstaller(&appconfig.InstallerData{
+				Name: lo.ToPtr("ripgrep"),
+				Type: appconfig.InstallerTypeNix,
+				Opts: &tc.opts,
+			})
+			assert.Equal(t, tc.expected, installer.GetRef())
+		})
+	}
+}
+
+func TestNixArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestNixInstaller(&appconfig.InstallerData{Name: lo.ToPtr("ripgrep"), Type: appconfig.InstallerTypeNix})
+	assert.Equal(t, []string{"profile", "install", "nixpkgs#ripgrep"}, installer.installArgs())
+	assert.Equal(t, []string{"profile", "upgrade", "ripgrep"}, installer.updateArgs("ripgrep"))
+
+	installer = newTestNixInstaller(&appconfig.InstallerData{
+		Name:    lo.ToPtr("ripgrep"),
+		Type:    appconfig.InstallerTypeNix,
+		Verbose: lo.ToPtr(true),
+		Opts:    &map[string]any{"priority": 4, "flags": "--impure"},
+	})
+	assert.Equal(t, []string{"profile", "install", "--verbose", "--priority", "4", "--impure", "nixpkgs#ripgrep"}, installer.installArgs())
+	assert.Equal(t, []string{"profile", "upgrade", "--verbose", "--impure", "0"}, installer.updateArgs("0"))
+}
+
+func TestParseNixProfileList(t *testing.T) {
+	t.Run("named elements", func(t *testing.T) {
+		output := []byte(`{
+  "version": 3,
+  "elements": {
+    "ripgrep": {
+      "active": true,
+      "attrPath": "legacyPackages.x86_64-linux.ripgrep",
+      "originalUrl": "flake:nixpkgs",
+      "storePaths": ["/nix/store/abc-ripgrep-14.1.0"]
+    },
+    "hello": {
+      "active": true,
+      "attrPath": "packages.x86_64-linux.default",
+      "originalUrl": "github:owner/hello",
+      "storePaths": ["/nix/store/def-hello-2.12"]
+    }
+  }
+}`)
+		elements, err := parseNixProfileList(output)
+		require.NoError(t, err)
+		require.Len(t, elements, 2)
+		assert.Equal(t, "hello", elements[0].Name)
+		assert.Equal(t, "ripgrep", elements[1].Name)
+		assert.Equal(t, []string{"/nix/store/abc-ripgrep-14.1.0"}, elements[1].StorePaths)
+	})
+
+	t.Run("indexed elements", func(t *testing.T) {
+		output := []byte(`{
+  "version": 2,
+  "elements": [
+    {"attrPath": "legacyPackages.x86_64-linux.ripgrep", "originalUrl": "flake:nixpkgs", "storePaths": []}
+  ]
+}`)
+		elements, err := parseNixProfileList(output)
+		require.NoError(t, err)
+		require.Len(t, elements, 1)
+		assert.Equal(t, "0", elements[0].Name)
+	})
+
+	t.Run("empty profile", func(t *testing.T) {
+		elements, err := parseNixProfileList([]byte(`{"version": 3, "elements": {}}`))
+		require.NoError(t, err)
+		assert.Empty(t, elements)
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		_, err := parseNixProfileList([]byte("not json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNixElementMatches(t *testing.T) {
+	ripgrep := nixProfileElement{AttrPath: "legacyPackages.x86_64-linux.ripgrep", OriginalURL: "flake:nixpkgs"}
+	assert.True(t, nixElementMatches(ripgrep, "nixpkgs", "ripgrep"))
+	assert.True(t, nixElementMatches(ripgrep, "flake:nixpkgs", "ripgrep"))
+	assert.True(t, nixElementMatches(ripgrep, "nixpkgs", "legacyPackages.x86_64-linux.ripgrep"))
+	assert.False(t, nixElementMatches(ripgrep, "nixpkgs", "grep"))
+	assert.False(t, nixElementMatches(ripgrep, "github:owner/repo", "ripgrep"))
+}
+
+func TestNixChecks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake nix binary")
+	}
+	logger.InitLogger(false)
+
+	// A fake nix binary with ripgrep installed from nixpkgs, and a newer build of it available.
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$2" in
+  list) echo '{"version": 3, "elements": {"ripgrep": {"attrPath": "legacyPackages.x86_64-linux.ripgrep", "originalUrl": "flake:nixpkgs", "storePaths": ["/nix/store/old-ripgrep"]}}}' ;;
+  --raw) echo /nix/store/new-ripgrep ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nix"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	installer := newTestNixInstaller(&appconfig.InstallerData{Name: lo.ToPtr("ripgrep"), Type: appconfig.InstallerTypeNix})
+	installed, err := installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.True(t, installed)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.True(t, needsUpdate)
+
+	installer = newTestNixInstaller(&appconfig.InstallerData{Name: lo.ToPtr("fd"), Type: appconfig.InstallerTypeNix})
+	installed, err = installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.False(t, installed)
+	needsUpdate, err = installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+}
@@ -21,11 +21,11 @@ type NpmInstaller struct {
 // NpmOpts represents options for the NpmInstaller.
 type NpmOpts struct {
 	// Flags is a string of additional flags to pass to the npm/pnpm/yarn command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // NpmPackageManager represents a Node.js package manager type.
@@ -47,6 +47,11 @@ func (i *NpmInstaller) Validate() []ValidationError {
 
 // Install implements IInstaller.
 func (i *NpmInstaller) Install() error {
+	return i.InstallPackages([]string{*i.Info.Name})
+}
+
+// InstallPackages implements IBatchInstaller.
+func (i *NpmInstaller) InstallPackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"install", "--global"}
 	if i.IsVerbose() {
@@ -57,12 +62,17 @@ func (i *NpmInstaller) Install() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, *i.Info.Name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough(string(i.PackageManager), args...)
 }
 
 // Update implements IInstaller.
 func (i *NpmInstaller) Update() error {
+	return i.UpdatePackages([]string{*i.Info.Name})
+}
+
+// UpdatePackages implements IBatchInstaller.
+func (i *NpmInstaller) UpdatePackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"install", "--global"}
 	if i.IsVerbose() {
@@ -73,7 +83,9 @@ func (i *NpmInstaller) Update() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, *i.Info.Name+"@latest")
+	for _, name := range names {
+		args = append(args, name+"@latest")
+	}
 	return i.RunCmdPassThrough(string(i.PackageManager), args...)
 }
 
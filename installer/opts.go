@@ -0,0 +1,79 @@
+package installer
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+)
+
+// installerOpts maps each installer type to the options struct its installer reads from the
+// `opts` map. The yaml tags on each struct are the accepted opts keys.
+var installerOpts = map[appconfig.InstallerType]any{
+	appconfig.InstallerTypeGroup:         GroupOpts{},
+	appconfig.InstallerTypeShell:         ShellOpts{},
+	appconfig.InstallerTypeDocker:        DockerOpts{},
+	appconfig.InstallerTypeBrew:          BrewOpts{},
+	appconfig.InstallerTypeApt:           AptOpts{},
+	appconfig.InstallerTypeApk:           AptOpts{},
+	appconfig.InstallerTypeGit:           GitOpts{},
+	appconfig.InstallerTypeGitHubRelease: GitHubReleaseOpts{},
+	appconfig.InstallerTypeRsync:         RsyncOpts{},
+	appconfig.InstallerTypeNpm:           NpmOpts{},
+	appconfig.InstallerTypePnpm:          NpmOpts{},
+	appconfig.InstallerTypeYarn:          NpmOpts{},
+	appconfig.InstallerTypePipx:          PipxOpts{},
+	appconfig.InstallerTypeManifest:      ManifestOpts{},
+	appconfig.InstallerTypePacman:        PacmanOpts{},
+	appconfig.InstallerTypeYay:           PacmanOpts{},
+	appconfig.InstallerTypeCargo:         CargoOpts{},
+	appconfig.InstallerTypeGo:            GoOpts{},
+	appconfig.InstallerTypeDnf:           DnfOpts{},
+	appconfig.InstallerTypeYum:           DnfOpts{},
+	appconfig.InstallerTypeZypper:        DnfOpts{},
+	appconfig.InstallerTypeFlatpak:       FlatpakOpts{},
+	appconfig.InstallerTypeSnap:          SnapOpts{},
+	appconfig.InstallerTypeUv:            UvOpts{},
+	appconfig.InstallerTypeGem:           GemOpts{},
+	appconfig.InstallerTypeMise:          MiseOpts{},
+	appconfig.InstallerTypeNix:           NixOpts{},
+	appconfig.InstallerTypeURL:           URLOpts{},
+	appconfig.InstallerTypeLink:          LinkOpts{},
+}
+
+// GetOptsType returns the options struct type read by the given installer type.
+// The second return value is false if the installer type is unknown.
+func GetOptsType(t appconfig.InstallerType) (reflect.Type, bool) {
+	opts, ok := installerOpts[t]
+	if !ok {
+		return nil, false
+	}
+	return reflect.TypeOf(opts), true
+}
+
+// GetOptsKeys returns the opts keys accepted by the given installer type.
+// The second return value is false if the installer type is unknown.
+func GetOptsKeys(t appconfig.InstallerType) ([]string, bool) {
+	optsType, ok := GetOptsType(t)
+	if !ok {
+		return nil, false
+	}
+	return optsKeys(optsType), true
+}
+
+// optsKeys returns the yaml keys of an options struct type, including the keys of inlined structs.
+func optsKeys(optsType reflect.Type) []string {
+	keys := []string{}
+	for idx := range optsType.NumField() {
+		field := optsType.Field(idx)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if opts == "inline" {
+			keys = append(keys, optsKeys(field.Type)...)
+			continue
+		}
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,55 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOptsKeys(t *testing.T) {
+	t.Run("returns yaml keys of the opts struct", func(t *testing.T) {
+		keys, ok := GetOptsKeys(appconfig.InstallerTypeBrew)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"tap", "cask", "flags", "install_flags", "update_flags"}, keys)
+	})
+
+	t.Run("includes keys of inlined opts", func(t *testing.T) {
+		keys, ok := GetOptsKeys(appconfig.InstallerTypeGitHubRelease)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"repository", "provider", "api_url", "download_filename", "github_token"}, keys[:5])
+		assert.Contains(t, keys, "destination")
+		assert.Contains(t, keys, "extract_to")
+	})
+
+	t.Run("aliased types share opts", func(t *testing.T) {
+		npm, _ := GetOptsKeys(appconfig.InstallerTypeNpm)
+		yarn, _ := GetOptsKeys(appconfig.InstallerTypeYarn)
+		assert.Equal(t, npm, yarn)
+	})
+
+	t.Run("group has no opts", func(t *testing.T) {
+		keys, ok := GetOptsKeys(appconfig.InstallerTypeGroup)
+		assert.True(t, ok)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, ok := GetOptsKeys(appconfig.InstallerType("unknown"))
+		assert.False(t, ok)
+	})
+
+	t.Run("every supported installer type is registered", func(t *testing.T) {
+		for _, typ := range []appconfig.InstallerType{
+			appconfig.InstallerTypeGroup, appconfig.InstallerTypeShell, appconfig.InstallerTypeDocker,
+			appconfig.InstallerTypeBrew, appconfig.InstallerTypeApt, appconfig.InstallerTypeApk,
+			appconfig.InstallerTypeGit, appconfig.InstallerTypeGitHubRelease, appconfig.InstallerTypeRsync,
+			appconfig.InstallerTypeNpm, appconfig.InstallerTypePnpm, appconfig.InstallerTypeYarn,
+			appconfig.InstallerTypePipx, appconfig.InstallerTypeManifest, appconfig.InstallerTypePacman,
+			appconfig.InstallerTypeYay, appconfig.InstallerTypeCargo, appconfig.InstallerTypeGo,
+		} {
+			_, ok := GetOptsKeys(typ)
+			assert.True(t, ok, "missing opts for %s", typ)
+		}
+	})
+}
@@ -20,13 +20,13 @@ type PacmanInstaller struct {
 // PacmanOpts represents options for the PacmanInstaller.
 type PacmanOpts struct {
 	// Needed skips reinstalling up-to-date packages (--needed flag).
-	Needed *bool
+	Needed *bool `json:"needed"        yaml:"needed"`
 	// Flags is a string of additional flags to pass to the pacman/yay command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // PacmanPackageManager represents an Arch Linux package manager type.
@@ -46,7 +46,11 @@ func (i *PacmanInstaller) Validate() []ValidationError {
 
 // Install implements IInstaller.
 func (i *PacmanInstaller) Install() error {
-	name := *i.Info.Name
+	return i.InstallPackages([]string{*i.Info.Name})
+}
+
+// InstallPackages implements IBatchInstaller.
+func (i *PacmanInstaller) InstallPackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"-S", "--noconfirm"}
 	if i.IsVerbose() {
@@ -60,13 +64,17 @@ func (i *PacmanInstaller) Install() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough(string(i.PackageManager), args...)
 }
 
 // Update implements IInstaller.
 func (i *PacmanInstaller) Update() error {
-	name := *i.Info.Name
+	return i.UpdatePackages([]string{*i.Info.Name})
+}
+
+// UpdatePackages implements IBatchInstaller.
+func (i *PacmanInstaller) UpdatePackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"-S", "--noconfirm"}
 	if i.IsVerbose() {
@@ -80,7 +88,7 @@ func (i *PacmanInstaller) Update() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough(string(i.PackageManager), args...)
 }
 
@@ -19,11 +19,11 @@ type PipxInstaller struct {
 // PipxOpts represents options for the PipxInstaller.
 type PipxOpts struct {
 	// Flags is a string of additional flags to pass to the pipx command.
-	Flags *string
+	Flags *string `json:"flags"         yaml:"flags"`
 	// InstallFlags is a string of additional flags to pass only during install.
-	InstallFlags *string
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
 	// UpdateFlags is a string of additional flags to pass only during update.
-	UpdateFlags *string
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
 }
 
 // Validate validates the installer configuration.
@@ -34,7 +34,11 @@ func (i *PipxInstaller) Validate() []ValidationError {
 
 // Install implements IInstaller.
 func (i *PipxInstaller) Install() error {
-	name := *i.Info.Name
+	return i.InstallPackages([]string{*i.Info.Name})
+}
+
+// InstallPackages implements IBatchInstaller.
+func (i *PipxInstaller) InstallPackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"install"}
 	if i.IsVerbose() {
@@ -45,12 +49,17 @@ func (i *PipxInstaller) Install() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough("pipx", args...)
 }
 
 // Update implements IInstaller.
 func (i *PipxInstaller) Update() error {
+	return i.UpdatePackages([]string{*i.Info.Name})
+}
+
+// UpdatePackages implements IBatchInstaller.
+func (i *PipxInstaller) UpdatePackages(names []string) error {
 	opts := i.GetOpts()
 	args := []string{"upgrade"}
 	if i.IsVerbose() {
@@ -61,7 +70,7 @@ func (i *PipxInstaller) Update() error {
 	} else if opts.Flags != nil {
 		args = append(args, strings.Fields(*opts.Flags)...)
 	}
-	args = append(args, *i.Info.Name)
+	args = append(args, names...)
 	return i.RunCmdPassThrough("pipx", args...)
 }
 
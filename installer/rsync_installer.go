@@ -22,11 +22,11 @@ type RsyncInstaller struct {
 // RsyncOpts represents options for the RsyncInstaller.
 type RsyncOpts struct {
 	// Source is the source directory or file.
-	Source *string
+	Source *string `json:"source"      yaml:"source"`
 	// Destination is the destination directory or file.
-	Destination *string
+	Destination *string `json:"destination" yaml:"destination"`
 	// Flags is a string of flags to pass to the rsync command.
-	Flags *string
+	Flags *string `json:"flags"       yaml:"flags"`
 }
 
 // Validate validates the installer configuration.
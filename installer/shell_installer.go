@@ -17,9 +17,9 @@ type ShellInstaller struct {
 // ShellOpts represents options for the ShellInstaller.
 type ShellOpts struct {
 	// Command is the shell command to run for installation.
-	Command *string
+	Command *string `json:"command"        yaml:"command"`
 	// UpdateCommand is the shell command to run for updating. If not provided, the install command is used.
-	UpdateCommand *string
+	UpdateCommand *string `json:"update_command" yaml:"update_command"`
 }
 
 // Validate validates the installer configuration.
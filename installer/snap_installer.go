@@ -0,0 +1,149 @@
+package installer
+
+import (
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+)
+
+// SnapInstaller is an installer for snap packages.
+type SnapInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// SnapOpts represents options for the SnapInstaller.
+type SnapOpts struct {
+	// Channel is the channel to install and refresh the snap from (e.g. "latest/stable", "edge").
+	Channel *string `json:"channel"       yaml:"channel"`
+	// Classic installs the snap with classic confinement (--classic flag).
+	Classic *bool `json:"classic"       yaml:"classic"`
+	// Flags is a string of additional flags to pass to the snap command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+// Validate validates the installer configuration.
+func (i *SnapInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *SnapInstaller) Install() error {
+	return i.RunCmdPassThrough("snap", i.installArgs()...)
+}
+
+// installArgs returns the arguments to install the snap.
+func (i *SnapInstaller) installArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("install")
+	if opts.InstallFlags != nil {
+		args = append(args, strings.Fields(*opts.InstallFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// Update implements IInstaller.
+func (i *SnapInstaller) Update() error {
+	return i.RunCmdPassThrough("snap", i.updateArgs()...)
+}
+
+// updateArgs returns the arguments to refresh the snap.
+func (i *SnapInstaller) updateArgs() []string {
+	opts := i.GetOpts()
+	args := i.command("refresh")
+	if opts.UpdateFlags != nil {
+		args = append(args, strings.Fields(*opts.UpdateFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// command returns the arguments for a snap command, with the channel and confinement options.
+func (i *SnapInstaller) command(name string) []string {
+	opts := i.GetOpts()
+	args := []string{name}
+	if opts.Channel != nil {
+		args = append(args, "--channel="+*opts.Channel)
+	}
+	if i.IsClassic() {
+		args = append(args, "--classic")
+	}
+	return args
+}
+
+// CheckNeedsUpdate implements IInstaller.
+func (i *SnapInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	out, err := i.RunCmdGetOutput("snap", "refresh", "--list")
+	if err != nil {
+		return false, err
+	}
+	return listsPackage(string(out), *i.Info.Name), nil
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *SnapInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	return i.RunCmdGetSuccess("snap", "list", *i.Info.Name)
+}
+
+// GetData implements IInstaller.
+func (i *SnapInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the SnapInstaller.
+func (i *SnapInstaller) GetOpts() *SnapOpts {
+	opts := &SnapOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if channel, ok := (*info.Opts)["channel"].(string); ok {
+			opts.Channel = &channel
+		}
+		if classic, ok := (*info.Opts)["classic"].(bool); ok {
+			opts.Classic = &classic
+		}
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// IsClassic returns whether the snap is installed with classic confinement.
+func (i *SnapInstaller) IsClassic() bool {
+	opts := i.GetOpts()
+	return opts.Classic != nil && *opts.Classic
+}
+
+// NewSnapInstaller creates a new SnapInstaller.
+func NewSnapInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *SnapInstaller {
+	i := &SnapInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
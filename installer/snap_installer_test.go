@@ -0,0 +1,85 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSnapInstaller(data *appconfig.InstallerData) *SnapInstaller {
+	return NewSnapInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestSnapValidation(t *testing.T) {
+	logger.InitLogger(false)
+	installer := newTestSnapInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("code"),
+		Type: appconfig.InstallerTypeSnap,
+	})
+	assertNoValidationErrors(t, installer.Validate())
+}
+
+func TestSnapGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestSnapInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("code"),
+		Type: appconfig.InstallerTypeSnap,
+	})
+	opts := installer.GetOpts()
+	assert.Nil(t, opts.Channel)
+	assert.False(t, installer.IsClassic())
+
+	installer = newTestSnapInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("code"),
+		Type: appconfig.InstallerTypeSnap,
+		Opts: &map[string]any{
+			"channel":       "latest/edge",
+			"classic":       true,
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts = installer.GetOpts()
+	assert.Equal(t, "latest/edge", *opts.Channel)
+	assert.True(t, installer.IsClassic())
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+}
+
+func TestSnapArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestSnapInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("hello"),
+		Type: appconfig.InstallerTypeSnap,
+	})
+	assert.Equal(t, []string{"install", "hello"}, installer.installArgs())
+	assert.Equal(t, []string{"refresh", "hello"}, installer.updateArgs())
+
+	installer = newTestSnapInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("code"),
+		Type: appconfig.InstallerTypeSnap,
+		Opts: &map[string]any{"channel": "stable", "classic": true, "update_flags": "--amend"},
+	})
+	assert.Equal(t, []string{"install", "--channel=stable", "--classic", "code"}, installer.installArgs())
+	assert.Equal(t, []string{"refresh", "--channel=stable", "--classic", "--amend", "code"}, installer.updateArgs())
+}
+
+func TestSnapCheckNeedsUpdate(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestSnapInstaller(&appconfig.InstallerData{
+		Name:           lo.ToPtr("code"),
+		Type:           appconfig.InstallerTypeSnap,
+		CheckHasUpdate: lo.ToPtr("false"),
+	})
+	result, err := installer.CheckNeedsUpdate()
+	assert.NoError(t, err)
+	assert.False(t, result)
+}
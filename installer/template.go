@@ -2,6 +2,7 @@ package installer
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -28,6 +29,19 @@ type TemplateVars struct {
 	DeviceID string
 	// DeviceIDAlias is the friendly alias for the current machine, if one is defined in machine_aliases.
 	DeviceIDAlias string
+	// IsWSL is true when running under Windows Subsystem for Linux.
+	IsWSL bool
+	// IsContainer is true when running inside a container (e.g., docker, podman).
+	IsContainer bool
+	// IsCI is true when running in CI.
+	IsCI bool
+	// IsSSH is true when running in an SSH session.
+	IsSSH bool
+	// IsHeadless is true when no graphical display is available.
+	IsHeadless bool
+	// Package is the package being checked. Only populated for the per-package checks of
+	// installers with names.
+	Package string
 	// DownloadFile is the absolute path to the downloaded asset. Only populated for
 	// github-release custom extract commands.
 	DownloadFile string
@@ -72,18 +86,18 @@ func NewTemplateVars(tag string, machineAliases map[string]string) *TemplateVars
 		OS:            string(platform.GetPlatform()),
 		DeviceID:      deviceID,
 		DeviceIDAlias: resolveDeviceAlias(deviceID, machineAliases),
+		IsWSL:         platform.HasTrait(platform.TraitWSL),
+		IsContainer:   platform.HasTrait(platform.TraitContainer),
+		IsCI:          platform.HasTrait(platform.TraitCI),
+		IsSSH:         platform.HasTrait(platform.TraitSSH),
+		IsHeadless:    platform.HasTrait(platform.TraitHeadless),
 	}
 }
 
 // resolveDeviceAlias returns the alias for the given machine ID by reverse-looking up the aliases map.
 // Returns an empty string if no alias is found.
 func resolveDeviceAlias(machineID string, aliases map[string]string) string {
-	for alias, id := range aliases {
-		if id == machineID {
-			return alias
-		}
-	}
-	return ""
+	return machine.ResolveAlias(machineID, aliases)
 }
 
 // TemplateVarDescription describes a single template variable for display purposes
@@ -115,8 +129,14 @@ func DescribeTemplateVars(vars *TemplateVars) []TemplateVarDescription {
 		{Name: "{{ .ArchGnu }}", Value: vars.ArchGnu},
 		{Name: "{{ .DeviceID }}", Value: vars.DeviceID},
 		{Name: "{{ .DeviceIDAlias }}", Value: vars.DeviceIDAlias, Note: deviceAliasNote},
+		{Name: "{{ .IsWSL }}", Value: strconv.FormatBool(vars.IsWSL)},
+		{Name: "{{ .IsContainer }}", Value: strconv.FormatBool(vars.IsContainer)},
+		{Name: "{{ .IsCI }}", Value: strconv.FormatBool(vars.IsCI)},
+		{Name: "{{ .IsSSH }}", Value: strconv.FormatBool(vars.IsSSH)},
+		{Name: "{{ .IsHeadless }}", Value: strconv.FormatBool(vars.IsHeadless)},
 		{Name: "{{ .Tag }}", Note: "set per install from the resolved GitHub release tag"},
 		{Name: "{{ .Version }}", Note: "set per install (Tag without leading 'v')"},
+		{Name: "{{ .Package }}", Note: "set per package (check_installed/check_has_update of installers with names only)"},
 		{Name: "{{ .DownloadFile }}", Note: "set per install (github-release custom extract_command only)"},
 		{Name: "{{ .ExtractDir }}", Note: "set per install (github-release custom extract_command only)"},
 		{Name: "{{ .Destination }}", Note: "set per install (github-release custom extract_command only)"},
@@ -138,6 +138,34 @@ func TestApplyTemplateDeviceIDAlias(t *testing.T) {
 	assert.Equal(t, "config_workstation.yaml", result)
 }
 
+func TestApplyTemplateTraits(t *testing.T) {
+	logger.InitLogger(false)
+
+	platform.SetTraits([]platform.Trait{platform.TraitWSL, platform.TraitHeadless})
+	defer platform.ResetTraits()
+
+	vars := NewTemplateVars("", nil)
+	assert.True(t, vars.IsWSL)
+	assert.False(t, vars.IsContainer)
+	assert.False(t, vars.IsCI)
+	assert.False(t, vars.IsSSH)
+	assert.True(t, vars.IsHeadless)
+
+	result, err := ApplyTemplate("{{ if .IsWSL }}wsl{{ else }}native{{ end }}", vars, "test-installer")
+	assert.NoError(t, err)
+	assert.Equal(t, "wsl", result)
+
+	descs := DescribeTemplateVars(vars)
+	assert.Contains(t, descs, TemplateVarDescription{Name: "{{ .IsWSL }}", Value: "true"})
+	assert.Contains(t, descs, TemplateVarDescription{Name: "{{ .IsSSH }}", Value: "false"})
+}
+
+func TestApplyTemplatePackage(t *testing.T) {
+	result, err := ApplyTemplate("dpkg -s {{ .Package }}", &TemplateVars{Package: "ripgrep"}, "cli-tools")
+	assert.NoError(t, err)
+	assert.Equal(t, "dpkg -s ripgrep", result)
+}
+
 func TestApplyTemplateLegacySyntax(t *testing.T) {
 	logger.InitLogger(false)
 
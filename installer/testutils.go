@@ -70,6 +70,27 @@ func (m *MockInstaller) GetTemplateVars() *TemplateVars {
 	return m.templateVars
 }
 
+// MockBatchInstaller is a mock batch installer that records the packages it installs and updates.
+type MockBatchInstaller struct {
+	MockInstaller
+	// installed holds the packages of each InstallPackages call.
+	installed [][]string
+	// updated holds the packages of each UpdatePackages call.
+	updated [][]string
+}
+
+// InstallPackages records the packages to install.
+func (m *MockBatchInstaller) InstallPackages(names []string) error {
+	m.installed = append(m.installed, names)
+	return m.installError
+}
+
+// UpdatePackages records the packages to update.
+func (m *MockBatchInstaller) UpdatePackages(names []string) error {
+	m.updated = append(m.updated, names)
+	return m.updateError
+}
+
 // simulateBrewCheck simulates parsing output from `brew outdated --json`
 // along with handling the exit code semantics.
 
@@ -0,0 +1,260 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/platform"
+)
+
+// URLInstaller is an installer for binaries and archives downloaded from a URL.
+type URLInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+	// asset installs the downloaded file, using the same options and extraction as github-release.
+	asset *GitHubReleaseInstaller
+}
+
+// URLOpts represents options for the URLInstaller.
+type URLOpts struct {
+	// URL is the HTTP(S) URL to download, either a single URL or a per-platform map.
+	// Supports Go template syntax with the usual variables ({{ .Tag }}, {{ .Version }},
+	// {{ .Arch }}, {{ .OS }}, ...). {{ .Tag }} and {{ .Version }} are only set with VersionURL.
+	URL *platform.PlatformMap[string] `json:"url"         yaml:"url"`
+	// VersionURL is a URL that returns the latest version as plain text, e.g. "v1.30.2". Only
+	// the first line of the response is used. When not set, updates are detected with the
+	// ETag or Last-Modified headers of the download URL.
+	VersionURL *string `json:"version_url" yaml:"version_url"`
+	AssetOpts  `yaml:",inline"`
+}
+
+// Validate validates the installer configuration.
+func (i *URLInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	info := i.GetData()
+	opts := i.GetOpts()
+	if opts.URL == nil {
+		errors = append(errors, ValidationError{FieldName: "url", Message: validationIsRequired(), InstallerName: *info.Name})
+	} else if info.Platforms.GetShouldRunOnOS(platform.GetPlatform()) {
+		url := opts.URL.Resolve()
+		if url == nil || len(*url) == 0 {
+			errors = append(errors, ValidationError{FieldName: fmt.Sprintf("url.%s", platform.GetPlatform()), Message: validationIsRequired(), InstallerName: *info.Name})
+		} else if !isHTTPURL(*url) {
+			errors = append(errors, ValidationError{FieldName: "url", Message: validationInvalidFormat(), InstallerName: *info.Name})
+		}
+	}
+	errors = append(errors, i.validatePlatformMapOpt("url")...)
+	if opts.VersionURL != nil && !isHTTPURL(*opts.VersionURL) {
+		errors = append(errors, ValidationError{FieldName: "version_url", Message: validationInvalidFormat(), InstallerName: *info.Name})
+	}
+	errors = append(errors, i.asset.validateAsset()...)
+	return errors
+}
+
+// isHTTPURL returns whether url is an HTTP or HTTPS URL.
+func isHTTPURL(url string) bool {
+	url = strings.ToLower(url)
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// Install implements IInstaller.
+func (i *URLInstaller) Install() error {
+	name := *i.GetData().Name
+	tmpDir, err := os.MkdirTemp("", "sofmani")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() {
+		if rerr := os.RemoveAll(tmpDir); rerr != nil {
+			logger.Warn("failed to remove temp dir %s: %v", tmpDir, rerr)
+		}
+	}()
+	logger.Debug("Created temp directory: %s", tmpDir)
+
+	version, err := i.latestVersion()
+	if err != nil {
+		return err
+	}
+	templateVars := NewTemplateVars(version, i.asset.machineAliases())
+	url, err := i.GetURL(templateVars)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+	tmpFile := filepath.Join(tmpDir, name+".download")
+	header, err := downloadAsset(req, tmpFile)
+	if err != nil {
+		return err
+	}
+	if err := i.asset.installAsset(tmpDir, tmpFile, templateVars); err != nil {
+		return err
+	}
+	if version == "" {
+		version = urlValidator(header)
+	}
+	return i.asset.UpdateCache(version)
+}
+
+// Update implements IInstaller.
+func (i *URLInstaller) Update() error {
+	return i.Install()
+}
+
+// CheckNeedsUpdate implements IInstaller. The cached version is compared to the latest version
+// from the version URL, or to the ETag or Last-Modified header of the download URL.
+func (i *URLInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	latest, err := i.latestVersion()
+	if err != nil {
+		return false, err
+	}
+	if latest == "" {
+		latest, err = i.remoteValidator()
+		if err != nil {
+			return false, err
+		}
+		if latest == "" {
+			logger.Debug("%s has no ETag or Last-Modified header, can't check for updates", *i.Info.Name)
+			return false, nil
+		}
+	}
+	cached, err := i.asset.GetCachedTag()
+	if err != nil {
+		return false, err
+	}
+	return latest != cached, nil
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *URLInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	return i.asset.assetInstalled()
+}
+
+// latestVersion returns the latest version from the version URL, or an empty string if there is
+// no version URL.
+func (i *URLInstaller) latestVersion() (string, error) {
+	versionURL := i.GetOpts().VersionURL
+	if versionURL == nil {
+		return "", nil
+	}
+	logger.Debug("Getting latest version from %s", *versionURL)
+	resp, err := http.Get(*versionURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch latest version from %s: %w", *versionURL, err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			logger.Warn("failed to close response body: %v", cerr)
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to fetch latest version: %s returned status %d", *versionURL, resp.StatusCode)
+	}
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response from %s: %w", *versionURL, err)
+	}
+	line, _, _ := strings.Cut(string(contents), "\n")
+	version := strings.TrimSpace(line)
+	if version == "" {
+		return "", fmt.Errorf("no version returned from %s", *versionURL)
+	}
+	logger.Debug("Latest version is %s", version)
+	return version, nil
+}
+
+// remoteValidator returns the ETag or Last-Modified header of the download URL, from a HEAD
+// request.
+func (i *URLInstaller) remoteValidator() (string, error) {
+	url, err := i.GetURL(NewTemplateVars("", i.asset.machineAliases()))
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.Head(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s for updates: %w", url, err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			logger.Warn("failed to close response body: %v", cerr)
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to check for updates: %s returned status %d", url, resp.StatusCode)
+	}
+	return urlValidator(resp.Header), nil
+}
+
+// urlValidator returns the value that identifies the version of a downloaded file: its ETag
+// header, falling back to its Last-Modified header.
+func urlValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// GetURL returns the download URL for the current platform, rendered with vars.
+func (i *URLInstaller) GetURL(vars *TemplateVars) (string, error) {
+	opts := i.GetOpts()
+	if opts.URL == nil || opts.URL.Resolve() == nil || *opts.URL.Resolve() == "" {
+		return "", fmt.Errorf("no url matched for the current platform (%s/%s)", runtime.GOOS, runtime.GOARCH)
+	}
+	raw := *opts.URL.Resolve()
+	url, err := ApplyTemplate(raw, vars, *i.Info.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply template to url %q: %w", raw, err)
+	}
+	return url, nil
+}
+
+// GetData implements IInstaller.
+func (i *URLInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the URLInstaller.
+func (i *URLInstaller) GetOpts() *URLOpts {
+	opts := &URLOpts{AssetOpts: i.asset.GetOpts().AssetOpts}
+	info := i.Info
+	if info.Opts != nil {
+		if url, ok := (*info.Opts)["url"]; ok {
+			// Unknown platform keys are reported by Validate.
+			opts.URL, _ = platform.NewPlatformMap[string](url)
+		}
+		if versionURL, ok := (*info.Opts)["version_url"].(string); ok {
+			opts.VersionURL = &versionURL
+		}
+	}
+	return opts
+}
+
+// NewURLInstaller creates a new URLInstaller.
+func NewURLInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *URLInstaller {
+	i := &URLInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+		asset:         NewGitHubReleaseInstaller(cfg, installer),
+	}
+
+	return i
+}
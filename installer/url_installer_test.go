@@ -0,0 +1,280 @@
+package installer
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestURLInstaller(data *appconfig.InstallerData) *URLInstaller {
+	return NewURLInstaller(&appconfig.AppConfig{}, data)
+}
+
+// useTestCacheDir points the sofmani cache directory at a temp directory for the test.
+func useTestCacheDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the cache directory can't be redirected on windows")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+}
+
+func TestURLValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	valid := &appconfig.InstallerData{
+		Name: lo.ToPtr("kubectl"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{
+			"url":         "https://dl.k8s.io/release/{{ .Tag }}/bin/linux/amd64/kubectl",
+			"version_url": "https://dl.k8s.io/release/stable.txt",
+			"destination": "/tmp/bin",
+		},
+	}
+	assertNoValidationErrors(t, newTestURLInstaller(valid).Validate())
+
+	missingURL := &appconfig.InstallerData{
+		Name: lo.ToPtr("kubectl"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"destination": "/tmp/bin"},
+	}
+	assertValidationError(t, newTestURLInstaller(missingURL).Validate(), "url")
+
+	unknownPlatformKey := &appconfig.InstallerData{
+		Name: lo.ToPtr("kubectl"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{
+			"url": map[any]any{
+				"macos":         "https://example.com/kubectl-macos",
+				"linux":         "https://example.com/kubectl-linux",
+				"windows":       "https://example.com/kubectl.exe",
+				"linux/x86_64":  "https://example.com/kubectl-linux-amd64",
+				"linux/riscv64": "https://example.com/kubectl-linux-riscv64",
+			},
+			"destination": "/tmp/bin",
+		},
+	}
+	assertValidationError(t, newTestURLInstaller(unknownPlatformKey).Validate(), "url")
+
+	notHTTP := &appconfig.InstallerData{
+		Name: lo.ToPtr("kubectl"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{
+			"url":         "ftp://example.com/kubectl",
+			"version_url": "file:///tmp/version",
+			"destination": "/tmp/bin",
+		},
+	}
+	errors := newTestURLInstaller(notHTTP).Validate()
+	assertValidationError(t, errors, "url")
+	assertValidationError(t, errors, "version_url")
+
+	// The asset options are validated the same way as github-release.
+	missingDestination := &appconfig.InstallerData{
+		Name: lo.ToPtr("kubectl"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"url": "https://example.com/kubectl"},
+	}
+	assertValidationError(t, newTestURLInstaller(missingDestination).Validate(), "destination")
+
+	treeWithoutArchive := &appconfig.InstallerData{
+		Name: lo.ToPtr("go"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"url": "https://example.com/go.tar.gz", "extract_to": "/tmp/go"},
+	}
+	assertValidationError(t, newTestURLInstaller(treeWithoutArchive).Validate(), "strategy")
+}
+
+func TestURLGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestURLInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("go"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{
+			"url":              map[string]any{"linux": "https://go.dev/dl/{{ .Tag }}.linux-amd64.tar.gz"},
+			"version_url":      "https://go.dev/VERSION?m=text",
+			"strategy":         "tar",
+			"extract_to":       "/opt/go",
+			"strip_components": 1,
+			"bin_links":        []any{map[string]any{"source": "bin/go", "target": "/usr/local/bin/go"}},
+		},
+	})
+	opts := installer.GetOpts()
+	assert.Equal(t, "https://go.dev/dl/{{ .Tag }}.linux-amd64.tar.gz", *opts.URL.Linux)
+	assert.Equal(t, "https://go.dev/VERSION?m=text", *opts.VersionURL)
+	assert.Equal(t, GitHubReleaseInstallStrategyTar, *opts.Strategy)
+	assert.Equal(t, "/opt/go", *opts.ExtractTo)
+	assert.Equal(t, 1, *opts.StripComponents)
+	assert.Equal(t, []GitHubReleaseBinLink{{Source: "bin/go", Target: "/usr/local/bin/go"}}, opts.BinLinks)
+}
+
+func TestURLGetURL(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestURLInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("tool"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"url": "https://example.com/{{ .Tag }}/tool-{{ .Version }}-{{ .OS }}"},
+	})
+	url, err := installer.GetURL(NewTemplateVars("v1.2.3", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/v1.2.3/tool-1.2.3-"+string(platform.GetPlatform()), url)
+
+	// A URL only for another platform.
+	other := string(platform.PlatformWindows)
+	if platform.GetPlatform() == platform.PlatformWindows {
+		other = string(platform.PlatformLinux)
+	}
+	installer = newTestURLInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("tool"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"url": map[string]any{other: "https://example.com/tool"}},
+	})
+	_, err = installer.GetURL(NewTemplateVars("", nil))
+	assert.Error(t, err)
+}
+
+func TestURLValidator(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, "", urlValidator(header))
+	header.Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+	assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", urlValidator(header))
+	header.Set("ETag", `"abc123"`)
+	assert.Equal(t, `"abc123"`, urlValidator(header))
+}
+
+func TestURLInstallWithETag(t *testing.T) {
+	logger.InitLogger(false)
+	useTestCacheDir(t)
+
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte("#!/bin/sh\necho tool\n"))
+	}))
+	defer server.Close()
+
+	destination := t.TempDir()
+	installer := newTestURLInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("url-etag-tool"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"url": server.URL + "/tool", "destination": destination},
+	})
+
+	installed, err := installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.False(t, installed)
+
+	require.NoError(t, installer.Install())
+	contents, err := os.ReadFile(filepath.Join(destination, "url-etag-tool"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho tool\n", string(contents))
+
+	installed, err = installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.True(t, installed)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+
+	etag = `"v2"`
+	needsUpdate, err = installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.True(t, needsUpdate)
+}
+
+func TestURLInstallWithVersionURL(t *testing.T) {
+	logger.InitLogger(false)
+	useTestCacheDir(t)
+
+	// A zip archive with the binary inside a versioned directory.
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("tool-1.2.3/bin/tool")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("binary"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	version := "v1.2.3"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(version + "\ntime 2024-01-01\n"))
+	})
+	mux.HandleFunc("/download/v1.2.3/tool.zip", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive.Bytes())
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	extractTo := filepath.Join(dir, "tool")
+	link := filepath.Join(dir, "bin", "tool")
+	installer := newTestURLInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("url-version-tool"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{
+			"url":              server.URL + "/download/{{ .Tag }}/tool.zip",
+			"version_url":      server.URL + "/version",
+			"strategy":         "zip",
+			"extract_to":       extractTo,
+			"strip_components": 1,
+			"bin_links":        []any{map[string]any{"source": "bin/tool", "target": link}},
+		},
+	})
+	assertNoValidationErrors(t, installer.Validate())
+
+	require.NoError(t, installer.Install())
+	contents, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(contents))
+
+	cached, err := installer.asset.GetCachedTag()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", cached)
+
+	installed, err := installer.CheckIsInstalled()
+	require.NoError(t, err)
+	assert.True(t, installed)
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+
+	version = "v1.3.0"
+	needsUpdate, err = installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.True(t, needsUpdate)
+}
+
+func TestURLCheckNeedsUpdateWithoutValidators(t *testing.T) {
+	logger.InitLogger(false)
+	useTestCacheDir(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	installer := newTestURLInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("url-no-validator-tool"),
+		Type: appconfig.InstallerTypeURL,
+		Opts: &map[string]any{"url": server.URL + "/tool", "destination": t.TempDir()},
+	})
+	needsUpdate, err := installer.CheckNeedsUpdate()
+	require.NoError(t, err)
+	assert.False(t, needsUpdate)
+}
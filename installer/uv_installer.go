@@ -0,0 +1,190 @@
+package installer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+)
+
+// UvInstaller is an installer for Python tools installed with `uv tool`.
+type UvInstaller struct {
+	InstallerBase
+	// Config is the application configuration.
+	Config *appconfig.AppConfig
+	// Info is the installer data.
+	Info *appconfig.InstallerData
+}
+
+// UvOpts represents options for the UvInstaller.
+type UvOpts struct {
+	// Python is the Python version to install the tool with (--python flag), e.g. "3.12".
+	Python *string `json:"python"        yaml:"python"`
+	// With is a list of extra packages to install with the tool (--with flag).
+	With *[]string `json:"with"          yaml:"with"`
+	// Flags is a string of additional flags to pass to the uv command.
+	Flags *string `json:"flags"         yaml:"flags"`
+	// InstallFlags is a string of additional flags to pass only during install.
+	InstallFlags *string `json:"install_flags" yaml:"install_flags"`
+	// UpdateFlags is a string of additional flags to pass only during update.
+	UpdateFlags *string `json:"update_flags"  yaml:"update_flags"`
+}
+
+// Validate validates the installer configuration.
+func (i *UvInstaller) Validate() []ValidationError {
+	errors := i.BaseValidate()
+	info := i.GetData()
+	if info.Opts != nil {
+		// Unquoted versions are parsed as numbers, which turns e.g. 3.10 into 3.1.
+		if python, ok := (*info.Opts)["python"]; ok {
+			if _, ok := python.(string); !ok {
+				errors = append(errors, ValidationError{FieldName: "python", Message: "Must be a quoted string, e.g. \"3.12\"", InstallerName: *info.Name})
+			}
+		}
+	}
+	return errors
+}
+
+// Install implements IInstaller.
+func (i *UvInstaller) Install() error {
+	return i.RunCmdPassThrough("uv", i.installArgs()...)
+}
+
+// installArgs returns the arguments to install the tool.
+func (i *UvInstaller) installArgs() []string {
+	opts := i.GetOpts()
+	args := []string{"tool", "install"}
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	if opts.Python != nil {
+		args = append(args, "--python", *opts.Python)
+	}
+	if opts.With != nil {
+		for _, pkg := range *opts.With {
+			args = append(args, "--with", pkg)
+		}
+	}
+	if opts.InstallFlags != nil {
+		args = append(args, strings.Fields(*opts.InstallFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// Update implements IInstaller.
+func (i *UvInstaller) Update() error {
+	return i.RunCmdPassThrough("uv", i.updateArgs()...)
+}
+
+// updateArgs returns the arguments to upgrade the tool.
+func (i *UvInstaller) updateArgs() []string {
+	opts := i.GetOpts()
+	args := []string{"tool", "upgrade"}
+	if i.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+	if opts.UpdateFlags != nil {
+		args = append(args, strings.Fields(*opts.UpdateFlags)...)
+	} else if opts.Flags != nil {
+		args = append(args, strings.Fields(*opts.Flags)...)
+	}
+	return append(args, *i.Info.Name)
+}
+
+// CheckNeedsUpdate implements IInstaller.
+func (i *UvInstaller) CheckNeedsUpdate() (bool, error) {
+	if i.HasCustomUpdateCheck() {
+		return i.RunCustomUpdateCheck()
+	}
+	out, err := i.RunCmdGetOutput("uv", "tool", "list", "--outdated")
+	if err != nil {
+		return false, err
+	}
+	return uvToolListed(string(out), *i.Info.Name), nil
+}
+
+// CheckIsInstalled implements IInstaller.
+func (i *UvInstaller) CheckIsInstalled() (bool, error) {
+	if i.HasCustomInstallCheck() {
+		return i.RunCustomInstallCheck()
+	}
+	out, err := i.RunCmdGetOutput("uv", "tool", "list")
+	if err != nil {
+		return false, err
+	}
+	return uvToolListed(string(out), *i.Info.Name), nil
+}
+
+// uvToolListed returns whether the output of `uv tool list` lists the given tool. Each tool is
+// listed as "<name> v<version>", followed by its executables as "- <executable>" lines. Names are
+// compared in their normalized form, so "foo_bar" matches the listed "foo-bar".
+func uvToolListed(output string, name string) bool {
+	name = normalizePythonPackageName(name)
+	for line := range strings.Lines(output) {
+		if strings.HasPrefix(line, "-") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 && normalizePythonPackageName(fields[0]) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonPackageNameSeparators matches the runs of separators that PEP 503 normalizes to "-".
+var pythonPackageNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonPackageName returns the PEP 503 normalized form of a Python package name:
+// lower-case, with runs of "-", "_" and "." replaced by a single "-".
+func normalizePythonPackageName(name string) string {
+	return pythonPackageNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// GetData implements IInstaller.
+func (i *UvInstaller) GetData() *appconfig.InstallerData {
+	return i.Info
+}
+
+// GetOpts returns the parsed options for the UvInstaller.
+func (i *UvInstaller) GetOpts() *UvOpts {
+	opts := &UvOpts{}
+	info := i.Info
+	if info.Opts != nil {
+		if python, ok := (*info.Opts)["python"].(string); ok {
+			opts.Python = &python
+		}
+		if with, ok := (*info.Opts)["with"].([]any); ok {
+			pkgs := []string{}
+			for _, pkg := range with {
+				if pkg, ok := pkg.(string); ok {
+					pkgs = append(pkgs, pkg)
+				}
+			}
+			opts.With = &pkgs
+		}
+		if flags, ok := (*info.Opts)["flags"].(string); ok {
+			opts.Flags = &flags
+		}
+		if installFlags, ok := (*info.Opts)["install_flags"].(string); ok {
+			opts.InstallFlags = &installFlags
+		}
+		if updateFlags, ok := (*info.Opts)["update_flags"].(string); ok {
+			opts.UpdateFlags = &updateFlags
+		}
+	}
+	return opts
+}
+
+// NewUvInstaller creates a new UvInstaller.
+func NewUvInstaller(cfg *appconfig.AppConfig, installer *appconfig.InstallerData) *UvInstaller {
+	i := &UvInstaller{
+		InstallerBase: InstallerBase{Data: installer},
+		Config:        cfg,
+		Info:          installer,
+	}
+
+	return i
+}
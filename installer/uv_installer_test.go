@@ -0,0 +1,129 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUvInstaller(data *appconfig.InstallerData) *UvInstaller {
+	return NewUvInstaller(&appconfig.AppConfig{}, data)
+}
+
+func TestUvValidation(t *testing.T) {
+	logger.InitLogger(false)
+
+	valid := newTestUvInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("ruff"),
+		Type: appconfig.InstallerTypeUv,
+		Opts: &map[string]any{"python": "3.10"},
+	})
+	assertNoValidationErrors(t, valid.Validate())
+
+	invalid := newTestUvInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("ruff"),
+		Type: appconfig.InstallerTypeUv,
+		Opts: &map[string]any{"python": 3.1},
+	})
+	errors := invalid.Validate()
+	require.Len(t, errors, 1)
+	assert.Equal(t, "python", errors[0].FieldName)
+}
+
+func TestUvGetOpts(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestUvInstaller(&appconfig.InstallerData{Name: lo.ToPtr("ruff"), Type: appconfig.InstallerTypeUv})
+	opts := installer.GetOpts()
+	assert.Nil(t, opts.Python)
+	assert.Nil(t, opts.With)
+
+	installer = newTestUvInstaller(&appconfig.InstallerData{
+		Name: lo.ToPtr("mkdocs"),
+		Type: appconfig.InstallerTypeUv,
+		Opts: &map[string]any{
+			"python":        "3.12",
+			"with":          []any{"mkdocs-material", "mkdocs-mermaid2-plugin"},
+			"flags":         "--common",
+			"install_flags": "--install-specific",
+			"update_flags":  "--update-specific",
+		},
+	})
+	opts = installer.GetOpts()
+	assert.Equal(t, "3.12", *opts.Python)
+	assert.Equal(t, []string{"mkdocs-material", "mkdocs-mermaid2-plugin"}, *opts.With)
+	assert.Equal(t, "--common", *opts.Flags)
+	assert.Equal(t, "--install-specific", *opts.InstallFlags)
+	assert.Equal(t, "--update-specific", *opts.UpdateFlags)
+}
+
+func TestUvArgs(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestUvInstaller(&appconfig.InstallerData{Name: lo.ToPtr("ruff"), Type: appconfig.InstallerTypeUv})
+	assert.Equal(t, []string{"tool", "install", "ruff"}, installer.installArgs())
+	assert.Equal(t, []string{"tool", "upgrade", "ruff"}, installer.updateArgs())
+
+	installer = newTestUvInstaller(&appconfig.InstallerData{
+		Name:    lo.ToPtr("mkdocs"),
+		Type:    appconfig.InstallerTypeUv,
+		Verbose: lo.ToPtr(true),
+		Opts: &map[string]any{
+			"python": "3.12",
+			"with":   []any{"mkdocs-material"},
+			"flags":  "--prerelease=allow",
+		},
+	})
+	assert.Equal(t,
+		[]string{"tool", "install", "--verbose", "--python", "3.12", "--with", "mkdocs-material", "--prerelease=allow", "mkdocs"},
+		installer.installArgs(),
+	)
+	assert.Equal(t, []string{"tool", "upgrade", "--verbose", "--prerelease=allow", "mkdocs"}, installer.updateArgs())
+}
+
+func TestUvToolListed(t *testing.T) {
+	output := `black v24.4.2
+- black
+- blackd
+ruff v0.5.0
+- ruff
+`
+	assert.True(t, uvToolListed(output, "black"))
+	assert.True(t, uvToolListed(output, "ruff"))
+	assert.False(t, uvToolListed(output, "blackd"))
+	assert.False(t, uvToolListed(output, "mypy"))
+	assert.False(t, uvToolListed("No tools installed\n", "ruff"))
+
+	outdated := "ruff v0.5.0 [latest: 0.6.0]\n- ruff\n"
+	assert.True(t, uvToolListed(outdated, "ruff"))
+
+	normalized := "foo-bar v1.0.0\n- foo-bar\npre-commit v3.7.1\n- pre-commit\n"
+	assert.True(t, uvToolListed(normalized, "foo_bar"))
+	assert.True(t, uvToolListed(normalized, "Foo.Bar"))
+	assert.True(t, uvToolListed(normalized, "Pre_-_Commit"))
+	assert.False(t, uvToolListed(normalized, "foobar"))
+}
+
+func TestNormalizePythonPackageName(t *testing.T) {
+	assert.Equal(t, "foo-bar", normalizePythonPackageName("foo_bar"))
+	assert.Equal(t, "foo-bar", normalizePythonPackageName("Foo.Bar"))
+	assert.Equal(t, "foo-bar", normalizePythonPackageName("FOO-_.-BAR"))
+	assert.Equal(t, "ruff", normalizePythonPackageName("ruff"))
+}
+
+func TestUvCheckIsInstalled(t *testing.T) {
+	logger.InitLogger(false)
+
+	installer := newTestUvInstaller(&appconfig.InstallerData{
+		Name:           lo.ToPtr("ruff"),
+		Type:           appconfig.InstallerTypeUv,
+		CheckInstalled: lo.ToPtr("false"),
+	})
+	result, err := installer.CheckIsInstalled()
+	assert.NoError(t, err)
+	assert.False(t, result)
+}
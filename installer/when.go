@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/chenasraf/sofmani/expr"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/chenasraf/sofmani/utils"
+)
+
+// InstallerWhenMatches evaluates the installer's "when" expression. Installers without one always
+// match.
+func InstallerWhenMatches(i IInstaller) (bool, error) {
+	when := i.GetData().When
+	if when == nil || *when == "" {
+		return true, nil
+	}
+	alias := ""
+	if vars := i.GetTemplateVars(); vars != nil {
+		alias = vars.DeviceIDAlias
+	}
+	return expr.Eval(*when, NewWhenFacts(i.GetData().Environ(), alias))
+}
+
+// NewWhenFacts returns the facts "when" expressions are evaluated against, for the current machine.
+// env holds the installer's environment variables, which take precedence over the process
+// environment.
+func NewWhenFacts(env []string, alias string) expr.Facts {
+	vars := utils.EnvSliceAsMap(env)
+	lookupEnv := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+	hostname, _ := os.Hostname()
+	distro := platform.GetDistro()
+	return expr.Facts{
+		Vars: map[string]string{
+			"os":             string(platform.GetPlatform()),
+			"arch":           string(platform.GetArch()),
+			"distro":         distro.ID,
+			"distro_version": distro.VersionID,
+			"hostname":       hostname,
+			"alias":          alias,
+		},
+		LookupEnv: lookupEnv,
+		HasCommand: func(name string) bool {
+			_, err := exec.LookPath(name)
+			return err == nil
+		},
+		FileExists: func(path string) bool {
+			exists, err := utils.PathExists(utils.GetRealPath(env, path))
+			return err == nil && exists
+		},
+		Version: func(tool string) string {
+			return toolVersion(env, tool)
+		},
+	}
+}
+
+var (
+	toolVersionMu    sync.Mutex
+	toolVersionCache = map[string]string{}
+)
+
+// toolVersion returns the version reported by "<tool> --version", or an empty string if the tool is
+// not installed. Each tool is only run once per sofmani run.
+func toolVersion(env []string, tool string) string {
+	toolVersionMu.Lock()
+	defer toolVersionMu.Unlock()
+	if version, ok := toolVersionCache[tool]; ok {
+		return version
+	}
+	version := ""
+	if _, err := exec.LookPath(tool); err == nil {
+		out, err := utils.RunCmdGetOutput(env, tool, "--version")
+		if err != nil {
+			logger.Debug("Failed to get version of %s: %v", tool, err)
+		}
+		version = expr.ExtractVersion(string(out))
+	}
+	toolVersionCache[tool] = version
+	return version
+}
@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/logger"
+	"github.com/chenasraf/sofmani/platform"
+	"github.com/chenasraf/sofmani/summary"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallerWhenMatches(t *testing.T) {
+	logger.InitLogger(false)
+	platform.SetOS("linux")
+	defer platform.SetOS(runtime.GOOS)
+	platform.SetDistro(platform.Distro{ID: "ubuntu", VersionID: "22.04"})
+	defer platform.ResetDistro()
+	t.Setenv("SOFMANI_WHEN_TEST", "process")
+
+	tests := []struct {
+		name     string
+		when     *string
+		env      *appconfig.EnvVars
+		expected bool
+	}{
+		{"no condition", nil, nil, true},
+		{"empty condition", lo.ToPtr(""), nil, true},
+		{"os and distro", lo.ToPtr(`os == "linux" && distro == "ubuntu" && distro_version >= "22.04"`), nil, true},
+		{"other os", lo.ToPtr(`os == "macos"`), nil, false},
+		{"alias", lo.ToPtr(`alias == "work"`), nil, true},
+		{"process env", lo.ToPtr(`env("SOFMANI_WHEN_TEST") == "process"`), nil, true},
+		{
+			"installer env takes precedence",
+			lo.ToPtr(`env("SOFMANI_WHEN_TEST") == "installer"`),
+			&appconfig.EnvVars{{Name: "SOFMANI_WHEN_TEST", Value: "installer"}},
+			true,
+		},
+		{"missing command", lo.ToPtr(`has("sofmani-missing-command")`), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := &MockInstaller{
+				data:         &appconfig.InstallerData{Name: lo.ToPtr("test"), When: tt.when, Env: tt.env},
+				templateVars: &TemplateVars{DeviceIDAlias: "work"},
+			}
+			result, err := InstallerWhenMatches(installer)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestToolVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake tool")
+	}
+	logger.InitLogger(false)
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "sofmani-fake-tool")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho \"fake-tool v1.2.3 (build 456)\"\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	assert.Equal(t, "1.2.3", toolVersion(nil, "sofmani-fake-tool"))
+	// The result is cached for the rest of the run.
+	require.NoError(t, os.Remove(tool))
+	assert.Equal(t, "1.2.3", toolVersion(nil, "sofmani-fake-tool"))
+	assert.Equal(t, "", toolVersion(nil, "sofmani-missing-tool"))
+}
+
+func TestRunInstallerWhen(t *testing.T) {
+	logger.InitLogger(false)
+	config := &appconfig.AppConfig{}
+
+	skipped := &MockInstaller{
+		data: &appconfig.InstallerData{Name: lo.ToPtr("skipped"), Type: appconfig.InstallerTypeBrew, When: lo.ToPtr(`false`)},
+	}
+	result, err := RunInstaller(config, skipped)
+	require.NoError(t, err)
+	assert.Equal(t, summary.ActionSkipped, result.Action)
+
+	invalid := &MockInstaller{
+		data: &appconfig.InstallerData{Name: lo.ToPtr("invalid"), Type: appconfig.InstallerTypeBrew, When: lo.ToPtr(`os in "linux"`)},
+	}
+	_, err = RunInstaller(config, invalid)
+	assert.ErrorContains(t, err, "when condition")
+}
+
+func TestBaseValidateWhen(t *testing.T) {
+	base := &InstallerBase{Data: &appconfig.InstallerData{Name: lo.ToPtr("test"), When: lo.ToPtr(`oss == "linux"`)}}
+	errors := base.BaseValidate()
+	require.Len(t, errors, 1)
+	assert.Equal(t, "when", errors[0].FieldName)
+	assert.Contains(t, errors[0].Message, `did you mean "os"?`)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/chenasraf/sofmani/platform"
 	"github.com/davecgh/go-spew/spew"
@@ -52,6 +53,38 @@ type Logger struct {
 var logger *Logger        // logger is the global logger instance.
 var customLogFile *string // customLogFile holds the custom log file path if set.
 
+var secrets []string     // secrets holds the values that are redacted from log output.
+var secretsMu sync.Mutex // secretsMu guards secrets.
+
+// secretMask replaces secret values in log output.
+const secretMask = "****"
+
+// AddSecret registers a value to be redacted from all log output, both in the console and in
+// the log file. Empty values are ignored.
+func AddSecret(value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if s == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+}
+
+// Redact replaces every registered secret value in text with a mask.
+func Redact(text string) string {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		text = strings.ReplaceAll(text, s, secretMask)
+	}
+	return text
+}
+
 // GetLogDir returns the appropriate log directory based on the operating system.
 func GetLogDir() string {
 	var logDir string
@@ -162,7 +195,7 @@ func processHighlights(text string, baseColorSeq string) string {
 
 // log is an internal helper function for logging messages with a specific level and color.
 func (l *Logger) log(level string, colorSeq string, format string, args ...any) {
-	message := fmt.Sprintf("[%s] %s", level, fmt.Sprintf(format, args...))
+	message := Redact(fmt.Sprintf("[%s] %s", level, fmt.Sprintf(format, args...)))
 
 	// Write to file (strip all highlight markers - file should have no colors)
 	fileMessage := stripHighlightMarkers(message)
@@ -237,3 +237,14 @@ func TestProcessHighlights(t *testing.T) {
 		assert.Equal(t, ansiHighlight+"text"+ansiReset, result)
 	})
 }
+
+func TestRedact(t *testing.T) {
+	t.Cleanup(func() { secrets = nil })
+
+	AddSecret("hunter2")
+	AddSecret("hunter2")
+	AddSecret("  ")
+	assert.Equal(t, []string{"hunter2"}, secrets)
+	assert.Equal(t, "token=**** again ****", Redact("token=hunter2 again hunter2"))
+	assert.Equal(t, "nothing to hide", Redact("nothing to hide"))
+}
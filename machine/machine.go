@@ -1,6 +1,10 @@
 package machine
 
-import "github.com/samber/lo"
+import (
+	"sort"
+
+	"github.com/samber/lo"
+)
 
 // Machines defines which machines a configuration applies to.
 type Machines struct {
@@ -39,3 +43,17 @@ func containsMachineID(list []string, machineID string, aliases map[string]strin
 		return entry == machineID
 	})
 }
+
+// ResolveAlias returns the alias that maps to the given machine ID in the aliases map,
+// or an empty string if none does. When several aliases point at the same machine, the
+// alphabetically first one is returned so the result is stable between runs.
+func ResolveAlias(machineID string, aliases map[string]string) string {
+	names := lo.Keys(aliases)
+	sort.Strings(names)
+	for _, alias := range names {
+		if aliases[alias] == machineID {
+			return alias
+		}
+	}
+	return ""
+}
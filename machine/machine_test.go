@@ -175,3 +175,24 @@ func TestSetMachineID(t *testing.T) {
 		t.Errorf("After SetMachineID(%s), GetMachineID() = %s", customID, got)
 	}
 }
+
+func TestResolveAlias(t *testing.T) {
+	aliases := map[string]string{
+		"work-laptop":  "abc123",
+		"home-desktop": "def456",
+		"alt-laptop":   "abc123",
+	}
+
+	if got := ResolveAlias("def456", aliases); got != "home-desktop" {
+		t.Errorf("ResolveAlias() = %q, want %q", got, "home-desktop")
+	}
+	if got := ResolveAlias("abc123", aliases); got != "alt-laptop" {
+		t.Errorf("ResolveAlias() = %q, want first alias alphabetically %q", got, "alt-laptop")
+	}
+	if got := ResolveAlias("unknown", aliases); got != "" {
+		t.Errorf("ResolveAlias() = %q, want empty string", got)
+	}
+	if got := ResolveAlias("abc123", nil); got != "" {
+		t.Errorf("ResolveAlias() with nil aliases = %q, want empty string", got)
+	}
+}
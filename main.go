@@ -58,6 +58,11 @@ func runMain(cliConfig *appconfig.AppCliConfig) {
 		logger.Debug("%s", line)
 	}
 
+	if !checkUnknownConfigKeys(cliConfig.ConfigFile, cfg) {
+		logger.Error("Config key check failed in strict mode, exiting. Please fix the errors and try again.")
+		os.Exit(1)
+	}
+
 	// Set MACHINE_ID environment variable
 	machineID := machine.GetMachineID()
 	logger.Debug("Setting env MACHINE_ID=%s", machineID)
@@ -66,14 +71,20 @@ func runMain(cliConfig *appconfig.AppCliConfig) {
 		return
 	}
 
-	if cfg.Env != nil {
-		for k, v := range *cfg.Env {
-			logger.Debug("Setting env %s=%s", k, v)
-			err := os.Setenv(k, v)
-			if err != nil {
-				logger.Error("failed to set environment variable %s: %v", k, err)
-				return
-			}
+	// Apply env, platform_env and the PATH adjustments to the sofmani process, so they are visible
+	// to its own lookups (e.g. `which` checks) as well as to every command it runs.
+	environ, err := cfg.ResolveEnviron()
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+	for _, line := range environ {
+		k, v, _ := strings.Cut(line, "=")
+		logger.Debug("Setting env %s=%s", k, v)
+		err := os.Setenv(k, v)
+		if err != nil {
+			logger.Error("failed to set environment variable %s: %v", k, err)
+			return
 		}
 	}
 
@@ -143,6 +154,7 @@ func runMain(cliConfig *appconfig.AppCliConfig) {
 	interrupted := false
 
 	installSummary := summary.NewSummary()
+	var pendingCategory *appconfig.InstallerData
 	for _, item := range items {
 		// Check for interrupt before each item
 		select {
@@ -155,11 +167,16 @@ func runMain(cliConfig *appconfig.AppCliConfig) {
 			break
 		}
 
-		// Handle category entries - just log the header
+		// Handle category entries - the header is logged before the first installer under it that
+		// isn't skipped, so categories left empty by filters are hidden
 		if item.isCategory {
-			logger.Category(*item.data.Category, item.data.Desc, logger.CategoryDisplayMode(cfg.GetCategoryDisplay()))
+			pendingCategory = item.data
 			continue
 		}
+		if pendingCategory != nil && installer.SkipReason(cfg, item.installer) == "" {
+			logger.Category(*pendingCategory.Category, pendingCategory.Desc, logger.CategoryDisplayMode(cfg.GetCategoryDisplay()))
+			pendingCategory = nil
+		}
 
 		result, err := installer.RunInstaller(cfg, item.installer)
 		if err != nil {
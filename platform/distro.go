@@ -0,0 +1,181 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DistroID is a Linux distribution identifier, as found in the ID field of os-release.
+type DistroID string
+
+// Constants for well-known distributions. Derived distributions match these through ID_LIKE, e.g.
+// Linux Mint matches ubuntu and debian, and Manjaro matches arch.
+const (
+	DistroUbuntu   DistroID = "ubuntu"   // DistroUbuntu represents Ubuntu.
+	DistroDebian   DistroID = "debian"   // DistroDebian represents Debian and its derivatives.
+	DistroFedora   DistroID = "fedora"   // DistroFedora represents Fedora.
+	DistroRHEL     DistroID = "rhel"     // DistroRHEL represents Red Hat Enterprise Linux and its derivatives.
+	DistroArch     DistroID = "arch"     // DistroArch represents Arch Linux and its derivatives.
+	DistroAlpine   DistroID = "alpine"   // DistroAlpine represents Alpine Linux.
+	DistroNixOS    DistroID = "nixos"    // DistroNixOS represents NixOS.
+	DistroOpenSUSE DistroID = "opensuse" // DistroOpenSUSE represents openSUSE Leap and Tumbleweed.
+	DistroSUSE     DistroID = "suse"     // DistroSUSE represents SUSE Linux Enterprise and openSUSE.
+)
+
+// DistroIDs lists the well-known distributions, which can be used as PlatformMap keys.
+var DistroIDs = []DistroID{
+	DistroUbuntu,
+	DistroDebian,
+	DistroFedora,
+	DistroRHEL,
+	DistroArch,
+	DistroAlpine,
+	DistroNixOS,
+	DistroOpenSUSE,
+	DistroSUSE,
+}
+
+// Distro describes the current Linux distribution, as read from /etc/os-release.
+type Distro struct {
+	// ID is the lower-case distribution identifier (e.g., "ubuntu", "debian", "arch").
+	ID string
+	// IDLike lists the identifiers of distributions this one is derived from (e.g., ["debian"]).
+	IDLike []string
+	// VersionID is the distribution version (e.g., "22.04"). Empty for rolling releases.
+	VersionID string
+}
+
+// Is returns true if the distribution is id, or is derived from it according to ID_LIKE.
+func (d Distro) Is(id DistroID) bool {
+	return d.ID != "" && (d.ID == string(id) || slices.Contains(d.IDLike, string(id)))
+}
+
+// DistroConstraint matches a distribution by name and, optionally, version, e.g. "ubuntu>=22.04".
+type DistroConstraint struct {
+	// ID is the distribution to match. It matches the distribution ID or any ID_LIKE entry.
+	ID DistroID
+	// Op is the version comparison operator (==, !=, <, <=, > or >=). Empty if no version is given.
+	Op string
+	// Version is the version to compare VERSION_ID against.
+	Version string
+}
+
+// DistroConstraintPattern matches a distribution constraint, "name" or "name<op>version".
+var DistroConstraintPattern = regexp.MustCompile(`^([a-z0-9._-]+)\s*(?:(==|!=|<=|>=|=|<|>)\s*(v?\d+(?:\.\d+)*))?$`)
+
+// ParseDistroConstraint parses a distribution constraint, e.g. "debian" or "ubuntu>=22.04".
+func ParseDistroConstraint(s string) (DistroConstraint, error) {
+	match := DistroConstraintPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return DistroConstraint{}, fmt.Errorf("invalid distro %q, expected e.g. \"debian\" or \"ubuntu>=22.04\"", s)
+	}
+	op := match[2]
+	if op == "=" {
+		op = "=="
+	}
+	return DistroConstraint{ID: DistroID(match[1]), Op: op, Version: match[3]}, nil
+}
+
+// Matches returns true if the distribution satisfies the constraint. Version comparisons only apply
+// to the distribution itself, not to the ones it is derived from, since their versions differ.
+func (c DistroConstraint) Matches(d Distro) bool {
+	if c.Op == "" {
+		return d.Is(c.ID)
+	}
+	if d.ID != string(c.ID) {
+		return false
+	}
+	cmp, ok := CompareVersions(d.VersionID, c.Version)
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// osReleaseFiles are the os-release locations, in lookup order.
+var osReleaseFiles = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+var (
+	distroValue *Distro    // distroValue caches the detected distribution.
+	distroMu    sync.Mutex // distroMu guards distroValue.
+)
+
+// GetDistro returns the current Linux distribution. It returns an empty Distro on other platforms,
+// or when os-release can't be read. It caches the value after the first call.
+func GetDistro() Distro {
+	distroMu.Lock()
+	defer distroMu.Unlock()
+	if distroValue == nil {
+		d := Distro{}
+		if getOS() == "linux" {
+			d = readOSRelease()
+		}
+		distroValue = &d
+	}
+	return *distroValue
+}
+
+// SetDistro overrides the detected distribution. This is primarily used for testing.
+func SetDistro(d Distro) {
+	distroMu.Lock()
+	defer distroMu.Unlock()
+	distroValue = &d
+}
+
+// ResetDistro clears the cached distribution, so it is detected again on the next call.
+// This is primarily used for testing.
+func ResetDistro() {
+	distroMu.Lock()
+	defer distroMu.Unlock()
+	distroValue = nil
+}
+
+// readOSRelease reads the first os-release file that exists.
+func readOSRelease() Distro {
+	for _, file := range osReleaseFiles {
+		content, err := os.ReadFile(file)
+		if err == nil {
+			return ParseOSRelease(string(content))
+		}
+	}
+	return Distro{}
+}
+
+// ParseOSRelease parses the content of an os-release file.
+func ParseOSRelease(content string) Distro {
+	d := Distro{}
+	for line := range strings.SplitSeq(content, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			d.ID = strings.ToLower(value)
+		case "ID_LIKE":
+			d.IDLike = strings.Fields(strings.ToLower(value))
+		case "VERSION_ID":
+			d.VersionID = value
+		}
+	}
+	return d
+}
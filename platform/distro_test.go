@@ -0,0 +1,116 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Distro
+	}{
+		{
+			"ubuntu",
+			`PRETTY_NAME="Ubuntu 22.04.4 LTS"
+NAME="Ubuntu"
+VERSION_ID="22.04"
+ID=ubuntu
+ID_LIKE=debian
+`,
+			Distro{ID: "ubuntu", IDLike: []string{"debian"}, VersionID: "22.04"},
+		},
+		{
+			"rolling release",
+			"NAME=\"Arch Linux\"\nID=arch\n# comment\nBUILD_ID=rolling\n",
+			Distro{ID: "arch"},
+		},
+		{
+			"multiple parents",
+			"ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID='9.3'\n",
+			Distro{ID: "rocky", IDLike: []string{"rhel", "centos", "fedora"}, VersionID: "9.3"},
+		},
+		{"empty", "", Distro{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseOSRelease(tt.content))
+		})
+	}
+}
+
+func TestSetDistro(t *testing.T) {
+	defer ResetDistro()
+	SetDistro(Distro{ID: "debian", VersionID: "12"})
+	assert.Equal(t, Distro{ID: "debian", VersionID: "12"}, GetDistro())
+}
+
+func TestDistroIs(t *testing.T) {
+	d := Distro{ID: "linuxmint", IDLike: []string{"ubuntu", "debian"}}
+	assert.True(t, d.Is("linuxmint"))
+	assert.True(t, d.Is(DistroUbuntu))
+	assert.True(t, d.Is(DistroDebian))
+	assert.False(t, d.Is(DistroArch))
+	assert.False(t, Distro{}.Is(""))
+}
+
+func TestParseDistroConstraint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DistroConstraint
+		wantErr  bool
+	}{
+		{"debian", DistroConstraint{ID: "debian"}, false},
+		{"ubuntu>=22.04", DistroConstraint{ID: "ubuntu", Op: ">=", Version: "22.04"}, false},
+		{"ubuntu = 24.04", DistroConstraint{ID: "ubuntu", Op: "==", Version: "24.04"}, false},
+		{"fedora!=40", DistroConstraint{ID: "fedora", Op: "!=", Version: "40"}, false},
+		{"opensuse-leap<15.6", DistroConstraint{ID: "opensuse-leap", Op: "<", Version: "15.6"}, false},
+		{"", DistroConstraint{}, true},
+		{"Ubuntu", DistroConstraint{}, true},
+		{"ubuntu>=", DistroConstraint{}, true},
+		{"ubuntu~22", DistroConstraint{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := ParseDistroConstraint(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, c)
+		})
+	}
+}
+
+func TestDistroConstraintMatches(t *testing.T) {
+	ubuntu := Distro{ID: "ubuntu", IDLike: []string{"debian"}, VersionID: "22.04"}
+	arch := Distro{ID: "arch"}
+	tests := []struct {
+		constraint string
+		distro     Distro
+		expected   bool
+	}{
+		{"ubuntu", ubuntu, true},
+		{"debian", ubuntu, true},
+		{"fedora", ubuntu, false},
+		{"ubuntu>=22.04", ubuntu, true},
+		{"ubuntu>22.04", ubuntu, false},
+		{"ubuntu<24.04", ubuntu, true},
+		{"ubuntu==22.4", ubuntu, true},
+		{"ubuntu!=22.04", ubuntu, false},
+		{"debian>=11", ubuntu, false},
+		{"arch", arch, true},
+		{"arch>=1", arch, false},
+		{"debian", Distro{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := ParseDistroConstraint(tt.constraint)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, c.Matches(tt.distro))
+		})
+	}
+}
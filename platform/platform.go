@@ -1,9 +1,13 @@
 package platform
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"runtime"
 	"slices"
+	"strings"
 
 	"github.com/samber/lo"
 )
@@ -46,16 +50,77 @@ const (
 	ArchArm64 Architecture = "arm64" // ArchArm64 represents ARM64 architecture.
 )
 
+// Architectures lists the supported architectures.
+var Architectures = []Architecture{ArchAmd64, ArchArm64}
+
+// ArchAliases maps other names of the supported architectures to them, e.g. x86_64 to amd64.
+var ArchAliases = map[string]Architecture{
+	"x86_64":  ArchAmd64,
+	"aarch64": ArchArm64,
+}
+
 // GetArch returns the current architecture (amd64 or arm64).
 func GetArch() Architecture {
-	switch getArch() {
-	case "amd64", "x86_64":
-		return ArchAmd64
-	case "arm64", "aarch64":
-		return ArchArm64
-	default:
-		return Architecture(getArch())
+	return NormalizeArch(getArch())
+}
+
+// NormalizeArch returns the architecture for a name or one of its aliases, e.g. amd64 for x86_64.
+// Unknown names are returned as-is.
+func NormalizeArch(name string) Architecture {
+	if arch, ok := ArchAliases[name]; ok {
+		return arch
+	}
+	return Architecture(name)
+}
+
+// Archs defines which architectures a configuration applies to.
+type Archs struct {
+	// Only specifies a list of architectures where the configuration should apply.
+	Only *[]Architecture `json:"only"   yaml:"only"`
+	// Except specifies a list of architectures where the configuration should not apply.
+	Except *[]Architecture `json:"except" yaml:"except"`
+}
+
+// containsArch checks if a list of architectures contains an architecture, accepting aliases such
+// as x86_64 and aarch64.
+func containsArch(archs []Architecture, arch Architecture) bool {
+	return slices.ContainsFunc(archs, func(entry Architecture) bool {
+		return NormalizeArch(string(entry)) == arch
+	})
+}
+
+// GetShouldRunOnArch determines if a configuration should run on the given architecture based on
+// the Only and Except fields of the Archs struct.
+func (a *Archs) GetShouldRunOnArch(arch Architecture) bool {
+	if a == nil {
+		return true
+	}
+	if a.Only != nil {
+		return containsArch(*a.Only, arch)
+	}
+	if a.Except != nil {
+		return !containsArch(*a.Except, arch)
+	}
+	return true
+}
+
+// Validate checks that every architecture is supported, or an alias of one.
+func (a *Archs) Validate() error {
+	if a == nil {
+		return nil
+	}
+	errs := []error{}
+	for _, archs := range []*[]Architecture{a.Only, a.Except} {
+		if archs == nil {
+			continue
+		}
+		for _, arch := range *archs {
+			if !slices.Contains(Architectures, NormalizeArch(string(arch))) {
+				errs = append(errs, fmt.Errorf("unknown architecture %q", arch))
+			}
+		}
 	}
+	return errors.Join(errs...)
 }
 
 // GetArchAlias returns the architecture in common alias format (x86_64 or arm64).
@@ -97,15 +162,27 @@ func GetPlatform() Platform {
 
 // Platforms defines which platforms a configuration applies to.
 type Platforms struct {
-	// Only specifies a list of platforms where the configuration should apply.
+	// Only specifies a list of platforms where the configuration should apply. Linux entries may
+	// name a distribution, e.g. "linux/debian" or "linux/ubuntu>=22.04".
 	Only *[]Platform `json:"only"   yaml:"only"`
-	// Except specifies a list of platforms where the configuration should not apply.
+	// Except specifies a list of platforms where the configuration should not apply. Linux entries
+	// may name a distribution, e.g. "linux/debian" or "linux/ubuntu>=22.04".
 	Except *[]Platform `json:"except" yaml:"except"`
+	// Distro specifies a list of Linux distributions where the configuration should apply, e.g.
+	// "debian" or "ubuntu>=22.04". It only matches on Linux.
+	Distro *[]string `json:"distro" yaml:"distro"`
 }
 
-// Platform represents an operating system platform.
+// Platform represents an operating system platform. On Linux, it may be followed by a distribution
+// constraint, e.g. "linux/debian" or "linux/ubuntu>=22.04".
 type Platform string
 
+// Split returns the operating system part of the platform, and its distribution constraint, if any.
+func (p Platform) Split() (Platform, string) {
+	os, distro, _ := strings.Cut(string(p), "/")
+	return Platform(os), distro
+}
+
 // Constants for supported platforms.
 const (
 	PlatformMacos   Platform = "macos"   // PlatformMacos represents macOS.
@@ -121,33 +198,150 @@ type PlatformMap[T any] struct {
 	Linux *T `json:"linux"   yaml:"linux"`
 	// Windows is the value for Windows.
 	Windows *T `json:"windows" yaml:"windows"`
+	// Distros holds values for specific Linux distributions, keyed by distribution (e.g. "debian").
+	// They take precedence over Linux.
+	Distros map[DistroID]*T `json:"-"       yaml:"-"`
+	// OSArch holds values for specific architectures of a platform, keyed by "os/arch" (e.g.
+	// "linux/arm64"). They take precedence over the platform and distribution values.
+	OSArch map[string]*T `json:"-"       yaml:"-"`
+}
+
+// platformMapPlatforms lists the platforms accepted as platform map keys.
+var platformMapPlatforms = []Platform{PlatformMacos, PlatformLinux, PlatformWindows}
+
+// PlatformMapKeys returns the keys accepted by platform maps: the platforms, the well-known
+// distributions, and "os/arch" combinations, including those naming an architecture alias (e.g.
+// "linux/x86_64").
+func PlatformMapKeys() []string {
+	keys := []string{}
+	for _, p := range platformMapPlatforms {
+		keys = append(keys, string(p))
+	}
+	for _, id := range DistroIDs {
+		keys = append(keys, string(id))
+	}
+	archs := []string{}
+	for _, arch := range Architectures {
+		archs = append(archs, string(arch))
+	}
+	archs = append(archs, slices.Sorted(maps.Keys(ArchAliases))...)
+	for _, p := range platformMapPlatforms {
+		for _, arch := range archs {
+			keys = append(keys, string(p)+"/"+arch)
+		}
+	}
+	return keys
+}
+
+// osArchKey returns the canonical form of an "os/arch" key, with the architecture alias resolved,
+// e.g. "linux/amd64" for "linux/x86_64". It returns false if key isn't a supported combination.
+func osArchKey(key string) (string, bool) {
+	os, arch, ok := strings.Cut(key, "/")
+	if !ok || !slices.Contains(platformMapPlatforms, Platform(os)) {
+		return "", false
+	}
+	normalized := NormalizeArch(arch)
+	if !slices.Contains(Architectures, normalized) {
+		return "", false
+	}
+	return os + "/" + string(normalized), true
+}
+
+// set sets the value for a platform or distribution key. It returns false for unknown keys.
+func (p *PlatformMap[T]) set(key string, value *T) bool {
+	switch Platform(key) {
+	case PlatformMacos:
+		p.MacOS = value
+	case PlatformLinux:
+		p.Linux = value
+	case PlatformWindows:
+		p.Windows = value
+	default:
+		switch {
+		case slices.Contains(DistroIDs, DistroID(key)):
+			if p.Distros == nil {
+				p.Distros = map[DistroID]*T{}
+			}
+			p.Distros[DistroID(key)] = value
+		default:
+			osArch, ok := osArchKey(key)
+			if !ok {
+				return false
+			}
+			if p.OSArch == nil {
+				p.OSArch = map[string]*T{}
+			}
+			p.OSArch[osArch] = value
+		}
+	}
+	return true
+}
+
+// UnmarshalYAML reads platform and distribution keys. Unknown keys are ignored here and reported by
+// the strict config check.
+func (p *PlatformMap[T]) UnmarshalYAML(unmarshal func(any) error) error {
+	values := map[string]T{}
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+	*p = PlatformMap[T]{}
+	for key, value := range values {
+		p.set(key, &value)
+	}
+	return nil
+}
+
+// UnmarshalJSON reads platform and distribution keys. Unknown keys are ignored.
+func (p *PlatformMap[T]) UnmarshalJSON(data []byte) error {
+	values := map[string]T{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*p = PlatformMap[T]{}
+	for key, value := range values {
+		p.set(key, &value)
+	}
+	return nil
 }
 
 // Resolve returns the value for the current platform from the PlatformMap.
-// It returns nil if no value is defined for the current platform.
+// A value for the current architecture ("os/arch") takes precedence over the platform value. On
+// Linux, a value for the current distribution, or one it is derived from, takes precedence over the
+// Linux value. It returns nil if no value is defined for the current platform.
 func (p *PlatformMap[T]) Resolve() *T {
 	if p == nil {
 		return nil
 	}
+	var os Platform
+	var value *T
 	switch getOS() {
 	case "darwin":
-		if p.MacOS != nil {
-			return p.MacOS
-		}
-		return nil
+		os, value = PlatformMacos, p.MacOS
 	case "linux":
-		if p.Linux != nil {
-			return p.Linux
+		os, value = PlatformLinux, p.Linux
+		if distro := p.resolveDistro(GetDistro()); distro != nil {
+			value = distro
 		}
-		return nil
 	case "windows":
-		if p.Windows != nil {
-			return p.Windows
-		}
-		return nil
+		os, value = PlatformWindows, p.Windows
 	default:
 		return nil
 	}
+	if osArch := p.OSArch[string(os)+"/"+string(GetArch())]; osArch != nil {
+		return osArch
+	}
+	return value
+}
+
+// resolveDistro returns the value for a distribution, trying its ID before the distributions it is
+// derived from, in ID_LIKE order.
+func (p *PlatformMap[T]) resolveDistro(d Distro) *T {
+	for _, id := range slices.Concat([]string{d.ID}, d.IDLike) {
+		if value := p.Distros[DistroID(id)]; value != nil {
+			return value
+		}
+	}
+	return nil
 }
 
 // ResolveWithFallback returns the value for the current platform from the PlatformMap.
@@ -165,22 +359,94 @@ func ContainsPlatform(platforms *[]Platform, platform Platform) bool {
 	return slices.Contains(*platforms, platform)
 }
 
+// MatchesPlatform checks if a platform entry matches the current operating system and, for entries
+// such as "linux/debian", the current distribution.
+func MatchesPlatform(entry Platform, curOS Platform) bool {
+	os, distro := entry.Split()
+	if os != curOS {
+		return false
+	}
+	if distro == "" {
+		return true
+	}
+	constraint, err := ParseDistroConstraint(distro)
+	return err == nil && constraint.Matches(GetDistro())
+}
+
+// matchesAny checks if any of the platform entries matches the current operating system.
+func matchesAny(entries []Platform, curOS Platform) bool {
+	return slices.ContainsFunc(entries, func(entry Platform) bool {
+		return MatchesPlatform(entry, curOS)
+	})
+}
+
 // GetShouldRunOnOS determines if a configuration should run on the current operating system
-// based on the Only and Except fields of the Platforms struct.
+// based on the Only, Except and Distro fields of the Platforms struct.
 func (p *Platforms) GetShouldRunOnOS(curOS Platform) bool {
 	if p == nil {
 		return true
 	}
 
 	if p.Only != nil {
-		return ContainsPlatform(p.Only, curOS)
+		if !matchesAny(*p.Only, curOS) {
+			return false
+		}
+	} else if p.Except != nil {
+		if matchesAny(*p.Except, curOS) {
+			return false
+		}
 	}
-	if p.Except != nil {
-		return !ContainsPlatform(p.Except, curOS)
+	if p.Distro != nil {
+		if curOS != PlatformLinux {
+			return false
+		}
+		d := GetDistro()
+		return slices.ContainsFunc(*p.Distro, func(entry string) bool {
+			constraint, err := ParseDistroConstraint(entry)
+			return err == nil && constraint.Matches(d)
+		})
 	}
 	return true
 }
 
+// Validate checks that every platform and distribution entry is well-formed.
+func (p *Platforms) Validate() error {
+	if p == nil {
+		return nil
+	}
+	errs := []error{}
+	for _, entries := range []*[]Platform{p.Only, p.Except} {
+		if entries == nil {
+			continue
+		}
+		for _, entry := range *entries {
+			os, distro := entry.Split()
+			if !slices.Contains([]Platform{PlatformMacos, PlatformLinux, PlatformWindows}, os) {
+				errs = append(errs, fmt.Errorf("unknown platform %q", entry))
+				continue
+			}
+			if !strings.Contains(string(entry), "/") {
+				continue
+			}
+			if os != PlatformLinux {
+				errs = append(errs, fmt.Errorf("platform %q: only linux entries can name a distribution", entry))
+				continue
+			}
+			if _, err := ParseDistroConstraint(distro); err != nil {
+				errs = append(errs, fmt.Errorf("platform %q: %w", entry, err))
+			}
+		}
+	}
+	if p.Distro != nil {
+		for _, entry := range *p.Distro {
+			if _, err := ParseDistroConstraint(entry); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // DockerOSMap is a PlatformMap that defines the Docker OS for each platform.
 var DockerOSMap = PlatformMap[string]{
 	MacOS:   lo.ToPtr("linux"),
@@ -197,35 +463,31 @@ func ParsePlatformSingleValue[T any](value T) *PlatformMap[T] {
 	return p
 }
 
-// ParselatformMap creates a PlatformMap from a map of platform strings to values.
-func ParselatformMap[T any](values map[string]T) *PlatformMap[T] {
+// ParselatformMap creates a PlatformMap from a map of platform or distribution strings to values.
+// It returns an error naming the unknown keys, along with a map of the known ones.
+func ParselatformMap[T any](values map[string]T) (*PlatformMap[T], error) {
 	p := &PlatformMap[T]{}
-	for k, v := range values {
-		val := v // capture value for pointer
-		switch Platform(k) {
-		case PlatformMacos:
-			p.MacOS = &val
-		case PlatformLinux:
-			p.Linux = &val
-		case PlatformWindows:
-			p.Windows = &val
-		default:
-			panic(fmt.Sprintf("Unsupported platform key: %q", k))
+	errs := []error{}
+	for _, k := range slices.Sorted(maps.Keys(values)) {
+		val := values[k] // copy value for pointer
+		if !p.set(k, &val) {
+			errs = append(errs, fmt.Errorf("unsupported platform key %q", k))
 		}
 	}
-	return p
+	return p, errors.Join(errs...)
 }
 
-// NewPlatformMap creates a new PlatformMap from either a single value or a map.
-func NewPlatformMap[T any](input any) *PlatformMap[T] {
+// NewPlatformMap creates a new PlatformMap from either a single value or a map. It returns an error
+// for unknown map keys, along with a map of the known ones, and for unsupported input types.
+func NewPlatformMap[T any](input any) (*PlatformMap[T], error) {
 	switch v := input.(type) {
 	case nil:
-		return nil
+		return nil, nil
 	case *T:
 		if v != nil {
-			return ParsePlatformSingleValue(*v)
+			return ParsePlatformSingleValue(*v), nil
 		}
-		return nil
+		return nil, nil
 	case map[string]T:
 		return ParselatformMap(v)
 	case map[string]*T:
@@ -257,8 +519,8 @@ func NewPlatformMap[T any](input any) *PlatformMap[T] {
 		}
 		return ParselatformMap(flat)
 	case T:
-		return ParsePlatformSingleValue(v)
+		return ParsePlatformSingleValue(v), nil
 	default:
-		panic(fmt.Sprintf("NewPlatformMap: unsupported input type %T", input))
+		return nil, fmt.Errorf("unsupported platform map value of type %T", input)
 	}
 }
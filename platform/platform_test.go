@@ -1,11 +1,14 @@
 package platform
 
 import (
+	"encoding/json"
 	"runtime"
 	"testing"
 
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestGetCurrentPlatform(t *testing.T) {
@@ -70,6 +73,62 @@ func TestGetShouldRunOnOSEdgeCases(t *testing.T) {
 	})
 }
 
+func TestGetShouldRunOnOSDistro(t *testing.T) {
+	defer ResetDistro()
+	SetDistro(Distro{ID: "ubuntu", IDLike: []string{"debian"}, VersionID: "22.04"})
+
+	tests := []struct {
+		name      string
+		platforms Platforms
+		curOS     Platform
+		expected  bool
+	}{
+		{"only matching distro", Platforms{Only: &[]Platform{"linux/ubuntu"}}, PlatformLinux, true},
+		{"only parent distro", Platforms{Only: &[]Platform{"linux/debian"}}, PlatformLinux, true},
+		{"only other distro", Platforms{Only: &[]Platform{"linux/arch"}}, PlatformLinux, false},
+		{"only distro on macos", Platforms{Only: &[]Platform{"linux/ubuntu"}}, PlatformMacos, false},
+		{"only distro or macos", Platforms{Only: &[]Platform{"linux/arch", PlatformMacos}}, PlatformMacos, true},
+		{"only distro version", Platforms{Only: &[]Platform{"linux/ubuntu>=22.04"}}, PlatformLinux, true},
+		{"only newer distro version", Platforms{Only: &[]Platform{"linux/ubuntu>=24.04"}}, PlatformLinux, false},
+		{"except matching distro", Platforms{Except: &[]Platform{"linux/debian"}}, PlatformLinux, false},
+		{"except other distro", Platforms{Except: &[]Platform{"linux/fedora"}}, PlatformLinux, true},
+		{"distro list", Platforms{Distro: &[]string{"fedora", "ubuntu>=22.04"}}, PlatformLinux, true},
+		{"distro list no match", Platforms{Distro: &[]string{"ubuntu<22.04"}}, PlatformLinux, false},
+		{"distro list on macos", Platforms{Distro: &[]string{"ubuntu"}}, PlatformMacos, false},
+		{"distro and only", Platforms{Only: &[]Platform{PlatformLinux}, Distro: &[]string{"alpine"}}, PlatformLinux, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.platforms.GetShouldRunOnOS(tt.curOS))
+		})
+	}
+}
+
+func TestPlatformsValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		platforms *Platforms
+		errMsg    string
+	}{
+		{"nil", nil, ""},
+		{"valid", &Platforms{Only: &[]Platform{PlatformMacos, "linux/ubuntu>=22.04"}, Distro: &[]string{"debian"}}, ""},
+		{"unknown platform", &Platforms{Only: &[]Platform{"freebsd"}}, `unknown platform "freebsd"`},
+		{"distro on macos", &Platforms{Except: &[]Platform{"macos/ubuntu"}}, "only linux entries can name a distribution"},
+		{"empty distro", &Platforms{Only: &[]Platform{"linux/"}}, `invalid distro ""`},
+		{"invalid distro entry", &Platforms{Distro: &[]string{"ubuntu>=jammy"}}, `invalid distro "ubuntu>=jammy"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.platforms.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
 func TestGetArch(t *testing.T) {
 	originalArch := archValue
 	defer func() { SetArch(originalArch) }()
@@ -100,6 +159,47 @@ func TestGetArch(t *testing.T) {
 	})
 }
 
+func TestGetShouldRunOnArch(t *testing.T) {
+	tests := []struct {
+		name     string
+		archs    *Archs
+		arch     Architecture
+		expected bool
+	}{
+		{"nil", nil, ArchArm64, true},
+		{"empty", &Archs{}, ArchArm64, true},
+		{"only matching", &Archs{Only: &[]Architecture{ArchArm64}}, ArchArm64, true},
+		{"only other", &Archs{Only: &[]Architecture{ArchAmd64}}, ArchArm64, false},
+		{"only alias", &Archs{Only: &[]Architecture{"aarch64"}}, ArchArm64, true},
+		{"except matching", &Archs{Except: &[]Architecture{ArchArm64}}, ArchArm64, false},
+		{"except alias", &Archs{Except: &[]Architecture{"x86_64"}}, ArchAmd64, false},
+		{"except other", &Archs{Except: &[]Architecture{ArchAmd64}}, ArchArm64, true},
+		{"only takes precedence", &Archs{Only: &[]Architecture{ArchArm64}, Except: &[]Architecture{ArchArm64}}, ArchArm64, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.archs.GetShouldRunOnArch(tt.arch))
+		})
+	}
+}
+
+func TestArchsValidate(t *testing.T) {
+	assert.NoError(t, (*Archs)(nil).Validate())
+	assert.NoError(t, (&Archs{Only: &[]Architecture{ArchAmd64, "aarch64"}, Except: &[]Architecture{"x86_64"}}).Validate())
+	assert.ErrorContains(t, (&Archs{Only: &[]Architecture{"arm"}}).Validate(), `unknown architecture "arm"`)
+	assert.ErrorContains(t, (&Archs{Except: &[]Architecture{"x64"}}).Validate(), `unknown architecture "x64"`)
+}
+
+func TestPlatformMapKeys(t *testing.T) {
+	keys := PlatformMapKeys()
+	assert.Equal(t, []string{"macos", "linux", "windows"}, keys[:3])
+	assert.Contains(t, keys, "debian")
+	assert.Contains(t, keys, "linux/arm64")
+	assert.Contains(t, keys, "macos/amd64")
+	assert.Contains(t, keys, "linux/x86_64")
+	assert.NotContains(t, keys, "linux/riscv64")
+}
+
 func TestGetArchAlias(t *testing.T) {
 	originalArch := archValue
 	defer func() { SetArch(originalArch) }()
@@ -176,6 +276,74 @@ func TestPlatformMapResolve(t *testing.T) {
 		assert.Nil(t, pm.Resolve())
 	})
 
+	t.Run("returns distro value on linux", func(t *testing.T) {
+		SetOS("linux")
+		SetDistro(Distro{ID: "ubuntu", IDLike: []string{"debian"}})
+		defer ResetDistro()
+		pm := &PlatformMap[string]{
+			Linux:   lo.ToPtr("linux"),
+			Distros: map[DistroID]*string{DistroUbuntu: lo.ToPtr("ubuntu"), DistroDebian: lo.ToPtr("debian")},
+		}
+		assert.Equal(t, "ubuntu", *pm.Resolve())
+	})
+
+	t.Run("returns parent distro value on linux", func(t *testing.T) {
+		SetOS("linux")
+		SetDistro(Distro{ID: "linuxmint", IDLike: []string{"ubuntu", "debian"}})
+		defer ResetDistro()
+		pm := &PlatformMap[string]{
+			Linux:   lo.ToPtr("linux"),
+			Distros: map[DistroID]*string{DistroDebian: lo.ToPtr("debian")},
+		}
+		assert.Equal(t, "debian", *pm.Resolve())
+	})
+
+	t.Run("falls back to Linux value for other distros", func(t *testing.T) {
+		SetOS("linux")
+		SetDistro(Distro{ID: "arch"})
+		defer ResetDistro()
+		pm := &PlatformMap[string]{
+			Linux:   lo.ToPtr("linux"),
+			Distros: map[DistroID]*string{DistroDebian: lo.ToPtr("debian")},
+		}
+		assert.Equal(t, "linux", *pm.Resolve())
+	})
+
+	t.Run("returns os/arch value before platform value", func(t *testing.T) {
+		SetOS("darwin")
+		originalArch := archValue
+		defer SetArch(originalArch)
+		SetArch("arm64")
+		pm := &PlatformMap[string]{
+			MacOS:  lo.ToPtr("mac"),
+			OSArch: map[string]*string{"macos/arm64": lo.ToPtr("mac-arm"), "linux/arm64": lo.ToPtr("linux-arm")},
+		}
+		assert.Equal(t, "mac-arm", *pm.Resolve())
+		SetArch("x86_64")
+		assert.Equal(t, "mac", *pm.Resolve())
+	})
+
+	t.Run("returns os/arch value before distro value", func(t *testing.T) {
+		SetOS("linux")
+		SetDistro(Distro{ID: "debian"})
+		defer ResetDistro()
+		originalArch := archValue
+		defer SetArch(originalArch)
+		SetArch("aarch64")
+		pm := &PlatformMap[string]{
+			Linux:   lo.ToPtr("linux"),
+			Distros: map[DistroID]*string{DistroDebian: lo.ToPtr("debian")},
+			OSArch:  map[string]*string{"linux/arm64": lo.ToPtr("linux-arm")},
+		}
+		assert.Equal(t, "linux-arm", *pm.Resolve())
+	})
+
+	t.Run("ignores distro values on other platforms", func(t *testing.T) {
+		SetOS("darwin")
+		pm := &PlatformMap[string]{Distros: map[DistroID]*string{DistroDebian: lo.ToPtr("debian")}}
+		assert.Nil(t, pm.Resolve())
+	})
+
 	t.Run("returns nil for unknown OS", func(t *testing.T) {
 		SetOS("freebsd")
 		pm := &PlatformMap[string]{
@@ -219,7 +387,8 @@ func TestParsePlatformSingleValue(t *testing.T) {
 func TestParselatformMap(t *testing.T) {
 	t.Run("parses macos value", func(t *testing.T) {
 		values := map[string]string{"macos": "mac-value"}
-		pm := ParselatformMap(values)
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
 		assert.Equal(t, "mac-value", *pm.MacOS)
 		assert.Nil(t, pm.Linux)
 		assert.Nil(t, pm.Windows)
@@ -227,7 +396,8 @@ func TestParselatformMap(t *testing.T) {
 
 	t.Run("parses linux value", func(t *testing.T) {
 		values := map[string]string{"linux": "linux-value"}
-		pm := ParselatformMap(values)
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
 		assert.Nil(t, pm.MacOS)
 		assert.Equal(t, "linux-value", *pm.Linux)
 		assert.Nil(t, pm.Windows)
@@ -235,7 +405,8 @@ func TestParselatformMap(t *testing.T) {
 
 	t.Run("parses windows value", func(t *testing.T) {
 		values := map[string]string{"windows": "windows-value"}
-		pm := ParselatformMap(values)
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
 		assert.Nil(t, pm.MacOS)
 		assert.Nil(t, pm.Linux)
 		assert.Equal(t, "windows-value", *pm.Windows)
@@ -247,21 +418,76 @@ func TestParselatformMap(t *testing.T) {
 			"linux":   "lin",
 			"windows": "win",
 		}
-		pm := ParselatformMap(values)
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
 		assert.Equal(t, "mac", *pm.MacOS)
 		assert.Equal(t, "lin", *pm.Linux)
 		assert.Equal(t, "win", *pm.Windows)
 	})
+
+	t.Run("parses distro values", func(t *testing.T) {
+		values := map[string]string{"linux": "lin", "debian": "deb"}
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
+		assert.Equal(t, "lin", *pm.Linux)
+		assert.Equal(t, "deb", *pm.Distros[DistroDebian])
+	})
+
+	t.Run("parses os/arch values", func(t *testing.T) {
+		values := map[string]string{"linux": "lin", "linux/arm64": "lin-arm"}
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
+		assert.Equal(t, "lin", *pm.Linux)
+		assert.Equal(t, "lin-arm", *pm.OSArch["linux/arm64"])
+	})
+
+	t.Run("normalizes architecture aliases", func(t *testing.T) {
+		values := map[string]string{"linux/x86_64": "lin-amd", "macos/aarch64": "mac-arm"}
+		pm, err := ParselatformMap(values)
+		require.NoError(t, err)
+		assert.Equal(t, "lin-amd", *pm.OSArch["linux/amd64"])
+		assert.Equal(t, "mac-arm", *pm.OSArch["macos/arm64"])
+	})
+
+	t.Run("returns an error for unknown keys", func(t *testing.T) {
+		pm, err := ParselatformMap(map[string]string{"linux": "lin", "freebsd": "bsd", "linux/riscv64": "lin"})
+		assert.EqualError(t, err, "unsupported platform key \"freebsd\"\nunsupported platform key \"linux/riscv64\"")
+		assert.Equal(t, "lin", *pm.Linux)
+		assert.Empty(t, pm.OSArch)
+	})
+}
+
+func TestPlatformMapUnmarshal(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		var pm PlatformMap[map[string]string]
+		content := "linux:\n  A: lin\nfedora:\n  A: fed\nlinux/x86_64:\n  A: amd\nunknown:\n  A: x\n"
+		require.NoError(t, yaml.Unmarshal([]byte(content), &pm))
+		assert.Equal(t, map[string]string{"A": "lin"}, *pm.Linux)
+		assert.Equal(t, map[string]string{"A": "fed"}, *pm.Distros[DistroFedora])
+		assert.Equal(t, map[string]string{"A": "amd"}, *pm.OSArch["linux/amd64"])
+		assert.Len(t, pm.Distros, 1)
+		assert.Nil(t, pm.MacOS)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var pm PlatformMap[string]
+		require.NoError(t, json.Unmarshal([]byte(`{"macos": "mac", "alpine": "apk"}`), &pm))
+		assert.Equal(t, "mac", *pm.MacOS)
+		assert.Equal(t, "apk", *pm.Distros[DistroAlpine])
+		assert.Nil(t, pm.Linux)
+	})
 }
 
 func TestNewPlatformMap(t *testing.T) {
 	t.Run("returns nil for nil input", func(t *testing.T) {
-		pm := NewPlatformMap[string](nil)
+		pm, err := NewPlatformMap[string](nil)
+		require.NoError(t, err)
 		assert.Nil(t, pm)
 	})
 
 	t.Run("handles single value", func(t *testing.T) {
-		pm := NewPlatformMap[string]("single-value")
+		pm, err := NewPlatformMap[string]("single-value")
+		require.NoError(t, err)
 		assert.Equal(t, "single-value", *pm.MacOS)
 		assert.Equal(t, "single-value", *pm.Linux)
 		assert.Equal(t, "single-value", *pm.Windows)
@@ -269,7 +495,8 @@ func TestNewPlatformMap(t *testing.T) {
 
 	t.Run("handles map of values", func(t *testing.T) {
 		input := map[string]string{"macos": "mac", "linux": "lin"}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac", *pm.MacOS)
 		assert.Equal(t, "lin", *pm.Linux)
 		assert.Nil(t, pm.Windows)
@@ -277,7 +504,8 @@ func TestNewPlatformMap(t *testing.T) {
 
 	t.Run("handles pointer to value", func(t *testing.T) {
 		value := "ptr-value"
-		pm := NewPlatformMap[string](&value)
+		pm, err := NewPlatformMap[string](&value)
+		require.NoError(t, err)
 		assert.Equal(t, "ptr-value", *pm.MacOS)
 		assert.Equal(t, "ptr-value", *pm.Linux)
 		assert.Equal(t, "ptr-value", *pm.Windows)
@@ -285,14 +513,16 @@ func TestNewPlatformMap(t *testing.T) {
 
 	t.Run("handles nil pointer", func(t *testing.T) {
 		var ptr *string
-		pm := NewPlatformMap[string](ptr)
+		pm, err := NewPlatformMap[string](ptr)
+		require.NoError(t, err)
 		assert.Nil(t, pm)
 	})
 
 	t.Run("handles map of pointers", func(t *testing.T) {
 		mac := "mac"
 		input := map[string]*string{"macos": &mac, "linux": nil}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac", *pm.MacOS)
 		assert.Nil(t, pm.Linux)
 	})
@@ -304,7 +534,8 @@ func TestNewPlatformMap(t *testing.T) {
 			"linux":   "linux-value",
 			"windows": "windows-value",
 		}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac-value", *pm.MacOS)
 		assert.Equal(t, "linux-value", *pm.Linux)
 		assert.Equal(t, "windows-value", *pm.Windows)
@@ -314,7 +545,8 @@ func TestNewPlatformMap(t *testing.T) {
 		input := map[any]any{
 			"macos": "mac-only",
 		}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac-only", *pm.MacOS)
 		assert.Nil(t, pm.Linux)
 		assert.Nil(t, pm.Windows)
@@ -327,7 +559,8 @@ func TestNewPlatformMap(t *testing.T) {
 			"linux":   "linux-value",
 			"windows": "windows-value",
 		}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac-value", *pm.MacOS)
 		assert.Equal(t, "linux-value", *pm.Linux)
 		assert.Equal(t, "windows-value", *pm.Windows)
@@ -337,7 +570,8 @@ func TestNewPlatformMap(t *testing.T) {
 		input := map[string]any{
 			"linux": "linux-only",
 		}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Nil(t, pm.MacOS)
 		assert.Equal(t, "linux-only", *pm.Linux)
 		assert.Nil(t, pm.Windows)
@@ -349,7 +583,8 @@ func TestNewPlatformMap(t *testing.T) {
 			"macos": "mac-value",
 			123:     "ignored",
 		}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac-value", *pm.MacOS)
 		assert.Nil(t, pm.Linux)
 		assert.Nil(t, pm.Windows)
@@ -361,10 +596,17 @@ func TestNewPlatformMap(t *testing.T) {
 			"macos": "mac-value",
 			"linux": 123, // wrong type, should be ignored
 		}
-		pm := NewPlatformMap[string](input)
+		pm, err := NewPlatformMap[string](input)
+		require.NoError(t, err)
 		assert.Equal(t, "mac-value", *pm.MacOS)
 		assert.Nil(t, pm.Linux)
 	})
+
+	t.Run("returns an error for unsupported input types", func(t *testing.T) {
+		pm, err := NewPlatformMap[string](123)
+		assert.Error(t, err)
+		assert.Nil(t, pm)
+	})
 }
 
 func TestSetOSAndSetArch(t *testing.T) {
@@ -0,0 +1,178 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Trait is a property of the environment sofmani runs in, such as running under WSL or over SSH.
+type Trait string
+
+// Constants for supported traits.
+const (
+	TraitWSL       Trait = "wsl"       // TraitWSL is set when running under Windows Subsystem for Linux.
+	TraitContainer Trait = "container" // TraitContainer is set when running inside a container (e.g., docker, podman).
+	TraitCI        Trait = "ci"        // TraitCI is set when running in CI, according to the CI environment variable.
+	TraitSSH       Trait = "ssh"       // TraitSSH is set when running in an SSH session.
+	TraitHeadless  Trait = "headless"  // TraitHeadless is set when no graphical display is available.
+)
+
+// AllTraits lists the supported traits.
+var AllTraits = []Trait{TraitWSL, TraitContainer, TraitCI, TraitSSH, TraitHeadless}
+
+// Traits defines which environment traits a configuration applies to.
+type Traits struct {
+	// Only specifies a list of traits, at least one of which must be present for the configuration
+	// to apply.
+	Only *[]Trait `json:"only"   yaml:"only"`
+	// Except specifies a list of traits, none of which may be present for the configuration to apply.
+	Except *[]Trait `json:"except" yaml:"except"`
+}
+
+// GetShouldRunWithTraits determines if a configuration should run given the present traits, based
+// on the Only and Except fields of the Traits struct. Both fields apply when set.
+func (t *Traits) GetShouldRunWithTraits(present []Trait) bool {
+	if t == nil {
+		return true
+	}
+	hasAny := func(traits []Trait) bool {
+		return slices.ContainsFunc(traits, func(trait Trait) bool {
+			return slices.Contains(present, trait)
+		})
+	}
+	if t.Only != nil && !hasAny(*t.Only) {
+		return false
+	}
+	if t.Except != nil && hasAny(*t.Except) {
+		return false
+	}
+	return true
+}
+
+// Validate checks that every trait is known.
+func (t *Traits) Validate() error {
+	if t == nil {
+		return nil
+	}
+	errs := []error{}
+	for _, traits := range []*[]Trait{t.Only, t.Except} {
+		if traits == nil {
+			continue
+		}
+		for _, trait := range *traits {
+			if !slices.Contains(AllTraits, trait) {
+				errs = append(errs, fmt.Errorf("unknown trait %q", trait))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	traitsValue *[]Trait   // traitsValue caches the detected traits.
+	traitsMu    sync.Mutex // traitsMu guards traitsValue.
+)
+
+// GetTraits returns the traits of the current environment. It caches the value after the first call.
+func GetTraits() []Trait {
+	traitsMu.Lock()
+	defer traitsMu.Unlock()
+	if traitsValue == nil {
+		traits := DetectTraits(getOS(), os.LookupEnv, os.ReadFile)
+		traitsValue = &traits
+	}
+	return *traitsValue
+}
+
+// HasTrait returns true if the current environment has a trait.
+func HasTrait(trait Trait) bool {
+	return slices.Contains(GetTraits(), trait)
+}
+
+// SetTraits overrides the detected traits. This is primarily used for testing.
+func SetTraits(traits []Trait) {
+	traitsMu.Lock()
+	defer traitsMu.Unlock()
+	traitsValue = &traits
+}
+
+// ResetTraits clears the cached traits, so they are detected again on the next call.
+// This is primarily used for testing.
+func ResetTraits() {
+	traitsMu.Lock()
+	defer traitsMu.Unlock()
+	traitsValue = nil
+}
+
+// containerMarkers are files that container runtimes create inside containers.
+var containerMarkers = []string{"/.dockerenv", "/run/.containerenv"}
+
+// containerCgroups are cgroup path fragments that indicate a container.
+var containerCgroups = []string{"docker", "kubepods", "containerd", "libpod", "lxc"}
+
+// DetectTraits detects the traits of an environment, given its operating system, a function to
+// look up environment variables and a function to read files.
+func DetectTraits(
+	curOS string,
+	lookupEnv func(string) (string, bool),
+	readFile func(string) ([]byte, error),
+) []Trait {
+	isSet := func(name string) bool {
+		value, ok := lookupEnv(name)
+		return ok && value != ""
+	}
+	traits := []Trait{}
+
+	if curOS == "linux" {
+		wsl := isSet("WSL_DISTRO_NAME") || isSet("WSL_INTEROP")
+		if !wsl {
+			if release, err := readFile("/proc/sys/kernel/osrelease"); err == nil {
+				wsl = strings.Contains(strings.ToLower(string(release)), "microsoft")
+			}
+		}
+		if wsl {
+			traits = append(traits, TraitWSL)
+		}
+
+		container := isSet("container") || isSet("REMOTE_CONTAINERS") || isSet("CODESPACES")
+		for _, marker := range containerMarkers {
+			if _, err := readFile(marker); err == nil {
+				container = true
+			}
+		}
+		if !container {
+			if cgroup, err := readFile("/proc/1/cgroup"); err == nil {
+				container = slices.ContainsFunc(containerCgroups, func(name string) bool {
+					return strings.Contains(string(cgroup), name)
+				})
+			}
+		}
+		if container {
+			traits = append(traits, TraitContainer)
+		}
+	}
+
+	if ci, ok := lookupEnv("CI"); ok && ci != "" && ci != "0" && !strings.EqualFold(ci, "false") {
+		traits = append(traits, TraitCI)
+	}
+
+	ssh := isSet("SSH_CONNECTION") || isSet("SSH_CLIENT") || isSet("SSH_TTY")
+	if ssh {
+		traits = append(traits, TraitSSH)
+	}
+
+	// Linux needs an X11 or Wayland display for graphical apps. macOS and Windows always have one,
+	// except over SSH.
+	headless := ssh
+	if curOS == "linux" {
+		headless = !isSet("DISPLAY") && !isSet("WAYLAND_DISPLAY")
+	}
+	if headless {
+		traits = append(traits, TraitHeadless)
+	}
+	return traits
+}
@@ -0,0 +1,110 @@
+package platform
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectTraits(t *testing.T) {
+	tests := []struct {
+		name     string
+		os       string
+		env      map[string]string
+		files    map[string]string
+		expected []Trait
+	}{
+		{"linux desktop", "linux", map[string]string{"DISPLAY": ":0"}, nil, []Trait{}},
+		{"linux without display", "linux", nil, nil, []Trait{TraitHeadless}},
+		{"wayland", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, nil, []Trait{}},
+		{
+			"wsl from env",
+			"linux",
+			map[string]string{"WSL_DISTRO_NAME": "Ubuntu", "DISPLAY": ":0"},
+			nil,
+			[]Trait{TraitWSL},
+		},
+		{
+			"wsl from kernel release",
+			"linux",
+			map[string]string{"DISPLAY": ":0"},
+			map[string]string{"/proc/sys/kernel/osrelease": "5.15.153.1-microsoft-standard-WSL2"},
+			[]Trait{TraitWSL},
+		},
+		{"docker", "linux", nil, map[string]string{"/.dockerenv": ""}, []Trait{TraitContainer, TraitHeadless}},
+		{"podman", "linux", map[string]string{"container": "podman"}, nil, []Trait{TraitContainer, TraitHeadless}},
+		{
+			"kubernetes cgroup",
+			"linux",
+			nil,
+			map[string]string{"/proc/1/cgroup": "0::/kubepods/besteffort/pod1234"},
+			[]Trait{TraitContainer, TraitHeadless},
+		},
+		{"host cgroup", "linux", nil, map[string]string{"/proc/1/cgroup": "0::/init.scope"}, []Trait{TraitHeadless}},
+		{"ci", "darwin", map[string]string{"CI": "true"}, nil, []Trait{TraitCI}},
+		{"ci disabled", "darwin", map[string]string{"CI": "false"}, nil, []Trait{}},
+		{"ssh on macos", "darwin", map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, nil, []Trait{TraitSSH, TraitHeadless}},
+		{
+			"ssh with X forwarding",
+			"linux",
+			map[string]string{"SSH_TTY": "/dev/pts/0", "DISPLAY": "localhost:10.0"},
+			nil,
+			[]Trait{TraitSSH},
+		},
+		{"windows", "windows", nil, map[string]string{"/.dockerenv": ""}, []Trait{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupEnv := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+			readFile := func(name string) ([]byte, error) {
+				content, ok := tt.files[name]
+				if !ok {
+					return nil, os.ErrNotExist
+				}
+				return []byte(content), nil
+			}
+			assert.Equal(t, tt.expected, DetectTraits(tt.os, lookupEnv, readFile))
+		})
+	}
+}
+
+func TestGetShouldRunWithTraits(t *testing.T) {
+	tests := []struct {
+		name     string
+		traits   *Traits
+		present  []Trait
+		expected bool
+	}{
+		{"nil", nil, []Trait{TraitSSH}, true},
+		{"empty", &Traits{}, []Trait{TraitSSH}, true},
+		{"only present", &Traits{Only: &[]Trait{TraitWSL}}, []Trait{TraitWSL, TraitHeadless}, true},
+		{"only absent", &Traits{Only: &[]Trait{TraitWSL}}, []Trait{TraitHeadless}, false},
+		{"only any of", &Traits{Only: &[]Trait{TraitWSL, TraitSSH}}, []Trait{TraitSSH}, true},
+		{"except present", &Traits{Except: &[]Trait{TraitContainer, TraitSSH}}, []Trait{TraitSSH}, false},
+		{"except absent", &Traits{Except: &[]Trait{TraitContainer}}, []Trait{}, true},
+		{"only and except", &Traits{Only: &[]Trait{TraitWSL}, Except: &[]Trait{TraitCI}}, []Trait{TraitWSL, TraitCI}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.traits.GetShouldRunWithTraits(tt.present))
+		})
+	}
+}
+
+func TestTraitsValidate(t *testing.T) {
+	assert.NoError(t, (*Traits)(nil).Validate())
+	assert.NoError(t, (&Traits{Only: &[]Trait{TraitWSL}, Except: &[]Trait{TraitCI}}).Validate())
+	assert.ErrorContains(t, (&Traits{Except: &[]Trait{"gui"}}).Validate(), `unknown trait "gui"`)
+}
+
+func TestSetTraits(t *testing.T) {
+	defer ResetTraits()
+	SetTraits([]Trait{TraitCI})
+	assert.Equal(t, []Trait{TraitCI}, GetTraits())
+	assert.True(t, HasTrait(TraitCI))
+	assert.False(t, HasTrait(TraitWSL))
+}
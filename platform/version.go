@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern matches the numeric part of a version, e.g. "v20.11.1" or "22.04-lts".
+var versionPattern = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)`)
+
+// parseVersion returns the numeric components of a version string.
+func parseVersion(version string) ([]int, bool) {
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return nil, false
+	}
+	parts := []int{}
+	for part := range strings.SplitSeq(match[1], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// CompareVersions compares two version strings component by component, returning -1, 0 or 1.
+// Missing components count as 0, so "22.04" equals "22.4.0". A leading "v" and any non-numeric
+// suffix are ignored. The second return value is false if either string is not a version.
+func CompareVersions(a string, b string) (int, bool) {
+	x, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for idx := range max(len(x), len(y)) {
+		var m, n int
+		if idx < len(x) {
+			m = x[idx]
+		}
+		if idx < len(y) {
+			n = y[idx]
+		}
+		if m < n {
+			return -1, true
+		}
+		if m > n {
+			return 1, true
+		}
+	}
+	return 0, true
+}
@@ -0,0 +1,31 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+		ok       bool
+	}{
+		{"22.04", "22.04", 0, true},
+		{"22.10", "22.04", 1, true},
+		{"1.9", "1.10", -1, true},
+		{"v20.11.1", "20", 1, true},
+		{"22.04", "22.4.0", 0, true},
+		{"3.12.2-rc1", "3.12.2", 0, true},
+		{"", "1", 0, false},
+		{"1", "latest", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			cmp, ok := CompareVersions(tt.a, tt.b)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, cmp)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package scan discovers packages that are already installed on the machine through the supported
+// package managers, and renders them as a sofmani config.
+package scan
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Package is a single installed package discovered by a Source.
+type Package struct {
+	// Name is the package name, as the matching installer type expects it.
+	Name string
+	// BinName is the binary to check for, when it differs from what the installer derives from Name.
+	BinName string
+	// Opts are the installer options needed to reinstall the package.
+	Opts map[string]any
+}
+
+// Runner runs a command and returns its standard output.
+type Runner func(bin string, args ...string) ([]byte, error)
+
+// Source lists the packages installed by a single package manager.
+type Source struct {
+	// Category is the category header the packages are grouped under.
+	Category string
+	// Type is the installer type used for the discovered packages.
+	Type appconfig.InstallerType
+	// Bin is the package manager binary. The source is skipped when it is not in PATH.
+	Bin string
+	// List returns the installed packages.
+	List func(run Runner) ([]Package, error)
+}
+
+// Result holds the packages discovered by a single source.
+type Result struct {
+	// Source is the source the packages were discovered by.
+	Source Source
+	// Packages are the discovered packages.
+	Packages []Package
+	// Err is set when the source was available but listing its packages failed.
+	Err error
+}
+
+// Scanner queries package managers for their installed packages.
+type Scanner struct {
+	// Run runs the package manager commands.
+	Run Runner
+	// LookPath reports whether a package manager binary is available.
+	LookPath func(file string) (string, error)
+}
+
+// NewScanner creates a Scanner that runs commands on the current machine.
+func NewScanner() *Scanner {
+	return &Scanner{
+		Run: func(bin string, args ...string) ([]byte, error) {
+			return utils.RunCmdGetOutput(nil, bin, args...)
+		},
+		LookPath: exec.LookPath,
+	}
+}
+
+// Scan lists the packages of each available source. Sources whose binary is not in PATH are left
+// out of the results.
+func (s *Scanner) Scan(sources []Source) []Result {
+	results := []Result{}
+	for _, source := range sources {
+		if _, err := s.LookPath(source.Bin); err != nil {
+			continue
+		}
+		packages, err := source.List(s.Run)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", source.Category, err)
+		}
+		results = append(results, Result{Source: source, Packages: packages, Err: err})
+	}
+	return results
+}
+
+// entry is the rendered form of a single install entry.
+type entry struct {
+	Category string         `yaml:"category,omitempty"`
+	Name     string         `yaml:"name,omitempty"`
+	Type     string         `yaml:"type,omitempty"`
+	BinName  string         `yaml:"bin_name,omitempty"`
+	Opts     map[string]any `yaml:"opts,omitempty"`
+}
+
+// Render renders the scan results as a sofmani YAML config, with each source's packages grouped
+// under a category header. Sources without packages are left out.
+func Render(results []Result) ([]byte, error) {
+	entries := []entry{}
+	for _, result := range results {
+		if len(result.Packages) == 0 {
+			continue
+		}
+		entries = append(entries, entry{Category: result.Source.Category})
+		for _, pkg := range result.Packages {
+			entries = append(entries, entry{
+				Name:    pkg.Name,
+				Type:    string(result.Source.Type),
+				BinName: pkg.BinName,
+				Opts:    pkg.Opts,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by `sofmani init --scan`. Review the entries before using this config.\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string][]entry{"install": entries}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
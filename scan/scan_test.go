@@ -0,0 +1,82 @@
+package scan
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan(t *testing.T) {
+	scanner := &Scanner{
+		Run: func(bin string, args ...string) ([]byte, error) { return nil, nil },
+		LookPath: func(file string) (string, error) {
+			if file == "missing" {
+				return "", exec.ErrNotFound
+			}
+			return "/usr/bin/" + file, nil
+		},
+	}
+	sources := []Source{
+		{Category: "Found", Bin: "found", List: func(run Runner) ([]Package, error) {
+			return []Package{{Name: "a"}}, nil
+		}},
+		{Category: "Missing", Bin: "missing", List: func(run Runner) ([]Package, error) {
+			t.Fatal("missing source should not be listed")
+			return nil, nil
+		}},
+		{Category: "Failing", Bin: "failing", List: func(run Runner) ([]Package, error) {
+			return nil, errors.New("boom")
+		}},
+	}
+
+	results := scanner.Scan(sources)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Found", results[0].Source.Category)
+	assert.Equal(t, []Package{{Name: "a"}}, results[0].Packages)
+	assert.NoError(t, results[0].Err)
+	assert.EqualError(t, results[1].Err, "Failing: boom")
+}
+
+func TestRender(t *testing.T) {
+	results := []Result{
+		{
+			Source: Source{Category: "Homebrew", Type: appconfig.InstallerTypeBrew},
+			Packages: []Package{
+				{Name: "jq"},
+				{Name: "sofmani", Opts: map[string]any{"tap": "chenasraf/tap"}},
+			},
+		},
+		{
+			Source: Source{Category: "npm", Type: appconfig.InstallerTypeNpm},
+		},
+		{
+			Source:   Source{Category: "Cargo", Type: appconfig.InstallerTypeCargo},
+			Packages: []Package{{Name: "ripgrep", BinName: "rg"}},
+		},
+	}
+
+	out, err := Render(results)
+	require.NoError(t, err)
+	assert.Equal(t, `# Generated by `+"`sofmani init --scan`"+`. Review the entries before using this config.
+install:
+  - category: Homebrew
+  - name: jq
+    type: brew
+  - name: sofmani
+    type: brew
+    opts:
+      tap: chenasraf/tap
+  - category: Cargo
+  - name: ripgrep
+    type: cargo
+    bin_name: rg
+`, string(out))
+
+	cfg, err := appconfig.ParseConfigFromContent(out, appconfig.ConfigFormatYAML)
+	require.NoError(t, err)
+	assert.Len(t, cfg.Install, 5)
+}
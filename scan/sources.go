@@ -0,0 +1,327 @@
+package scan
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+)
+
+// DefaultSources returns the package manager sources scanned by `sofmani init --scan`.
+func DefaultSources() []Source {
+	return []Source{
+		{
+			Category: "Homebrew",
+			Type:     appconfig.InstallerTypeBrew,
+			Bin:      "brew",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("brew", "leaves")
+				if err != nil {
+					return nil, err
+				}
+				return parseBrewLeaves(out), nil
+			},
+		},
+		{
+			Category: "Homebrew Casks",
+			Type:     appconfig.InstallerTypeBrew,
+			Bin:      "brew",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("brew", "list", "--cask", "-1")
+				if err != nil {
+					return nil, err
+				}
+				return parseBrewCasks(out), nil
+			},
+		},
+		{
+			Category: "npm",
+			Type:     appconfig.InstallerTypeNpm,
+			Bin:      "npm",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("npm", "ls", "-g", "--depth=0", "--json")
+				if err != nil && len(out) == 0 {
+					return nil, err
+				}
+				return parseNpmList(out)
+			},
+		},
+		{
+			Category: "pipx",
+			Type:     appconfig.InstallerTypePipx,
+			Bin:      "pipx",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("pipx", "list", "--json")
+				if err != nil {
+					return nil, err
+				}
+				return parsePipxList(out)
+			},
+		},
+		{
+			Category: "Cargo",
+			Type:     appconfig.InstallerTypeCargo,
+			Bin:      "cargo",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("cargo", "install", "--list")
+				if err != nil {
+					return nil, err
+				}
+				return parseCargoList(out), nil
+			},
+		},
+		{
+			Category: "APT",
+			Type:     appconfig.InstallerTypeApt,
+			Bin:      "apt-mark",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("apt-mark", "showmanual")
+				if err != nil {
+					return nil, err
+				}
+				return parseLines(out), nil
+			},
+		},
+		{
+			Category: "Pacman",
+			Type:     appconfig.InstallerTypePacman,
+			Bin:      "pacman",
+			List: func(run Runner) ([]Package, error) {
+				// -n limits the list to packages from the sync repositories; AUR packages are
+				// listed separately since pacman cannot install them.
+				out, err := run("pacman", "-Qen")
+				if err != nil && len(out) == 0 {
+					return nil, err
+				}
+				return parsePacmanList(out), nil
+			},
+		},
+		{
+			Category: "AUR",
+			Type:     appconfig.InstallerTypeYay,
+			Bin:      "yay",
+			List: func(run Runner) ([]Package, error) {
+				out, err := run("pacman", "-Qem")
+				if err != nil && len(out) == 0 {
+					return nil, err
+				}
+				return parsePacmanList(out), nil
+			},
+		},
+		{
+			Category: "Go",
+			Type:     appconfig.InstallerTypeGo,
+			Bin:      "go",
+			List: func(run Runner) ([]Package, error) {
+				dir, err := goBinDir(run)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := os.Stat(dir); os.IsNotExist(err) {
+					return []Package{}, nil
+				}
+				out, err := run("go", "version", "-m", dir)
+				if err != nil && len(out) == 0 {
+					return nil, err
+				}
+				return parseGoVersion(out), nil
+			},
+		},
+	}
+}
+
+// goBinDir returns the directory `go install` writes binaries to: $GOBIN, or the bin directory of
+// the first $GOPATH entry.
+func goBinDir(run Runner) (string, error) {
+	out, err := run("go", "env", "GOBIN", "GOPATH")
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\r\n"), "\n")
+	if gobin := strings.TrimSpace(lines[0]); gobin != "" {
+		return gobin, nil
+	}
+	gopath := ""
+	if len(lines) > 1 {
+		gopath = strings.TrimSpace(lines[1])
+	}
+	gopath, _, _ = strings.Cut(gopath, string(filepath.ListSeparator))
+	return filepath.Join(gopath, "bin"), nil
+}
+
+// parseLines returns one package per non-empty line.
+func parseLines(out []byte) []Package {
+	packages := []Package{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			packages = append(packages, Package{Name: name})
+		}
+	}
+	return sortPackages(packages)
+}
+
+// parseBrewLeaves parses `brew leaves`. Formulae from third-party taps are listed as
+// "user/tap/formula", and are split into the formula name and the tap option.
+func parseBrewLeaves(out []byte) []Package {
+	packages := parseLines(out)
+	for idx, pkg := range packages {
+		parts := strings.Split(pkg.Name, "/")
+		if len(parts) == 3 {
+			packages[idx] = Package{
+				Name: parts[2],
+				Opts: map[string]any{"tap": parts[0] + "/" + parts[1]},
+			}
+		}
+	}
+	return packages
+}
+
+// parseBrewCasks parses `brew list --cask -1`.
+func parseBrewCasks(out []byte) []Package {
+	packages := parseLines(out)
+	for idx := range packages {
+		packages[idx].Opts = map[string]any{"cask": true}
+	}
+	return packages
+}
+
+// npmBundled are the packages that come with Node.js itself.
+var npmBundled = []string{"npm", "corepack"}
+
+// parseNpmList parses `npm ls -g --depth=0 --json`.
+func parseNpmList(out []byte) ([]Package, error) {
+	var list struct {
+		Dependencies map[string]any `json:"dependencies"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, err
+	}
+	packages := []Package{}
+	for name := range list.Dependencies {
+		if !slices.Contains(npmBundled, name) {
+			packages = append(packages, Package{Name: name})
+		}
+	}
+	return sortPackages(packages), nil
+}
+
+// parsePipxList parses `pipx list --json`. When none of the package's apps is named after the
+// package, the first app is used as the binary name.
+func parsePipxList(out []byte) ([]Package, error) {
+	var list struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Package string   `json:"package"`
+					Apps    []string `json:"apps"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, err
+	}
+	packages := []Package{}
+	for venv, info := range list.Venvs {
+		main := info.Metadata.MainPackage
+		pkg := Package{Name: main.Package}
+		if pkg.Name == "" {
+			pkg.Name = venv
+		}
+		pkg.BinName = binNameFor(pkg.Name, main.Apps)
+		packages = append(packages, pkg)
+	}
+	return sortPackages(packages), nil
+}
+
+// parseCargoList parses `cargo install --list`, which lists each crate as "name version:" followed
+// by its indented binaries. Crates installed from a path or git repository are skipped, since they
+// cannot be reinstalled by name.
+func parseCargoList(out []byte) []Package {
+	packages := []Package{}
+	var current *Package
+	bins := []string{}
+	flush := func() {
+		if current != nil {
+			current.BinName = binNameFor(current.Name, bins)
+			packages = append(packages, *current)
+		}
+		current = nil
+		bins = []string{}
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			bins = append(bins, strings.TrimSpace(line))
+			continue
+		}
+		flush()
+		header := strings.TrimSuffix(strings.TrimSpace(line), ":")
+		fields := strings.Fields(header)
+		if len(fields) != 2 {
+			continue
+		}
+		current = &Package{Name: fields[0]}
+	}
+	flush()
+	return sortPackages(packages)
+}
+
+// parsePacmanList parses `pacman -Q` output, which lists one "name version" pair per line.
+func parsePacmanList(out []byte) []Package {
+	packages := parseLines(out)
+	for idx, pkg := range packages {
+		packages[idx].Name = strings.Fields(pkg.Name)[0]
+	}
+	return packages
+}
+
+// parseGoVersion parses `go version -m <dir>`, which lists each binary as "file: go version",
+// followed by its build info. The "path" line holds the package that was installed.
+func parseGoVersion(out []byte) []Package {
+	packages := []Package{}
+	bin := ""
+	for _, line := range strings.Split(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "\t") {
+			file, _, _ := strings.Cut(line, ": ")
+			bin = strings.TrimSuffix(filepath.Base(file), ".exe")
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "path" && bin != "" {
+			pkg := Package{Name: fields[1]}
+			if path.Base(pkg.Name) != bin {
+				pkg.BinName = bin
+			}
+			packages = append(packages, pkg)
+			bin = ""
+		}
+	}
+	return sortPackages(packages)
+}
+
+// binNameFor returns the binary name to record for a package with the given binaries, or an empty
+// string when one of them is named after the package.
+func binNameFor(name string, bins []string) string {
+	if len(bins) == 0 || slices.Contains(bins, name) {
+		return ""
+	}
+	return bins[0]
+}
+
+func sortPackages(packages []Package) []Package {
+	sort.SliceStable(packages, func(a, b int) bool {
+		return packages[a].Name < packages[b].Name
+	})
+	return packages
+}
@@ -0,0 +1,148 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBrewLeaves(t *testing.T) {
+	packages := parseBrewLeaves([]byte("jq\nneovim\nchenasraf/tap/sofmani\n"))
+	assert.Equal(t, []Package{
+		{Name: "sofmani", Opts: map[string]any{"tap": "chenasraf/tap"}},
+		{Name: "jq"},
+		{Name: "neovim"},
+	}, packages)
+}
+
+func TestParseBrewCasks(t *testing.T) {
+	packages := parseBrewCasks([]byte("wezterm\nfirefox\n"))
+	assert.Equal(t, []Package{
+		{Name: "firefox", Opts: map[string]any{"cask": true}},
+		{Name: "wezterm", Opts: map[string]any{"cask": true}},
+	}, packages)
+}
+
+func TestParseNpmList(t *testing.T) {
+	packages, err := parseNpmList([]byte(`{
+  "name": "lib",
+  "dependencies": {
+    "typescript": {"version": "5.4.5"},
+    "npm": {"version": "10.5.0"},
+    "corepack": {"version": "0.25.2"},
+    "@antfu/ni": {"version": "0.21.12"}
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Package{{Name: "@antfu/ni"}, {Name: "typescript"}}, packages)
+
+	_, err = parseNpmList([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestParsePipxList(t *testing.T) {
+	packages, err := parsePipxList([]byte(`{
+  "pipx_spec_version": "0.1",
+  "venvs": {
+    "black": {"metadata": {"main_package": {"package": "black", "apps": ["black", "blackd"]}}},
+    "httpie": {"metadata": {"main_package": {"package": "httpie", "apps": ["http", "https"]}}}
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Package{
+		{Name: "black"},
+		{Name: "httpie", BinName: "http"},
+	}, packages)
+}
+
+func TestParseCargoList(t *testing.T) {
+	packages := parseCargoList([]byte(`bat v0.24.0:
+    bat
+local-tool v0.1.0 (/home/user/src/local-tool):
+    local-tool
+ripgrep v14.1.0:
+    rg
+`))
+	assert.Equal(t, []Package{
+		{Name: "bat"},
+		{Name: "ripgrep", BinName: "rg"},
+	}, packages)
+}
+
+func TestParsePacmanList(t *testing.T) {
+	packages := parsePacmanList([]byte("git 2.45.1-1\nbase 3-2\n"))
+	assert.Equal(t, []Package{{Name: "base"}, {Name: "git"}}, packages)
+}
+
+func TestParseGoVersion(t *testing.T) {
+	packages := parseGoVersion([]byte(`/home/user/go/bin/gopls: go1.24.0
+	path	golang.org/x/tools/gopls
+	mod	golang.org/x/tools/gopls	v0.18.1	h1:abc=
+	dep	golang.org/x/mod	v0.23.0	h1:def=
+/home/user/go/bin/golangci-lint: go1.24.0
+	path	github.com/golangci/golangci-lint/v2/cmd/golangci-lint
+	mod	github.com/golangci/golangci-lint/v2	v2.1.0	h1:ghi=
+/home/user/go/bin/yq: go1.24.0
+	path	github.com/mikefarah/yq/v4
+	mod	github.com/mikefarah/yq/v4	v4.45.1	h1:jkl=
+`))
+	assert.Equal(t, []Package{
+		{Name: "github.com/golangci/golangci-lint/v2/cmd/golangci-lint"},
+		{Name: "github.com/mikefarah/yq/v4", BinName: "yq"},
+		{Name: "golang.org/x/tools/gopls"},
+	}, packages)
+}
+
+func TestGoBinDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"uses GOBIN", "/opt/gobin\n/home/user/go\n", "/opt/gobin"},
+		{"falls back to GOPATH", "\n/home/user/go\n", "/home/user/go/bin"},
+		{"uses first GOPATH entry", "\n/home/user/go:/other\n", "/home/user/go/bin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := goBinDir(func(bin string, args ...string) ([]byte, error) {
+				return []byte(tt.output), nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dir)
+		})
+	}
+}
+
+func TestDefaultSources(t *testing.T) {
+	gobin := t.TempDir()
+	outputs := map[string]string{
+		"brew leaves":                "jq\n",
+		"brew list --cask -1":        "wezterm\n",
+		"npm ls -g --depth=0 --json": `{"dependencies": {"typescript": {}}}`,
+		"pipx list --json":           `{"venvs": {"black": {"metadata": {"main_package": {"package": "black"}}}}}`,
+		"cargo install --list":       "bat v0.24.0:\n    bat\n",
+		"apt-mark showmanual":        "curl\n",
+		"pacman -Qen":                "git 2.45.1-1\n",
+		"pacman -Qem":                "yay-bin 12.3.5-1\n",
+		"go env GOBIN GOPATH":        gobin + "\n\n",
+		"go version -m " + gobin:     gobin + "/gopls: go1.24.0\n\tpath\tgolang.org/x/tools/gopls\n",
+	}
+	run := func(bin string, args ...string) ([]byte, error) {
+		key := strings.Join(append([]string{bin}, args...), " ")
+		if out, ok := outputs[key]; ok {
+			return []byte(out), nil
+		}
+		return nil, fmt.Errorf("unexpected command: %s", key)
+	}
+	for _, source := range DefaultSources() {
+		t.Run(source.Category, func(t *testing.T) {
+			packages, err := source.List(run)
+			require.NoError(t, err)
+			assert.Len(t, packages, 1)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package schema
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sourceDocs holds documentation and enum values read from the Go sources.
+type sourceDocs struct {
+	// types maps "pkg.Type" to the type's doc comment.
+	types map[string]string
+	// fields maps "pkg.Type.Field" to the field's doc comment.
+	fields map[string]string
+	// enums maps "pkg.Type" to the values of the string constants declared with that type,
+	// in declaration order.
+	enums map[string][]string
+}
+
+// loadDocs parses the non-test Go files of the given package directories (relative to root)
+// and collects type docs, field docs and typed string constants.
+func loadDocs(root string, pkgs []string) (*sourceDocs, error) {
+	docs := &sourceDocs{
+		types:  map[string]string{},
+		fields: map[string]string{},
+		enums:  map[string][]string{},
+	}
+	fset := token.NewFileSet()
+	for _, pkg := range pkgs {
+		dir := filepath.Join(root, pkg)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			docs.collect(pkg, file)
+		}
+	}
+	return docs, nil
+}
+
+// collect reads the type docs, field docs and typed string constants of a single file.
+func (d *sourceDocs) collect(pkg string, file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				key := pkg + "." + s.Name.Name
+				doc := s.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if doc != nil {
+					d.types[key] = doc.Text()
+				}
+				if st, ok := s.Type.(*ast.StructType); ok {
+					d.collectFields(key, st)
+				}
+			case *ast.ValueSpec:
+				d.collectConsts(pkg, gen.Tok, s)
+			}
+		}
+	}
+}
+
+func (d *sourceDocs) collectFields(typeKey string, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		doc := field.Doc
+		if doc == nil {
+			doc = field.Comment
+		}
+		if doc == nil {
+			continue
+		}
+		for _, name := range field.Names {
+			d.fields[typeKey+"."+name.Name] = doc.Text()
+		}
+	}
+}
+
+func (d *sourceDocs) collectConsts(pkg string, tok token.Token, s *ast.ValueSpec) {
+	ident, ok := s.Type.(*ast.Ident)
+	if tok != token.CONST || !ok {
+		return
+	}
+	for _, value := range s.Values {
+		lit, ok := value.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			continue
+		}
+		if v, err := strconv.Unquote(lit.Value); err == nil {
+			key := pkg + "." + ident.Name
+			d.enums[key] = append(d.enums[key], v)
+		}
+	}
+}
+
+// typeDoc returns the cleaned-up doc comment for the given type.
+func (d *sourceDocs) typeDoc(t reflect.Type) string {
+	return describe(d.types[typeKey(t)], baseName(t))
+}
+
+// fieldDoc returns the cleaned-up doc comment for the given struct field.
+func (d *sourceDocs) fieldDoc(owner reflect.Type, field reflect.StructField) string {
+	return describe(d.fields[typeKey(owner)+"."+field.Name], field.Name)
+}
+
+// typeKey returns the "pkg.Type" key of a named type, without generic type arguments.
+func typeKey(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + baseName(t)
+}
+
+// baseName returns the name of a type without generic type arguments.
+func baseName(t reflect.Type) string {
+	name, _, _ := strings.Cut(t.Name(), "[")
+	return name
+}
+
+// describe turns a Go doc comment into a schema description: it joins the lines, and drops the
+// leading identifier (and a following "is"/"are") that Go doc comments start with.
+func describe(doc string, name string) string {
+	text := strings.Join(strings.Fields(doc), " ")
+	if rest, ok := strings.CutPrefix(text, name+" "); ok {
+		text = rest
+		for _, verb := range []string{"is ", "are "} {
+			if rest, ok := strings.CutPrefix(text, verb); ok {
+				text = rest
+				break
+			}
+		}
+	}
+	if text == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(r)) + text[size:]
+}
@@ -0,0 +1,27 @@
+// Command gen regenerates sofmani.schema.json from the Go config types.
+// Run it with `go generate ./schema` from the repository root.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chenasraf/sofmani/schema"
+)
+
+func main() {
+	root := flag.String("root", "..", "path to the module root")
+	out := flag.String("o", "sofmani.schema.json", "path of the schema file to write")
+	flag.Parse()
+
+	data, err := schema.Generate(*root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate schema: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
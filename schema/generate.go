@@ -0,0 +1,470 @@
+// Package schema generates the JSON schema for sofmani config files from the Go config types.
+package schema
+
+//go:generate go run ./gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/chenasraf/sofmani/appconfig"
+	"github.com/chenasraf/sofmani/installer"
+	"github.com/chenasraf/sofmani/machine"
+	"github.com/chenasraf/sofmani/platform"
+)
+
+// sourcePackages are the package directories whose doc comments are used for descriptions.
+var sourcePackages = []string{"appconfig", "installer", "machine", "platform"}
+
+// fieldDefaults are default values that are applied in code rather than declared on the types.
+var fieldDefaults = map[string]any{
+	"appconfig.AppConfig.Debug":            false,
+	"appconfig.AppConfig.CheckUpdates":     false,
+	"appconfig.AppConfig.Summary":          true,
+	"appconfig.AppConfig.Strict":           false,
+	"appconfig.AppConfig.CategoryDisplay":  string(appconfig.CategoryDisplayBorder),
+	"appconfig.InstallerData.Verbose":      false,
+	"installer.AssetOpts.Strategy":         string(installer.GitHubReleaseInstallStrategyNone),
+	"installer.GitHubReleaseOpts.Provider": string(installer.GitHubReleaseProviderGitHub),
+	"installer.LinkOpts.OnConflict":        string(installer.LinkConflictSkip),
+}
+
+// fieldRefs are fields whose YAML representation is looser than their Go type (e.g. a *string
+// that also accepts a boolean), described by a hand-written definition.
+var fieldRefs = map[string]string{
+	"appconfig.AppConfig.Install":            "installStep",
+	"appconfig.InstallerData.Steps":          "installStep",
+	"appconfig.InstallerData.Enabled":        "enabled",
+	"appconfig.InstallerData.Frequency":      "frequency",
+	"appconfig.InstallerData.CheckHasUpdate": "shellScript",
+	"appconfig.InstallerData.CheckInstalled": "shellScript",
+	"appconfig.InstallerData.PreInstall":     "shellScript",
+	"appconfig.InstallerData.PostInstall":    "shellScript",
+	"appconfig.InstallerData.PreUpdate":      "shellScript",
+	"appconfig.InstallerData.PostUpdate":     "shellScript",
+	"platform.Platforms.Distro":              "distro",
+}
+
+// structRequired lists required keys of struct types.
+var structRequired = map[string][]string{
+	"installer.GitHubReleaseBinLink": {"target"},
+}
+
+// Generate builds the JSON schema for sofmani config files. Descriptions are read from the doc
+// comments of the Go sources under moduleRoot.
+func Generate(moduleRoot string) ([]byte, error) {
+	docs, err := loadDocs(moduleRoot, sourcePackages)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{docs: docs, definitions: newObject()}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g.root()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generator builds schema objects from Go types, collecting shared definitions as it goes.
+type generator struct {
+	docs        *sourceDocs
+	definitions *object
+}
+
+func (g *generator) root() *object {
+	props := newObject().set("$schema", newObject(
+		"type", "string",
+		"description", "Reference to the JSON schema for this configuration file.",
+	))
+	g.structProperties(reflect.TypeFor[appconfig.AppConfigOverlay](), props, false)
+	return newObject(
+		"$schema", "http://json-schema.org/draft-07/schema#",
+		"$id", "https://raw.githubusercontent.com/chenasraf/sofmani/master/schema/sofmani.schema.json",
+		"title", "sofmani configuration",
+		"description", "Schema for sofmani (Software Manifest) configuration files. See https://github.com/chenasraf/sofmani for documentation.",
+		"type", "object",
+		"additionalProperties", false,
+		"properties", props,
+		"definitions", g.definitions,
+	)
+}
+
+// ref returns a reference to the named definition, building it first if needed.
+func (g *generator) ref(name string) *object {
+	if !g.definitions.has(name) {
+		// Reserve the slot before building, so recursive types (group steps) terminate.
+		g.definitions.set(name, nil)
+		g.definitions.set(name, g.buildDefinition(name))
+	}
+	return newObject("$ref", "#/definitions/"+name)
+}
+
+// definitionName returns the shared definition name for a type, if it has one.
+func definitionName(t reflect.Type) (string, bool) {
+	switch t {
+	case reflect.TypeFor[appconfig.InstallerData]():
+		return "installer", true
+	case reflect.TypeFor[appconfig.InstallerType]():
+		return "installerType", true
+	case reflect.TypeFor[appconfig.RepoUpdateMode]():
+		return "repoUpdateMode", true
+	case reflect.TypeFor[appconfig.SkipSummary]():
+		return "skipSummary", true
+	case reflect.TypeFor[appconfig.EnvVars]():
+		return "envMap", true
+	case reflect.TypeFor[platform.Platform]():
+		return "platform", true
+	case reflect.TypeFor[platform.Platforms]():
+		return "platforms", true
+	case reflect.TypeFor[platform.Archs]():
+		return "archs", true
+	case reflect.TypeFor[platform.Traits]():
+		return "traits", true
+	case reflect.TypeFor[platform.PlatformMap[map[string]string]]():
+		return "platformEnvMap", true
+	case reflect.TypeFor[machine.Machines]():
+		return "machines", true
+	}
+	return "", false
+}
+
+func (g *generator) buildDefinition(name string) *object {
+	switch name {
+	case "installer":
+		return g.installerDefinition()
+	case "installerType":
+		return g.typeSchema(reflect.TypeFor[appconfig.InstallerType](), false, true)
+	case "repoUpdateMode":
+		return g.typeSchema(reflect.TypeFor[appconfig.RepoUpdateMode](), false, true)
+	case "platform":
+		// A platform is either a known platform, or Linux narrowed down to a distribution.
+		t := reflect.TypeFor[platform.Platform]()
+		return newObject(
+			"description", g.docs.typeDoc(t),
+			"anyOf", []any{
+				newObject("type", "string", "enum", toAnySlice(g.docs.enums[typeKey(t)])),
+				newObject("type", "string", "pattern", "^linux/"+strings.TrimPrefix(platform.DistroConstraintPattern.String(), "^")),
+			},
+		)
+	case "distro":
+		return newObject(
+			"type", "string",
+			"description", "A Linux distribution, optionally with a version constraint (e.g. 'debian', 'ubuntu>=22.04').",
+			"pattern", platform.DistroConstraintPattern.String(),
+		)
+	case "platforms":
+		return g.typeSchema(reflect.TypeFor[platform.Platforms](), false, true)
+	case "archs":
+		return g.typeSchema(reflect.TypeFor[platform.Archs](), false, true)
+	case "traits":
+		return g.typeSchema(reflect.TypeFor[platform.Traits](), false, true)
+	case "machines":
+		return g.typeSchema(reflect.TypeFor[machine.Machines](), false, true)
+	case "platformEnvMap":
+		return g.typeSchema(reflect.TypeFor[platform.PlatformMap[map[string]string]](), false, true)
+	case "envMap":
+		// EnvVars keeps its order in Go, but is written as a map of names to either plain values
+		// or value source objects.
+		return newObject(
+			"type", "object",
+			"additionalProperties", newObject("oneOf", []any{
+				newObject("type", "string"),
+				g.typeSchema(reflect.TypeFor[appconfig.EnvVar](), false, true),
+			}),
+		)
+	case "skipSummary":
+		return newObject(
+			"description", g.docs.typeDoc(reflect.TypeFor[appconfig.SkipSummary]()),
+			"oneOf", []any{
+				newObject("type", "boolean"),
+				newObject(
+					"type", "object",
+					"additionalProperties", false,
+					"properties", newObject(
+						"install", newObject("type", "boolean"),
+						"update", newObject("type", "boolean"),
+					),
+				),
+			},
+		)
+	case "enabled":
+		return newObject(
+			"description", "Enable or disable the step. Accepts a boolean, a boolean string ('true'/'false'), or a shell command whose exit code decides the result.",
+			"oneOf", []any{newObject("type", "boolean"), newObject("type", "string")},
+		)
+	case "shellScript":
+		return newObject(
+			"description", "A shell command or script. Accepts a string, or a boolean shorthand where 'true' runs the unix 'true' builtin (always succeeds) and 'false' runs 'false' (always fails).",
+			"oneOf", []any{newObject("type", "string"), newObject("type", "boolean")},
+		)
+	case "frequency":
+		return newObject(
+			"type", "string",
+			"description", "Duration string limiting how often the installer runs (e.g. '60s', '30m', '12h', '1d', '1w', '1d12h'). Supported units: s, m, h, d, w.",
+			"pattern", `^(\d+[smhdw])+$`,
+		)
+	case "installStep":
+		return newObject(
+			"description", "An entry in the top-level 'install' list. Must be either a category header (has 'category') or an installer (has 'name' or 'names', and 'type'), where 'extends' can provide any of them. Only category headers can have 'defaults'.",
+			"allOf", []any{
+				g.ref("installer"),
+				newObject(
+					"if", newObject("not", newObject("required", []any{"category"})),
+					"then", newObject("allOf", []any{
+						newObject("anyOf", []any{
+							newObject("required", []any{"name"}),
+							newObject("required", []any{"names"}),
+							newObject("required", []any{"extends"}),
+						}),
+						newObject("anyOf", []any{
+							newObject("required", []any{"type"}),
+							newObject("required", []any{"extends"}),
+						}),
+						newObject("not", newObject("required", []any{"defaults"})),
+					}),
+				),
+			},
+		)
+	}
+	panic(fmt.Sprintf("unknown schema definition %q", name))
+}
+
+// installerDefinition builds the installer definition, including a rule per installer type that
+// selects the schema of its opts.
+func (g *generator) installerDefinition() *object {
+	t := reflect.TypeFor[appconfig.InstallerData]()
+	def := g.typeSchema(t, false, true)
+
+	// Group installer types that share an opts struct into a single rule.
+	var optsTypes []reflect.Type
+	typesByOpts := map[reflect.Type][]any{}
+	for _, name := range g.docs.enums[typeKey(reflect.TypeFor[appconfig.InstallerType]())] {
+		optsType, ok := installer.GetOptsType(appconfig.InstallerType(name))
+		if !ok {
+			continue
+		}
+		if _, seen := typesByOpts[optsType]; !seen {
+			optsTypes = append(optsTypes, optsType)
+		}
+		typesByOpts[optsType] = append(typesByOpts[optsType], name)
+	}
+
+	rules := []any{}
+	for _, optsType := range optsTypes {
+		types := typesByOpts[optsType]
+		typeCond := newObject("enum", types)
+		if len(types) == 1 {
+			typeCond = newObject("const", types[0])
+		}
+		rule := newObject("if", newObject(
+			"properties", newObject("type", typeCond),
+			"required", []any{"type"},
+		))
+		if optsType.NumField() == 0 {
+			rule.set("then", newObject(
+				"not", newObject("required", []any{"opts"}),
+				"description", fmt.Sprintf("The %s type does not accept 'opts'.", quoteList(types)),
+			))
+		} else {
+			rule.set("then", newObject("properties", newObject("opts", g.typeSchema(optsType, true, false))))
+		}
+		rules = append(rules, rule)
+	}
+	def.set("allOf", rules)
+	return def
+}
+
+// typeSchema returns the schema for a Go type. inOpts indicates the value is read from an
+// installer's opts map, where platform maps also accept a single value for all platforms.
+// When define is false, types with a shared definition are referenced instead of inlined.
+func (g *generator) typeSchema(t reflect.Type, inOpts bool, define bool) *object {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !define {
+		if name, ok := definitionName(t); ok {
+			return g.ref(name)
+		}
+	}
+	s := newObject()
+	if t.PkgPath() != "" {
+		if desc := g.docs.typeDoc(t); desc != "" && t.Kind() != reflect.Struct {
+			s.set("description", desc)
+		}
+	}
+	if strings.HasPrefix(t.Name(), "PlatformMap[") {
+		value := t.Field(0).Type
+		props := newObject()
+		for _, p := range platform.PlatformMapKeys() {
+			props.set(p, g.typeSchema(value, inOpts, false))
+		}
+		obj := newObject("type", "object", "additionalProperties", false, "properties", props)
+		if !inOpts {
+			return obj
+		}
+		return newObject("oneOf", []any{g.typeSchema(value, inOpts, false), obj})
+	}
+	if values := g.docs.enums[typeKey(t)]; t.Kind() == reflect.String && t.PkgPath() != "" && len(values) > 0 {
+		return s.set("type", "string").set("enum", toAnySlice(values))
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return s.set("type", "boolean")
+	case reflect.String:
+		return s.set("type", "string")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return s.set("type", "integer")
+	case reflect.Float32, reflect.Float64:
+		return s.set("type", "number")
+	case reflect.Slice, reflect.Array:
+		return s.set("type", "array").set("items", g.typeSchema(t.Elem(), inOpts, false))
+	case reflect.Map:
+		s.set("type", "object")
+		if t.Key().PkgPath() != "" && t.Key().Kind() == reflect.String {
+			s.set("propertyNames", g.typeSchema(t.Key(), inOpts, false))
+		}
+		if t.Elem().Kind() == reflect.Interface {
+			return s
+		}
+		return s.set("additionalProperties", g.typeSchema(t.Elem(), inOpts, false))
+	case reflect.Struct:
+		s.set("type", "object").set("additionalProperties", false)
+		if desc := g.docs.typeDoc(t); desc != "" && !inOpts {
+			s.set("description", desc)
+		}
+		if required, ok := structRequired[typeKey(t)]; ok {
+			s.set("required", toAnySlice(required))
+		}
+		props := newObject()
+		g.structProperties(t, props, inOpts)
+		return s.set("properties", props)
+	}
+	return s
+}
+
+// structProperties adds a property for each yaml-tagged field of t to props, including the
+// fields of inlined structs.
+func (g *generator) structProperties(t reflect.Type, props *object, inOpts bool) {
+	for idx := range t.NumField() {
+		field := t.Field(idx)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if opts == "inline" {
+			g.structProperties(field.Type, props, inOpts)
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		key := typeKey(t) + "." + field.Name
+		var s *object
+		if ref, ok := fieldRefs[key]; ok {
+			s = g.ref(ref)
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Slice {
+				s = newObject("type", "array", "items", s)
+			}
+		} else {
+			s = g.typeSchema(field.Type, inOpts, false)
+		}
+		// Field docs are more specific than the docs of the field's type.
+		if desc := g.docs.fieldDoc(t, field); desc != "" {
+			s.set("description", desc)
+		}
+		if def, ok := fieldDefaults[key]; ok {
+			s.set("default", def)
+		}
+		props.set(name, s)
+	}
+}
+
+// quoteList formats values as a human-readable list of quoted names.
+func quoteList(values []any) string {
+	quoted := make([]string, len(values))
+	for idx, v := range values {
+		quoted[idx] = fmt.Sprintf("'%v'", v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func toAnySlice[T any](values []T) []any {
+	out := make([]any, len(values))
+	for idx, v := range values {
+		out[idx] = v
+	}
+	return out
+}
+
+// object is a JSON object that keeps its keys in insertion order, so the generated schema is
+// stable and reads in the same order as the Go types.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+// newObject creates an object from alternating keys and values.
+func newObject(kv ...any) *object {
+	o := &object{values: map[string]any{}}
+	for idx := 0; idx+1 < len(kv); idx += 2 {
+		o.set(kv[idx].(string), kv[idx+1])
+	}
+	return o
+}
+
+// set sets a key, keeping its original position if it already exists.
+func (o *object) set(key string, value any) *object {
+	if !o.has(key) {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+	return o
+}
+
+func (o *object) has(key string) bool {
+	return slices.Contains(o.keys, key)
+}
+
+// MarshalJSON implements json.Marshaler, writing the keys in insertion order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, key := range o.keys {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		keyData, err := marshalNoEscape(key)
+		if err != nil {
+			return nil, err
+		}
+		valueData, err := marshalNoEscape(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyData)
+		buf.WriteByte(':')
+		buf.Write(valueData)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalNoEscape marshals v to JSON without escaping HTML characters.
+func marshalNoEscape(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
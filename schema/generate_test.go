@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchemaIsUpToDate ensures the committed schema matches the Go config types. If this fails,
+// run `go generate ./schema` and commit the result.
+func TestSchemaIsUpToDate(t *testing.T) {
+	generated, err := Generate("..")
+	require.NoError(t, err)
+	committed, err := os.ReadFile("sofmani.schema.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(generated), "schema is out of date, run `go generate ./schema`")
+}
+
+func TestGenerateIncludesInstallerOpts(t *testing.T) {
+	generated, err := Generate("..")
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(generated, &m))
+	defs := m["definitions"].(map[string]any)
+	installerDef := defs["installer"].(map[string]any)
+	rules := installerDef["allOf"].([]any)
+
+	optsFor := func(installerType string) map[string]any {
+		for _, r := range rules {
+			rule := r.(map[string]any)
+			cond := rule["if"].(map[string]any)["properties"].(map[string]any)["type"].(map[string]any)
+			matches := cond["const"] == installerType
+			if enum, ok := cond["enum"].([]any); ok {
+				for _, v := range enum {
+					matches = matches || v == installerType
+				}
+			}
+			if !matches {
+				continue
+			}
+			then := rule["then"].(map[string]any)
+			props, ok := then["properties"].(map[string]any)
+			if !ok {
+				return nil
+			}
+			return props["opts"].(map[string]any)["properties"].(map[string]any)
+		}
+		return nil
+	}
+
+	ghr := optsFor("github-release")
+	require.NotNil(t, ghr)
+	assert.Contains(t, ghr, "strip_components")
+	assert.Contains(t, ghr, "bin_links")
+	assert.Equal(t, "integer", ghr["strip_components"].(map[string]any)["type"])
+
+	assert.Equal(t, optsFor("npm"), optsFor("yarn"))
+	assert.Contains(t, optsFor("brew"), "cask")
+	assert.Nil(t, optsFor("group"))
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		doc      string
+		name     string
+		expected string
+	}{
+		{"Debug enables or disables debug mode.\n", "Debug", "Enables or disables debug mode."},
+		{"Tap is the Homebrew tap to use for the package.\n", "Tap", "The Homebrew tap to use for the package."},
+		{"Only specifies a list\nof platforms.\n", "Only", "Specifies a list of platforms."},
+		{"used for something.\n", "Other", "Used for something."},
+		{"", "Empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, describe(tt.doc, tt.name))
+		})
+	}
+}
@@ -49,9 +49,11 @@ func TestSchemaTopLevelProperties(t *testing.T) {
 		"debug",
 		"check_updates",
 		"summary",
+		"strict",
 		"category_display",
 		"repo_update",
 		"defaults",
+		"templates",
 		"env",
 		"platform_env",
 		"machine_aliases",
@@ -103,6 +105,17 @@ func TestInstallerTypesMatchGoConstants(t *testing.T) {
 		string(appconfig.InstallerTypeYay),
 		string(appconfig.InstallerTypeCargo),
 		string(appconfig.InstallerTypeGo),
+		string(appconfig.InstallerTypeDnf),
+		string(appconfig.InstallerTypeYum),
+		string(appconfig.InstallerTypeZypper),
+		string(appconfig.InstallerTypeFlatpak),
+		string(appconfig.InstallerTypeSnap),
+		string(appconfig.InstallerTypeUv),
+		string(appconfig.InstallerTypeGem),
+		string(appconfig.InstallerTypeMise),
+		string(appconfig.InstallerTypeNix),
+		string(appconfig.InstallerTypeURL),
+		string(appconfig.InstallerTypeLink),
 	}
 	sort.Strings(goTypes)
 
@@ -177,7 +190,7 @@ func TestRecipesParseAgainstSchemaShape(t *testing.T) {
 			data, err := os.ReadFile(path)
 			require.NoError(t, err)
 
-			cfg, err := appconfig.ParseConfigFromContent(data)
+			cfg, err := appconfig.ParseConfigFromContent(data, appconfig.ConfigFormatYAML)
 			require.NoError(t, err, "recipe must parse as AppConfig")
 
 			// Basic sanity: every installer in the recipe has a recognized
@@ -29,7 +29,8 @@ type InstallResult struct {
 	Type string
 	// Action is the action that was taken.
 	Action Action
-	// Children contains results from nested installers (for group/manifest).
+	// Children contains results from nested installers (for group/manifest), or of each package
+	// (for installers with names).
 	Children []InstallResult
 	// SkipSummaryInstall indicates whether to exclude this from install summary.
 	SkipSummaryInstall bool
@@ -128,6 +129,9 @@ func collectResultsByAction(r InstallResult, action Action) []InstallResult {
 			}
 			results = append(results, filtered)
 		}
+	} else if len(r.Children) > 0 {
+		// Batched installers report each of their packages
+		results = append(results, filterChildrenByAction(r.Children, action)...)
 	} else {
 		// For leaf installers, include if action matches directly
 		if r.Action == action {
@@ -161,6 +165,9 @@ func filterChildrenByAction(children []InstallResult, action Action) []InstallRe
 					Children: filterChildrenByAction(child.Children, action),
 				})
 			}
+		} else if len(child.Children) > 0 {
+			// Batched installers report each of their packages
+			filtered = append(filtered, filterChildrenByAction(child.Children, action)...)
 		} else {
 			// For leaf installers, include if action matches
 			if child.Action == action {
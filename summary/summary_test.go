@@ -166,6 +166,29 @@ func TestCollectResultsByAction(t *testing.T) {
 		assert.Equal(t, "child1", results[0].Children[0].Name)
 	})
 
+	t.Run("Batched installer reports each package", func(t *testing.T) {
+		r := InstallResult{
+			Name:   "git curl jq",
+			Type:   "apt",
+			Action: ActionInstalled,
+			Children: []InstallResult{
+				{Name: "git", Type: "apt", Action: ActionUpToDate},
+				{Name: "curl", Type: "apt", Action: ActionInstalled},
+				{Name: "jq", Type: "apt", Action: ActionUpgraded},
+			},
+		}
+
+		installed := collectResultsByAction(r, ActionInstalled)
+		assert.Equal(t, []InstallResult{{Name: "curl", Type: "apt", Action: ActionInstalled}}, installed)
+		upgraded := collectResultsByAction(r, ActionUpgraded)
+		assert.Equal(t, []InstallResult{{Name: "jq", Type: "apt", Action: ActionUpgraded}}, upgraded)
+
+		group := InstallResult{Name: "group", Type: "group", Action: ActionInstalled, Children: []InstallResult{r}}
+		results := collectResultsByAction(group, ActionInstalled)
+		assert.Len(t, results, 1)
+		assert.Equal(t, []InstallResult{{Name: "curl", Type: "apt", Action: ActionInstalled}}, results[0].Children)
+	})
+
 	t.Run("Neither parent nor children match", func(t *testing.T) {
 		r := InstallResult{
 			Name:   "group",
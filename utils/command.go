@@ -14,12 +14,18 @@ import (
 // UNIX_DEFAULT_SHELL is the default shell used on Unix-like systems if SHELL environment variable is not set.
 const UNIX_DEFAULT_SHELL string = "bash"
 
+// commandEnv returns the environment of a command: the process environment, with paths in its
+// values resolved, followed by env. The values of env are already resolved by EnvVars.Resolve and
+// are passed as is, so that literal "$" characters in them are kept.
+func commandEnv(env []string) []string {
+	return append(ResolveEnvPaths(os.Environ()), env...)
+}
+
 // RunCmdPassThrough executes a command and passes through its standard input, output, and error streams.
-// It also resolves environment variable paths.
 func RunCmdPassThrough(env []string, bin string, args ...string) error {
 	logger.Debug("Running command: %s %v", bin, args)
 	cmd := exec.Command(bin, args...)
-	cmd.Env = ResolveEnvPaths(os.Environ(), cmd.Env, env)
+	cmd.Env = commandEnv(env)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -43,7 +49,7 @@ func RunCmdPassThroughChained(env []string, commands [][]string) error {
 func RunCmdGetSuccess(env []string, bin string, args ...string) (bool, error) {
 	logger.Debug("Running command: %s %v", bin, args)
 	cmd := exec.Command(bin, args...)
-	cmd.Env = ResolveEnvPaths(os.Environ(), cmd.Env, env)
+	cmd.Env = commandEnv(env)
 	err := cmd.Run()
 	if err != nil {
 		return false, nil // Error means command failed, not an error in execution of this function
@@ -55,7 +61,7 @@ func RunCmdGetSuccess(env []string, bin string, args ...string) (bool, error) {
 func RunCmdGetSuccessPassThrough(env []string, bin string, args ...string) (bool, error) {
 	logger.Debug("Running command: %s %v", bin, args)
 	cmd := exec.Command(bin, args...)
-	cmd.Env = ResolveEnvPaths(os.Environ(), cmd.Env, env)
+	cmd.Env = commandEnv(env)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -70,7 +76,7 @@ func RunCmdGetSuccessPassThrough(env []string, bin string, args ...string) (bool
 func RunCmdGetOutput(env []string, bin string, args ...string) ([]byte, error) {
 	logger.Debug("Running command: %s %v", bin, args)
 	cmd := exec.Command(bin, args...)
-	cmd.Env = ResolveEnvPaths(os.Environ(), cmd.Env, env)
+	cmd.Env = commandEnv(env)
 	out, err := cmd.Output()
 	return out, err
 }
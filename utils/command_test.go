@@ -198,3 +198,13 @@ func TestRunCmdAsFile(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestRunCmdGetOutputKeepsLiteralDollar(t *testing.T) {
+	if platform.GetPlatform() == platform.PlatformWindows {
+		t.Skip("uses sh to print the variable")
+	}
+	// Values in env are already resolved, and must not be expanded again.
+	output, err := RunCmdGetOutput([]string{"PASS=p$HOME"}, "sh", "-c", `printf '%s' "$PASS"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "p$HOME", string(output))
+}
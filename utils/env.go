@@ -3,6 +3,9 @@ package utils
 import (
 	"fmt"
 	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/samber/lo"
@@ -14,11 +17,11 @@ func ResolveEnvPaths(envs ...[]string) []string {
 	out := []string{}
 	for _, e := range envs {
 		for _, env := range e {
-			vals := strings.Split(env, "=")
-			if len(vals) != 2 {
+			k, v, ok := strings.Cut(env, "=")
+			if !ok {
 				continue
 			}
-			out = append(out, fmt.Sprintf("%s=%s", vals[0], GetRealPath(e, vals[1])))
+			out = append(out, fmt.Sprintf("%s=%s", k, GetRealPath(e, v)))
 		}
 	}
 	return out
@@ -51,12 +54,10 @@ func CombineEnvMaps(envs ...*map[string]string) map[string]string {
 func EnvSliceAsMap(env []string) map[string]string {
 	out := map[string]string{}
 	for _, line := range env {
-		vals := strings.Split(line, "=")
-		if len(vals) != 2 {
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
 			continue
 		}
-		k := vals[0]
-		v := vals[1]
 		out[k] = v
 	}
 	return out
@@ -69,6 +70,55 @@ func EnvMapAsSlice(env map[string]string) []string {
 	})
 }
 
+// ExpandEnvRefs replaces $VAR, ${VAR}, ${VAR:-default} and ${VAR-default} references in value.
+// Variables are looked up with lookup. With ":-", the default is used when the variable is unset
+// or empty; with "-", only when it is unset. Unset variables without a default expand to "".
+func ExpandEnvRefs(value string, lookup func(name string) (string, bool)) string {
+	return os.Expand(value, func(ref string) string {
+		if name, def, ok := strings.Cut(ref, ":-"); ok {
+			if v, found := lookup(name); found && v != "" {
+				return v
+			}
+			return def
+		}
+		if name, def, ok := strings.Cut(ref, "-"); ok {
+			if v, found := lookup(name); found {
+				return v
+			}
+			return def
+		}
+		v, _ := lookup(ref)
+		return v
+	})
+}
+
+// AdjustPathList returns the given PATH-style list with prepend added in front and appendDirs added
+// at the end. Prepended directories are moved to the front if already present, while appended ones
+// are skipped if already present. "~" and environment variables in the directories are expanded.
+func AdjustPathList(pathList string, prepend []string, appendDirs []string) string {
+	expand := func(dirs []string) []string {
+		out := []string{}
+		for _, dir := range dirs {
+			if dir = GetRealPath(nil, dir); dir != "" && !slices.Contains(out, dir) {
+				out = append(out, dir)
+			}
+		}
+		return out
+	}
+	dirs := expand(prepend)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range expand(appendDirs) {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, string(filepath.ListSeparator))
+}
+
 // mergeEnvs helper function to merge a source slice of env strings into a target map (represented as a slice).
 // This is an internal helper for CombineEnv.
 func mergeEnvs(source *[]string, target []string) []string {
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolveEnvPaths(t *testing.T) {
@@ -58,3 +62,64 @@ func TestMergeEnvs(t *testing.T) {
 	result := mergeEnvs(source, target)
 	assert.ElementsMatch(t, expected, result)
 }
+
+func TestEnvValuesContainingEquals(t *testing.T) {
+	env := []string{"OPTS=--foo=bar --baz=1", "EMPTY="}
+	assert.Equal(t, map[string]string{"OPTS": "--foo=bar --baz=1", "EMPTY": ""}, EnvSliceAsMap(env))
+	assert.Equal(t, []string{"OPTS=--foo=bar --baz=1", "EMPTY="}, ResolveEnvPaths(env))
+}
+
+func TestExpandEnvRefs(t *testing.T) {
+	vars := map[string]string{"HOME": "/home/user", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"plain", "no refs", "no refs"},
+		{"bare reference", "$HOME/bin", "/home/user/bin"},
+		{"braced reference", "${HOME}/bin", "/home/user/bin"},
+		{"unset reference", "${MISSING}/bin", "/bin"},
+		{"default when unset", "${MISSING:-/opt}/bin", "/opt/bin"},
+		{"default when empty", "${EMPTY:-fallback}", "fallback"},
+		{"set value wins over default", "${HOME:-/opt}", "/home/user"},
+		{"dash default keeps empty", "${EMPTY-fallback}", ""},
+		{"dash default when unset", "${MISSING-fallback}", "fallback"},
+		{"default containing dashes", "${MISSING:-a-b}", "a-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExpandEnvRefs(tt.value, lookup))
+		})
+	}
+}
+
+func TestAdjustPathList(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	sep := string(filepath.ListSeparator)
+	join := func(dirs ...string) string { return strings.Join(dirs, sep) }
+
+	t.Run("prepends and appends", func(t *testing.T) {
+		result := AdjustPathList(join("/usr/bin", "/bin"), []string{"~/.local/bin"}, []string{"/opt/bin"})
+		assert.Equal(t, join(filepath.Join(home, ".local/bin"), "/usr/bin", "/bin", "/opt/bin"), result)
+	})
+
+	t.Run("moves prepended directories to the front", func(t *testing.T) {
+		result := AdjustPathList(join("/usr/bin", "/opt/bin"), []string{"/opt/bin"}, nil)
+		assert.Equal(t, join("/opt/bin", "/usr/bin"), result)
+	})
+
+	t.Run("skips appended directories already present", func(t *testing.T) {
+		result := AdjustPathList(join("/usr/bin", "/bin"), nil, []string{"/usr/bin", "/opt/bin", "/opt/bin"})
+		assert.Equal(t, join("/usr/bin", "/bin", "/opt/bin"), result)
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		assert.Equal(t, "/opt/bin", AdjustPathList("", []string{"/opt/bin"}, nil))
+	})
+}
@@ -46,24 +46,31 @@ func GetRealPath(env []string, path string) string {
 		}
 	}
 
-	// Expand ~ to home directory
-	if strings.HasPrefix(path, fmt.Sprintf("~%s", string(filepath.Separator))) || path == "~" {
-		homedir, err := os.UserHomeDir()
-		if err == nil { // Only replace if UserHomeDir succeeds
-			if path == "~" {
-				path = homedir
-			} else {
-				isDir := strings.HasSuffix(path, string(filepath.Separator))
-				path = filepath.Join(homedir, path[2:])
-				if isDir && !strings.HasSuffix(path, string(filepath.Separator)) { // Ensure trailing slash is preserved if originally present
-					path += string(filepath.Separator)
-				}
-			}
-		}
-	}
+	path = ExpandHome(path)
 	return strings.TrimSpace(path)
 }
 
+// ExpandHome replaces a leading "~" in path with the user's home directory. Other paths are
+// returned as is.
+func ExpandHome(path string) string {
+	if !strings.HasPrefix(path, fmt.Sprintf("~%s", string(filepath.Separator))) && path != "~" {
+		return path
+	}
+	homedir, err := os.UserHomeDir()
+	if err != nil { // Only replace if UserHomeDir succeeds
+		return path
+	}
+	if path == "~" {
+		return homedir
+	}
+	isDir := strings.HasSuffix(path, string(filepath.Separator))
+	path = filepath.Join(homedir, path[2:])
+	if isDir && !strings.HasSuffix(path, string(filepath.Separator)) { // Ensure trailing slash is preserved if originally present
+		path += string(filepath.Separator)
+	}
+	return path
+}
+
 // PathExists checks if a file or directory exists at the given path.
 // It returns true if the path exists, false otherwise.
 // It does not distinguish between files and directories.
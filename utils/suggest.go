@@ -0,0 +1,43 @@
+package utils
+
+// ClosestMatch returns the candidate closest to input by edit distance, for "did you mean"
+// style suggestions. It only returns a match when the distance is small relative to the
+// input length, so unrelated words are not suggested.
+func ClosestMatch(input string, candidates []string) (string, bool) {
+	best := ""
+	bestDist := -1
+	for _, c := range candidates {
+		d := levenshtein(input, c)
+		if bestDist == -1 || d < bestDist {
+			best = c
+			bestDist = d
+		}
+	}
+	maxDist := max(len(input)/3, 2)
+	if bestDist == -1 || bestDist > maxDist {
+		return "", false
+	}
+	return best, true
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"post_install", "pre_install", "download_filename", "destination"}
+	tests := []struct {
+		input    string
+		expected string
+		found    bool
+	}{
+		{"post_instal", "post_install", true},
+		{"download_file_name", "download_filename", true},
+		{"destinaton", "destination", true},
+		{"pre_instll", "pre_install", true},
+		{"foo", "", false},
+		{"completely_unrelated", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ClosestMatch(tt.input, candidates)
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	t.Run("no candidates", func(t *testing.T) {
+		_, ok := ClosestMatch("anything", nil)
+		assert.False(t, ok)
+	})
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("abc", "abc"))
+	assert.Equal(t, 1, levenshtein("abc", "abd"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}